@@ -0,0 +1,223 @@
+package main
+
+import (
+	"io"
+	"log"
+	"net"
+	"os"
+	"project_lab/internal/middleware"
+	"project_lab/internal/ratelimit"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+	"project_lab/internal/storage"
+	"strconv"
+	"time"
+)
+
+// loadTokenService monta o TokenService a partir do ambiente:
+//
+//	JWT_KEYS        lista "kid:ALG:valor" separada por vírgulas (HS256, RS256, EdDSA)
+//	JWT_ACTIVE_KID  kid usado para assinar novos tokens (opcional com uma única chave)
+//	JWT_SECRET      atalho para uma única chave HS256 quando JWT_KEYS não é definido
+//	JWT_ACCESS_TTL  validade do token de acesso (padrão 15m)
+//	JWT_REFRESH_TTL validade do refresh token (padrão 720h)
+func loadTokenService(refreshRepo repositories.RefreshTokenRepository, usedTokenRepo repositories.UsedTokenRepository) services.TokenService {
+	spec := os.Getenv("JWT_KEYS")
+	if spec == "" && os.Getenv("JWT_SECRET") != "" {
+		spec = "default:HS256:" + os.Getenv("JWT_SECRET")
+	}
+	if spec == "" {
+		log.Fatal("Configure JWT_KEYS ou JWT_SECRET no arquivo .env.")
+	}
+
+	keys, err := services.ParseKeySet(spec, os.Getenv("JWT_ACTIVE_KID"))
+	if err != nil {
+		log.Fatalf("Erro ao carregar chaves JWT: %v", err)
+	}
+
+	return services.NewTokenService(keys, refreshRepo, usedTokenRepo, durationEnv("JWT_ACCESS_TTL"), durationEnv("JWT_REFRESH_TTL"))
+}
+
+// durationEnv lê uma duração no formato do Go ("15m", "720h"); vazio vira zero.
+func durationEnv(name string) time.Duration {
+	value := os.Getenv(name)
+	if value == "" {
+		return 0
+	}
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		log.Fatalf("Valor inválido para %s: %q", name, value)
+	}
+	return d
+}
+
+// loadMailer escolhe o envio de e-mails:
+//
+//	SMTP_HOST       servidor SMTP; sem ele, os e-mails só são registrados localmente
+//	SMTP_PORT       porta do servidor (padrão 587)
+//	SMTP_USERNAME   usuário para autenticação (opcional)
+//	SMTP_PASSWORD   senha para autenticação
+//	MAIL_FROM       remetente (padrão "EasyTrip <no-reply@localhost>")
+//	MAIL_DIR        sem SMTP, grava cada e-mail como .eml neste diretório em vez de imprimir
+func loadMailer() services.Mailer {
+	from := os.Getenv("MAIL_FROM")
+	if from == "" {
+		from = "EasyTrip <no-reply@localhost>"
+	}
+
+	host := os.Getenv("SMTP_HOST")
+	if host == "" {
+		log.Printf("SMTP_HOST não definido. E-mails serão registrados localmente.")
+		return services.NewLogMailer(os.Getenv("MAIL_DIR"), from)
+	}
+
+	port := os.Getenv("SMTP_PORT")
+	if port == "" {
+		port = "587"
+	}
+	return services.NewSMTPMailer(host, port, os.Getenv("SMTP_USERNAME"), os.Getenv("SMTP_PASSWORD"), from)
+}
+
+// loadPasswordPolicy monta a política de senhas:
+//
+//	PASSWORD_MIN_LENGTH     tamanho mínimo (padrão 8)
+//	PASSWORD_BLOCKLIST_FILE arquivo com senhas vazadas, uma por linha, somado à lista embutida
+func loadPasswordPolicy() *services.PasswordPolicy {
+	minLength := 0
+	if value := os.Getenv("PASSWORD_MIN_LENGTH"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Fatalf("Valor inválido para PASSWORD_MIN_LENGTH: %q", value)
+		}
+		minLength = n
+	}
+
+	var lists []io.Reader
+	if path := os.Getenv("PASSWORD_BLOCKLIST_FILE"); path != "" {
+		file, err := os.Open(path)
+		if err != nil {
+			log.Fatalf("Erro ao abrir PASSWORD_BLOCKLIST_FILE: %v", err)
+		}
+		defer file.Close()
+		lists = append(lists, file)
+	}
+
+	policy, err := services.NewPasswordPolicy(minLength, lists...)
+	if err != nil {
+		log.Fatalf("Erro ao carregar política de senhas: %v", err)
+	}
+	return policy
+}
+
+// loadBlobStore escolhe onde ficam os comprovantes das despesas:
+//
+//	STORAGE_BACKEND        local (padrão) ou s3, para qualquer serviço compatível (AWS S3, MinIO...)
+//	STORAGE_DIR            diretório do backend local (padrão uploads)
+//	S3_ENDPOINT            URL do serviço (ex.: https://s3.sa-east-1.amazonaws.com, http://localhost:9000)
+//	S3_REGION              região (padrão us-east-1)
+//	S3_BUCKET              bucket, que já deve existir
+//	S3_ACCESS_KEY          chave de acesso
+//	S3_SECRET_KEY          chave secreta
+//	S3_PATH_STYLE          true para endereçar o bucket no caminho (MinIO e afins)
+//	ATTACHMENT_MAX_SIZE_MB tamanho máximo de cada arquivo (padrão 10)
+func loadBlobStore() (storage.BlobStore, int64) {
+	var blobs storage.BlobStore
+	var err error
+	switch backend := os.Getenv("STORAGE_BACKEND"); backend {
+	case "", "local":
+		dir := os.Getenv("STORAGE_DIR")
+		if dir == "" {
+			dir = "uploads"
+		}
+		blobs, err = storage.NewLocalStore(dir)
+	case "s3":
+		blobs, err = storage.NewS3Store(storage.S3Config{
+			Endpoint:  os.Getenv("S3_ENDPOINT"),
+			Region:    os.Getenv("S3_REGION"),
+			Bucket:    os.Getenv("S3_BUCKET"),
+			AccessKey: os.Getenv("S3_ACCESS_KEY"),
+			SecretKey: os.Getenv("S3_SECRET_KEY"),
+			PathStyle: boolEnv("S3_PATH_STYLE"),
+		})
+	default:
+		log.Fatalf("Valor inválido para STORAGE_BACKEND: %q (use local ou s3)", backend)
+	}
+	if err != nil {
+		log.Fatalf("Erro ao configurar o armazenamento de arquivos: %v", err)
+	}
+
+	var maxSize int64
+	if value := os.Getenv("ATTACHMENT_MAX_SIZE_MB"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n <= 0 {
+			log.Fatalf("Valor inválido para ATTACHMENT_MAX_SIZE_MB: %q", value)
+		}
+		maxSize = int64(n) << 20
+	}
+	return blobs, maxSize
+}
+
+// boolEnv lê uma flag "true"/"false" (também aceita 1/0); vazio vira false.
+func boolEnv(name string) bool {
+	value := os.Getenv(name)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		log.Fatalf("Valor inválido para %s: %q", name, value)
+	}
+	return b
+}
+
+// Políticas de limite por rota, ajustáveis com RATE_LIMITS.
+var defaultRateLimits = map[string]ratelimit.Policy{
+	"login":           {Name: "login", Limit: 10, Window: time.Minute},
+	"refresh":         {Name: "refresh", Limit: 30, Window: time.Minute},
+	"forgot-password": {Name: "forgot-password", Limit: 5, Window: 15 * time.Minute},
+	"verify-resend":   {Name: "verify-resend", Limit: 3, Window: 15 * time.Minute},
+}
+
+// loadRateLimiting monta o limitador de requisições:
+//
+//	RATE_LIMIT_BACKEND memory (padrão) ou redis, para compartilhar limites entre instâncias
+//	REDIS_ADDR         endereço host:porta do servidor (padrão localhost:6379)
+//	REDIS_PASSWORD     senha do servidor (opcional)
+//	REDIS_DB           número do banco (padrão 0)
+//	RATE_LIMITS        políticas "rota=limite/janela" separadas por vírgula (ex.: login=5/1m)
+//	TRUSTED_PROXIES    CIDRs dos proxies cujo X-Forwarded-For é aceito (ex.: 10.0.0.0/8)
+func loadRateLimiting() (ratelimit.Limiter, map[string]ratelimit.Policy, []*net.IPNet) {
+	var limiter ratelimit.Limiter
+	switch backend := os.Getenv("RATE_LIMIT_BACKEND"); backend {
+	case "", "memory":
+		limiter = ratelimit.NewMemoryLimiter()
+	case "redis":
+		addr := os.Getenv("REDIS_ADDR")
+		if addr == "" {
+			addr = "localhost:6379"
+		}
+		db := 0
+		if value := os.Getenv("REDIS_DB"); value != "" {
+			n, err := strconv.Atoi(value)
+			if err != nil || n < 0 {
+				log.Fatalf("Valor inválido para REDIS_DB: %q", value)
+			}
+			db = n
+		}
+		limiter = ratelimit.NewRedisLimiter(addr, os.Getenv("REDIS_PASSWORD"), db)
+	default:
+		log.Fatalf("Valor inválido para RATE_LIMIT_BACKEND: %q (use memory ou redis)", backend)
+	}
+
+	policies, err := ratelimit.ParsePolicies(os.Getenv("RATE_LIMITS"), defaultRateLimits)
+	if err != nil {
+		log.Fatalf("Erro em RATE_LIMITS: %v", err)
+	}
+
+	trusted, err := middleware.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		log.Fatalf("Erro em TRUSTED_PROXIES: %v", err)
+	}
+
+	return limiter, policies, trusted
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"log"
 	"os"
+	"project_lab/internal/repositories"
 
 	_ "github.com/lib/pq"
 )
@@ -31,6 +32,14 @@ func connectDB() *sql.DB {
 		log.Fatalf("Banco não respondeu: %v", err)
 	}
 
+	// DB_QUERY_TIMEOUT limita cada operação dos repositórios (padrão 10s);
+	// "0" desativa o limite, e só o cancelamento da requisição interrompe a consulta.
+	if os.Getenv("DB_QUERY_TIMEOUT") == "0" {
+		repositories.SetQueryTimeout(0)
+	} else if d := durationEnv("DB_QUERY_TIMEOUT"); d > 0 {
+		repositories.SetQueryTimeout(d)
+	}
+
 	fmt.Println("Conexão bem sucedida com o Postgres!")
 	return db
 }
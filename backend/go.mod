@@ -9,7 +9,4 @@ require (
 	golang.org/x/crypto v0.41.0
 )
 
-require (
-	github.com/golang-jwt/jwt/v5 v5.3.0
-	golang.org/x/time v0.14.0
-)
+require github.com/golang-jwt/jwt/v5 v5.3.0
@@ -0,0 +1,237 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+	"strconv"
+)
+
+// multipartOverhead é a folga, além do tamanho máximo do arquivo, para os
+// cabeçalhos e delimitadores do corpo multipart.
+const multipartOverhead = 64 << 10
+
+type AttachmentHandler struct {
+	attachments services.AttachmentService
+	groupRepo   repositories.TravelGroupRepository
+}
+
+func NewAttachmentHandler(attachments services.AttachmentService, groupRepo repositories.TravelGroupRepository) *AttachmentHandler {
+	return &AttachmentHandler{attachments: attachments, groupRepo: groupRepo}
+}
+
+// ListAttachmentsHandler lida com GET /groups/{id}/expenses/{expenseId}/attachments.
+func (h *AttachmentHandler) ListAttachmentsHandler(w http.ResponseWriter, r *http.Request, groupID int, expenseID int) {
+	if _, _, _, ok := h.loadExpense(w, r, groupID, expenseID, services.PermView); !ok {
+		return
+	}
+
+	attachments, err := h.attachments.List(r.Context(), groupID, expenseID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar comprovantes da despesa %d: %v\n", expenseID, err)
+		writeServerError(w, r, err, "internal.list_attachments")
+		return
+	}
+	for i := range attachments {
+		setAttachmentURLs(groupID, &attachments[i])
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(attachments)
+}
+
+// UploadAttachmentHandler lida com POST /groups/{id}/expenses/{expenseId}/attachments:
+// recebe um arquivo no campo "file" de um corpo multipart/form-data. Quem pode
+// alterar a despesa pode anexar comprovantes a ela.
+func (h *AttachmentHandler) UploadAttachmentHandler(w http.ResponseWriter, r *http.Request, groupID int, expenseID int) {
+	group, userID, expense, ok := h.loadExpense(w, r, groupID, expenseID, services.PermContribute)
+	if !ok {
+		return
+	}
+	if !canManage(group, userID, expense.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "expense.author_only")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.attachments.MaxSize()+multipartOverhead)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "attachment.multipart_required")
+		return
+	}
+
+	// Procura o campo "file", ignorando os demais, sem gravar nada em disco:
+	// o arquivo vai direto para o serviço, que aplica o limite de tamanho.
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			problem.Error(w, r, http.StatusBadRequest, "attachment.file_required")
+			return
+		}
+		if err != nil {
+			h.writeUploadError(w, r, expenseID, err)
+			return
+		}
+		if part.FormName() != "file" || part.FileName() == "" {
+			part.Close()
+			continue
+		}
+
+		attachment := models.ExpenseAttachment{ExpenseID: expenseID, FileName: part.FileName(), UploadedBy: &userID}
+		err = h.attachments.Upload(r.Context(), groupID, &attachment, part)
+		part.Close()
+		if err != nil {
+			h.writeUploadError(w, r, expenseID, err)
+			return
+		}
+
+		setAttachmentURLs(groupID, &attachment)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(attachment)
+		return
+	}
+}
+
+func (h *AttachmentHandler) writeUploadError(w http.ResponseWriter, r *http.Request, expenseID int, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		problem.Write(w, problem.Localized(r, http.StatusRequestEntityTooLarge, problem.CodeAttachmentTooLarge,
+			"attachment.too_large", h.attachments.MaxSize()>>20))
+		return
+	}
+	if writeDomainError(w, r, err) {
+		return
+	}
+	fmt.Printf("Erro ao anexar comprovante à despesa %d: %v\n", expenseID, err)
+	writeServerError(w, r, err, "internal.upload_attachment")
+}
+
+// DownloadAttachmentHandler lida com GET /groups/{id}/expenses/{expenseId}/attachments/{attachmentId}.
+func (h *AttachmentHandler) DownloadAttachmentHandler(w http.ResponseWriter, r *http.Request, groupID int, expenseID int, attachmentID int) {
+	h.download(w, r, groupID, expenseID, attachmentID, false)
+}
+
+// DownloadThumbnailHandler lida com GET .../attachments/{attachmentId}/thumbnail
+// (apenas imagens JPEG, PNG e GIF têm miniatura).
+func (h *AttachmentHandler) DownloadThumbnailHandler(w http.ResponseWriter, r *http.Request, groupID int, expenseID int, attachmentID int) {
+	h.download(w, r, groupID, expenseID, attachmentID, true)
+}
+
+// download confere se o usuário é membro do grupo e envia o arquivo. O tipo
+// enviado é o detectado no upload, e os cabeçalhos impedem que o navegador
+// interprete o arquivo de outra forma.
+func (h *AttachmentHandler) download(w http.ResponseWriter, r *http.Request, groupID int, expenseID int, attachmentID int, thumbnail bool) {
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView); !ok {
+		return
+	}
+
+	attachment, ok := h.loadAttachment(w, r, groupID, expenseID, attachmentID)
+	if !ok {
+		return
+	}
+
+	file, err := h.attachments.Open(r.Context(), attachment, thumbnail)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao abrir comprovante %d: %v\n", attachmentID, err)
+		writeServerError(w, r, err, "internal.download_attachment")
+		return
+	}
+	defer file.Close()
+
+	contentType, size := attachment.ContentType, strconv.FormatInt(attachment.Size, 10)
+	if thumbnail {
+		contentType, size = "image/jpeg", ""
+	}
+	w.Header().Set("Content-Type", contentType)
+	if size != "" {
+		w.Header().Set("Content-Length", size)
+	}
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("inline", map[string]string{"filename": attachment.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Content-Security-Policy", "default-src 'none'; sandbox")
+	w.Header().Set("Cache-Control", "private, max-age=3600")
+	if _, err := io.Copy(w, file); err != nil {
+		fmt.Printf("Erro ao enviar comprovante %d: %v\n", attachmentID, err)
+	}
+}
+
+// DeleteAttachmentHandler lida com DELETE .../attachments/{attachmentId}: quem
+// enviou o comprovante, o organizador ou os co-organizadores.
+func (h *AttachmentHandler) DeleteAttachmentHandler(w http.ResponseWriter, r *http.Request, groupID int, expenseID int, attachmentID int) {
+	group, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermContribute)
+	if !ok {
+		return
+	}
+
+	attachment, ok := h.loadAttachment(w, r, groupID, expenseID, attachmentID)
+	if !ok {
+		return
+	}
+	if !canManage(group, userID, attachment.UploadedBy) {
+		problem.Error(w, r, http.StatusForbidden, "attachment.uploader_only")
+		return
+	}
+
+	if err := h.attachments.Delete(r.Context(), groupID, attachment); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao apagar comprovante %d: %v\n", attachmentID, err)
+		writeServerError(w, r, err, "internal.delete_attachment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadExpense confere a permissão no grupo e se a despesa é dele.
+func (h *AttachmentHandler) loadExpense(w http.ResponseWriter, r *http.Request, groupID int, expenseID int, permission services.Permission) (*models.TravelGroupDetails, int, *models.Expense, bool) {
+	group, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, permission)
+	if !ok {
+		return nil, userID, nil, false
+	}
+	expense, err := h.groupRepo.GetExpense(r.Context(), groupID, expenseID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return nil, userID, nil, false
+		}
+		fmt.Printf("Erro ao buscar despesa %d: %v\n", expenseID, err)
+		writeServerError(w, r, err, "internal.get_expense")
+		return nil, userID, nil, false
+	}
+	return group, userID, expense, true
+}
+
+func (h *AttachmentHandler) loadAttachment(w http.ResponseWriter, r *http.Request, groupID int, expenseID int, attachmentID int) (*models.ExpenseAttachment, bool) {
+	attachment, err := h.attachments.Get(r.Context(), groupID, expenseID, attachmentID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return nil, false
+		}
+		fmt.Printf("Erro ao buscar comprovante %d: %v\n", attachmentID, err)
+		writeServerError(w, r, err, "internal.get_attachment")
+		return nil, false
+	}
+	return attachment, true
+}
+
+// setAttachmentURLs preenche os links de download, que passam pela API (e pela
+// verificação de membro) em vez de expor o armazenamento.
+func setAttachmentURLs(groupID int, attachment *models.ExpenseAttachment) {
+	attachment.URL = fmt.Sprintf("/groups/%d/expenses/%d/attachments/%d", groupID, attachment.ExpenseID, attachment.ID)
+	if attachment.ThumbnailKey != nil {
+		thumbnail := attachment.URL + "/thumbnail"
+		attachment.ThumbnailURL = &thumbnail
+	}
+}
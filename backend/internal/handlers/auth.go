@@ -2,62 +2,244 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"project_lab/internal/i18n"
+	"project_lab/internal/middleware"
 	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
 	"project_lab/internal/services"
+	"strconv"
+	"time"
 )
 
 // AuthHandler gerencia as requisições HTTP para a autenticação.
 type AuthHandler struct {
-	authService services.AuthService
+	authService  services.AuthService
+	tokenService services.TokenService
 }
 
 // NewAuthHandler cria uma nova instância de AuthHandler.
-func NewAuthHandler(authService services.AuthService) *AuthHandler {
+func NewAuthHandler(authService services.AuthService, tokenService services.TokenService) *AuthHandler {
 	return &AuthHandler{
-		authService: authService,
+		authService:  authService,
+		tokenService: tokenService,
 	}
 }
 
 // RegisterUserHandler lida com a requisição de cadastro de usuário.
 func (h *AuthHandler) RegisterUserHandler(w http.ResponseWriter, r *http.Request) {
-	var user models.User
-	if err := json.NewDecoder(r.Body).Decode(&user); err != nil {
-		http.Error(w, "Dados de requisição inválidos", http.StatusBadRequest)
+	var req models.UserRegisterRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
-	if err := h.authService.RegisterUser(&user); err != nil {
-		if err.Error() == "e-mail já está em uso" {
-			http.Error(w, err.Error(), http.StatusConflict)
-			return
+	user := models.User{Name: req.Name, Email: req.Email, Password: req.Password, Locale: string(i18n.FromContext(r.Context()))}
+	if err := h.authService.RegisterUser(r.Context(), &user); err != nil {
+		var invalid *services.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			writeValidationError(w, r, http.StatusUnprocessableEntity, problem.CodeValidationFailed, invalid.Fields)
+		case errors.Is(err, repositories.ErrEmailAlreadyExists):
+			writeValidationError(w, r, http.StatusConflict, problem.CodeEmailTaken, []services.FieldError{{Field: "email", Message: i18n.M("error.email_taken")}})
+		default:
+			fmt.Printf("Erro ao registrar usuário: %v\n", err)
+			writeServerError(w, r, err, "internal.register")
 		}
-		http.Error(w, "Erro ao registrar usuário", http.StatusInternalServerError)
 		return
 	}
 
 	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte("Usuário registrado com sucesso!"))
+	w.Write([]byte(i18n.T(i18n.FromContext(r.Context()), "auth.registered")))
 }
 
 // LoginUserHandler lida com a requisição de login de usuário.
 func (h *AuthHandler) LoginUserHandler(w http.ResponseWriter, r *http.Request) {
 	var loginRequest models.UserLogin
 	if err := json.NewDecoder(r.Body).Decode(&loginRequest); err != nil {
-		http.Error(w, "Dados de requisição inválidos", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
-	token, err := h.authService.Authenticate(loginRequest.Email, loginRequest.Password)
+	tokens, err := h.authService.Authenticate(r.Context(), loginRequest.Email, loginRequest.Password, clientInfo(r))
 	if err != nil {
-		http.Error(w, "Credenciais inválidas", http.StatusUnauthorized)
+		var locked *services.AccountLockedError
+		switch {
+		case errors.As(err, &locked):
+			wait := max(time.Until(locked.Until), time.Second)
+			w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(wait.Seconds()))))
+			locale := i18n.FromContext(r.Context())
+			problem.Write(w, problem.Localized(r, http.StatusTooManyRequests, problem.CodeAccountLocked, "auth.account_locked", formatWait(locale, wait)))
+		default:
+			if !writeDomainError(w, r, err) {
+				fmt.Printf("Erro ao autenticar: %v\n", err)
+				writeServerError(w, r, err, "internal.login")
+			}
+		}
+		return
+	}
+
+	writeAuthTokens(w, r, tokens)
+}
+
+// clientInfo extrai IP e User-Agent da requisição para a auditoria de login.
+func clientInfo(r *http.Request) models.ClientInfo {
+	ip := middleware.ClientIP(r)
+	userAgent := r.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+	return models.ClientInfo{IP: ip, UserAgent: userAgent}
+}
+
+// formatWait descreve a espera em segundos ou minutos, arredondando para cima.
+func formatWait(locale i18n.Locale, d time.Duration) string {
+	if d < time.Minute {
+		return i18n.T(locale, "wait.seconds", int(math.Ceil(d.Seconds())))
+	}
+	minutes := int(math.Ceil(d.Minutes()))
+	if minutes == 1 {
+		return i18n.T(locale, "wait.minute")
+	}
+	return i18n.T(locale, "wait.minutes", minutes)
+}
+
+// RefreshTokenHandler troca um refresh token válido por um novo par de tokens.
+// POST /auth/refresh
+func (h *AuthHandler) RefreshTokenHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	tokens, err := h.tokenService.Refresh(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, repositories.ErrRefreshTokenReused) {
+			fmt.Printf("Reuso de refresh token detectado; sessão revogada.\n")
+		}
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao renovar token: %v\n", err)
+		writeServerError(w, r, err, "internal.refresh")
+		return
+	}
+
+	writeAuthTokens(w, r, tokens)
+}
+
+// LogoutHandler revoga a sessão (família de refresh tokens) do token informado.
+// POST /auth/logout
+func (h *AuthHandler) LogoutHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	// Logout é idempotente: um token desconhecido ou já revogado não é erro.
+	if err := h.tokenService.Revoke(r.Context(), req.RefreshToken); err != nil && !errors.Is(err, repositories.ErrRefreshTokenInvalid) {
+		fmt.Printf("Erro ao revogar sessão: %v\n", err)
+		writeServerError(w, r, err, "internal.logout")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// VerifyEmailHandler confirma o e-mail com o token recebido no link.
+// POST /auth/verify
+func (h *AuthHandler) VerifyEmailHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.EmailTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
-	response := map[string]string{"token": token}
-	jsonResponse, err := json.Marshal(response)
+	if err := h.authService.VerifyEmail(r.Context(), req.Token); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao verificar e-mail: %v\n", err)
+		writeServerError(w, r, err, "internal.verify_email")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ResendVerificationHandler envia um novo link de verificação ao usuário logado.
+// POST /auth/verify/resend
+func (h *AuthHandler) ResendVerificationHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return
+	}
+
+	if err := h.authService.SendVerificationEmail(r.Context(), userID); err != nil {
+		fmt.Printf("Erro ao reenviar verificação de e-mail: %v\n", err)
+		writeServerError(w, r, err, "internal.send_verification")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ForgotPasswordHandler envia o link de redefinição de senha. A resposta é a
+// mesma para e-mails cadastrados ou não.
+// POST /auth/forgot-password
+func (h *AuthHandler) ForgotPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ForgotPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Email == "" {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	if err := h.authService.RequestPasswordReset(r.Context(), req.Email); err != nil {
+		fmt.Printf("Erro ao solicitar redefinição de senha: %v\n", err)
+		writeServerError(w, r, err, "internal.request_password_reset")
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+}
+
+// ResetPasswordHandler define a nova senha com o token recebido no link.
+// POST /auth/reset-password
+func (h *AuthHandler) ResetPasswordHandler(w http.ResponseWriter, r *http.Request) {
+	var req models.ResetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Token == "" {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	if err := h.authService.ResetPassword(r.Context(), req.Token, req.Password); err != nil {
+		var invalid *services.ValidationError
+		switch {
+		case errors.As(err, &invalid):
+			writeValidationError(w, r, http.StatusUnprocessableEntity, problem.CodeValidationFailed, invalid.Fields)
+		default:
+			if !writeDomainError(w, r, err) {
+				fmt.Printf("Erro ao redefinir senha: %v\n", err)
+				writeServerError(w, r, err, "internal.reset_password")
+			}
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func writeAuthTokens(w http.ResponseWriter, r *http.Request, tokens *models.AuthTokens) {
+	jsonResponse, err := json.Marshal(tokens)
 	if err != nil {
-		http.Error(w, "Erro ao serializar resposta", http.StatusInternalServerError)
+		problem.Error(w, r, http.StatusInternalServerError, "internal.encode_response")
 		return
 	}
 
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+)
+
+type BudgetHandler struct {
+	budgets   services.BudgetService
+	groupRepo repositories.TravelGroupRepository
+}
+
+func NewBudgetHandler(budgets services.BudgetService, groupRepo repositories.TravelGroupRepository) *BudgetHandler {
+	return &BudgetHandler{budgets: budgets, groupRepo: groupRepo}
+}
+
+// GetBudgetHandler lida com GET /groups/{id}/budget: planejado x realizado,
+// por categoria e por membro. Sem orçamento, só o realizado é preenchido.
+func (h *BudgetHandler) GetBudgetHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView); !ok {
+		return
+	}
+
+	h.writeReport(w, r, groupID)
+}
+
+// UpdateBudgetHandler lida com PUT /groups/{id}/budget (organizador e
+// co-organizadores) e responde com o relatório já recalculado.
+func (h *BudgetHandler) UpdateBudgetHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermEditGroup); !ok {
+		return
+	}
+
+	var req models.BudgetUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	if err := h.budgets.SaveBudget(r.Context(), groupID, req); err != nil {
+		var invalid *services.ValidationError
+		if errors.As(err, &invalid) {
+			writeValidationError(w, r, http.StatusUnprocessableEntity, problem.CodeValidationFailed, invalid.Fields)
+			return
+		}
+		fmt.Printf("Erro ao gravar orçamento do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.save_budget")
+		return
+	}
+
+	checkBudgetAlerts(r, h.budgets, groupID)
+	h.writeReport(w, r, groupID)
+}
+
+// DeleteBudgetHandler lida com DELETE /groups/{id}/budget (organizador e co-organizadores).
+func (h *BudgetHandler) DeleteBudgetHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermEditGroup); !ok {
+		return
+	}
+
+	if err := h.budgets.DeleteBudget(r.Context(), groupID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao apagar orçamento do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.delete_budget")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (h *BudgetHandler) writeReport(w http.ResponseWriter, r *http.Request, groupID int) {
+	report, err := h.budgets.GetReport(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao calcular orçamento do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.budget")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(report)
+}
+
+// checkBudgetAlerts avisa os organizadores das categorias que atingiram o
+// limite. Uma falha no aviso não desfaz a alteração que o disparou: só é registrada.
+func checkBudgetAlerts(r *http.Request, budgets services.BudgetService, groupID int) {
+	if err := budgets.CheckAlerts(r.Context(), groupID); err != nil {
+		fmt.Printf("Erro ao enviar avisos do orçamento do grupo %d: %v\n", groupID, err)
+	}
+}
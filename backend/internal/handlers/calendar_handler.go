@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project_lab/internal/middleware"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+	"strings"
+	"time"
+)
+
+type CalendarHandler struct {
+	calendarService services.CalendarService
+	groupRepo       repositories.TravelGroupRepository
+	feedRepo        repositories.CalendarFeedRepository
+	publicURL       string
+}
+
+// NewCalendarHandler cria o handler de calendário. publicURL é a URL base da API
+// usada nos links do feed (ex.: https://api.viagens.com); se vazia, é deduzida
+// da própria requisição.
+func NewCalendarHandler(calendarService services.CalendarService, groupRepo repositories.TravelGroupRepository, feedRepo repositories.CalendarFeedRepository, publicURL string) *CalendarHandler {
+	return &CalendarHandler{
+		calendarService: calendarService,
+		groupRepo:       groupRepo,
+		feedRepo:        feedRepo,
+		publicURL:       strings.TrimRight(publicURL, "/"),
+	}
+}
+
+// GetGroupCalendarHandler lida com GET /groups/{id}/calendar.ics
+func (h *CalendarHandler) GetGroupCalendarHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	_, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView)
+	if !ok {
+		return
+	}
+
+	name, events, err := h.calendarService.GroupEvents(r.Context(), groupID, userID)
+	if err != nil {
+		fmt.Printf("Erro ao montar calendário do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.calendar")
+		return
+	}
+
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="grupo-%d.ics"`, groupID))
+	writeCalendar(w, name, events)
+}
+
+// GetCalendarFeedHandler lida com GET /calendar/{token}.ics. A rota é pública:
+// clientes de calendário não enviam o header Bearer, então o token na URL é a
+// credencial do feed.
+func (h *CalendarHandler) GetCalendarFeedHandler(w http.ResponseWriter, r *http.Request, token string) {
+
+	userID, err := h.feedRepo.FindUserByFeedToken(r.Context(), token)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao validar token do feed: %v\n", err)
+		writeServerError(w, r, err, "internal.calendar")
+		return
+	}
+
+	name, events, err := h.calendarService.UserEvents(r.Context(), userID)
+	if err != nil {
+		fmt.Printf("Erro ao montar feed de calendário do usuário %d: %v\n", userID, err)
+		writeServerError(w, r, err, "internal.calendar")
+		return
+	}
+
+	writeCalendar(w, name, events)
+}
+
+// CreateCalendarFeedHandler lida com POST /profile/calendar-feed.
+// Gera (ou troca) o token do feed; o link anterior deixa de funcionar.
+func (h *CalendarHandler) CreateCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userIDValue := r.Context().Value(middleware.UserIDKey)
+	userID, ok := userIDValue.(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return
+	}
+
+	token, err := h.feedRepo.RotateFeedToken(r.Context(), userID)
+	if err != nil {
+		fmt.Printf("Erro ao gerar feed de calendário do usuário %d: %v\n", userID, err)
+		writeServerError(w, r, err, "internal.create_calendar_feed")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{
+		"url": h.baseURL(r) + "/calendar/" + token + ".ics",
+	})
+}
+
+// RevokeCalendarFeedHandler lida com DELETE /profile/calendar-feed
+func (h *CalendarHandler) RevokeCalendarFeedHandler(w http.ResponseWriter, r *http.Request) {
+	userIDValue := r.Context().Value(middleware.UserIDKey)
+	userID, ok := userIDValue.(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return
+	}
+
+	if err := h.feedRepo.RevokeFeedToken(r.Context(), userID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao revogar feed de calendário do usuário %d: %v\n", userID, err)
+		writeServerError(w, r, err, "internal.revoke_calendar_feed")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// baseURL é a URL pública da API (API_URL). Sem ela, usa o host da requisição
+// e o esquema resolvido pelo middleware RealIP, que só aceita o
+// X-Forwarded-Proto de proxies confiáveis (TRUSTED_PROXIES).
+func (h *CalendarHandler) baseURL(r *http.Request) string {
+	if h.publicURL != "" {
+		return h.publicURL
+	}
+	return middleware.Scheme(r) + "://" + r.Host
+}
+
+func writeCalendar(w http.ResponseWriter, name string, events []services.CalendarEvent) {
+	w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+	if err := services.WriteICalendar(w, name, events, time.Now()); err != nil {
+		fmt.Printf("Erro ao escrever calendário: %v\n", err)
+	}
+}
@@ -0,0 +1,122 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"project_lab/internal/i18n"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+	"project_lab/internal/storage"
+)
+
+// domainError associa um erro de domínio ao status HTTP, ao código e à
+// mensagem (ID do catálogo i18n) devolvidos ao cliente.
+type domainError struct {
+	err     error
+	status  int
+	code    problem.Code
+	message string
+}
+
+// domainErrors é o único mapeamento entre os erros de repositories/services e
+// as respostas HTTP. Erros com detalhes do caso (i18n.Error) usam a própria
+// mensagem no "detail"; os demais, a mensagem do mapeamento.
+var domainErrors = []domainError{
+	{services.ErrInvalidCredentials, http.StatusUnauthorized, problem.CodeInvalidCredentials, "error.invalid_credentials"},
+	{services.ErrInvalidAccessToken, http.StatusUnauthorized, problem.CodeInvalidToken, "auth.token_invalid"},
+	{services.ErrInvalidActionToken, http.StatusBadRequest, problem.CodeActionTokenInvalid, "error.action_token_invalid"},
+	{repositories.ErrRefreshTokenReused, http.StatusUnauthorized, problem.CodeRefreshTokenReused, "error.refresh_token_reused"},
+	{repositories.ErrRefreshTokenInvalid, http.StatusUnauthorized, problem.CodeRefreshTokenInvalid, "error.refresh_token_invalid"},
+	{repositories.ErrEmailAlreadyExists, http.StatusConflict, problem.CodeEmailTaken, "error.email_taken"},
+	{repositories.ErrUserNotFound, http.StatusNotFound, problem.CodeUserNotFound, "error.user_not_found"},
+
+	{repositories.ErrGroupNotFound, http.StatusNotFound, problem.CodeGroupNotFound, "error.group_not_found"},
+	{repositories.ErrMemberNotFound, http.StatusNotFound, problem.CodeMemberNotFound, "error.member_not_found"},
+	{repositories.ErrDestinationNotFound, http.StatusNotFound, problem.CodeDestinationNotFound, "error.destination_not_found"},
+	{repositories.ErrExpenseNotFound, http.StatusNotFound, problem.CodeExpenseNotFound, "error.expense_not_found"},
+	{repositories.ErrAttachmentNotFound, http.StatusNotFound, problem.CodeAttachmentNotFound, "error.attachment_not_found"},
+	{storage.ErrBlobNotFound, http.StatusNotFound, problem.CodeAttachmentNotFound, "error.attachment_not_found"},
+	{services.ErrInvalidAttachment, http.StatusUnprocessableEntity, problem.CodeInvalidAttachment, "error.invalid_attachment"},
+	{services.ErrAttachmentTooLarge, http.StatusRequestEntityTooLarge, problem.CodeAttachmentTooLarge, "error.attachment_too_large"},
+	{services.ErrTooManyAttachments, http.StatusConflict, problem.CodeTooManyAttachments, "error.too_many_attachments"},
+	{repositories.ErrBudgetNotFound, http.StatusNotFound, problem.CodeBudgetNotFound, "error.budget_not_found"},
+	{repositories.ErrRateNotFound, http.StatusUnprocessableEntity, problem.CodeRateNotFound, "error.exchange_rate_not_found"},
+	{services.ErrInvalidSplit, http.StatusUnprocessableEntity, problem.CodeInvalidSplit, "error.invalid_split"},
+	{repositories.ErrPaymentNotFound, http.StatusNotFound, problem.CodePaymentNotFound, "error.payment_not_found"},
+	{services.ErrInvalidPayment, http.StatusUnprocessableEntity, problem.CodeInvalidPayment, "error.invalid_payment"},
+	{services.ErrOwnershipTransferRequired, http.StatusConflict, problem.CodeOwnershipTransferRequired, "member.organizer_cannot_be_removed"},
+	{services.ErrMemberRemovalForbidden, http.StatusForbidden, problem.CodeNotGroupOrganizer, "member.organizer_only_remove"},
+	{services.ErrUnsettledBalance, http.StatusConflict, problem.CodeUnsettledBalance, "error.unsettled_balance"},
+
+	{repositories.ErrItineraryItemNotFound, http.StatusNotFound, problem.CodeItineraryItemNotFound, "error.itinerary_item_not_found"},
+	{repositories.ErrItineraryOutsideDates, http.StatusConflict, problem.CodeItineraryOutsideDates, "error.itinerary_outside_dates"},
+	{services.ErrInvalidItineraryItem, http.StatusUnprocessableEntity, problem.CodeInvalidItineraryItem, "error.invalid_itinerary_item"},
+
+	{repositories.ErrVotingNotFound, http.StatusNotFound, problem.CodeVotingNotFound, "voting.not_found"},
+	{repositories.ErrVoteNotFound, http.StatusNotFound, problem.CodeVoteNotFound, "error.vote_not_found"},
+	{repositories.ErrVotingLocked, http.StatusConflict, problem.CodeConflict, "voting.locked_after_votes"},
+	{repositories.ErrAlreadyVoted, http.StatusConflict, problem.CodeAlreadyVoted, "error.already_voted"},
+	{services.ErrInvalidVoting, http.StatusUnprocessableEntity, problem.CodeInvalidVoting, "error.invalid_voting"},
+	{services.ErrInvalidBallot, http.StatusUnprocessableEntity, problem.CodeInvalidBallot, "error.invalid_ballot"},
+
+	{repositories.ErrInviteNotFound, http.StatusNotFound, problem.CodeInviteNotFound, "error.invite_not_found"},
+	{repositories.ErrInviteNotPending, http.StatusGone, problem.CodeInviteUnavailable, "error.invite_unavailable"},
+	{repositories.ErrInviteExpired, http.StatusGone, problem.CodeInviteUnavailable, "error.invite_unavailable"},
+	{repositories.ErrInviteExhausted, http.StatusGone, problem.CodeInviteUnavailable, "error.invite_unavailable"},
+	{repositories.ErrInviteEmailMismatch, http.StatusForbidden, problem.CodeInviteEmailMismatch, "error.invite_email_mismatch"},
+	{repositories.ErrAlreadyGroupMember, http.StatusConflict, problem.CodeAlreadyGroupMember, "error.already_group_member"},
+
+	{repositories.ErrFeedTokenNotFound, http.StatusNotFound, problem.CodeFeedNotFound, "error.calendar_feed_not_found"},
+}
+
+// writeDomainError responde com o problema correspondente a err e devolve
+// true; para erros fora do mapeamento não escreve nada e devolve false, e o
+// chamador trata o erro como interno.
+func writeDomainError(w http.ResponseWriter, r *http.Request, err error) bool {
+	for _, d := range domainErrors {
+		if errors.Is(err, d.err) {
+			problem.Write(w, problem.New(d.status, d.code, localizeError(r, err, d.message)))
+			return true
+		}
+	}
+	return false
+}
+
+// writeServerError responde a um erro inesperado (fora de domainErrors) com
+// 500 e a mensagem id, exceto quando a requisição não chegou ao fim por causa
+// do contexto: 499 se o cliente desistiu e 504 se o prazo da consulta esgotou.
+func writeServerError(w http.ResponseWriter, r *http.Request, err error, id string) {
+	switch {
+	case errors.Is(r.Context().Err(), context.Canceled):
+		// O driver também devolve query_canceled quando o cliente desiste, por
+		// isso o contexto da requisição é conferido primeiro.
+		problem.Error(w, r, problem.StatusClientClosedRequest, "error.request_canceled")
+	case errors.Is(err, context.DeadlineExceeded), repositories.IsQueryCanceled(err):
+		problem.Error(w, r, http.StatusGatewayTimeout, "error.timeout")
+	default:
+		problem.Error(w, r, http.StatusInternalServerError, id)
+	}
+}
+
+// localizeError traduz o erro para o idioma da requisição: a mensagem do
+// i18n.Error, se houver, ou a mensagem padrão informada.
+func localizeError(r *http.Request, err error, fallback string) string {
+	locale := i18n.FromContext(r.Context())
+	var localized *i18n.Error
+	if errors.As(err, &localized) {
+		return localized.Message.In(locale)
+	}
+	return i18n.T(locale, fallback)
+}
+
+// writeValidationError responde com os erros de validação por campo.
+func writeValidationError(w http.ResponseWriter, r *http.Request, status int, code problem.Code, fields []services.FieldError) {
+	locale := i18n.FromContext(r.Context())
+	p := problem.Localized(r, status, code, "request.invalid_data")
+	for _, f := range fields {
+		p.Errors = append(p.Errors, problem.FieldError{Field: f.Field, Message: f.Message.In(locale)})
+	}
+	problem.Write(w, p)
+}
@@ -0,0 +1,72 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"project_lab/internal/middleware"
+	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+)
+
+// permissionDenied associa cada permissão ao código e à mensagem do 403
+// devolvido a quem não a tem.
+var permissionDenied = map[services.Permission]struct {
+	code    problem.Code
+	message string
+}{
+	services.PermContribute:        {problem.CodeReadOnlyMember, "group.read_only"},
+	services.PermEditGroup:         {problem.CodeNotGroupOrganizer, "group.organizer_only_update"},
+	services.PermInviteMembers:     {problem.CodeNotGroupOrganizer, "invite.organizer_only"},
+	services.PermRemoveMembers:     {problem.CodeNotGroupOrganizer, "member.organizer_only_remove"},
+	services.PermChangeRoles:       {problem.CodeNotGroupOrganizer, "member.organizer_only_roles"},
+	services.PermTransferOwnership: {problem.CodeNotGroupOrganizer, "group.organizer_only_transfer"},
+	services.PermDeleteGroup:       {problem.CodeNotGroupOrganizer, "group.organizer_only_delete"},
+}
+
+// authorizeGroup é a verificação de acesso (Mitigação A01) de todos os
+// handlers que operam sobre um grupo: o usuário autenticado precisa ser membro
+// (senão 404, sem revelar que o grupo existe) e o papel dele precisa ter a
+// permissão (senão 403). Devolve os detalhes do grupo, com o papel do usuário.
+func authorizeGroup(w http.ResponseWriter, r *http.Request, repo repositories.TravelGroupRepository, groupID int, permission services.Permission) (group *models.TravelGroupDetails, userID int, ok bool) {
+	userID, ok = r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return nil, 0, false
+	}
+
+	group, err := repo.GetGroupDetails(r.Context(), groupID, userID)
+	if err != nil {
+		// ErrGroupNotFound: o usuário não é membro ou o grupo não existe.
+		if errors.Is(err, repositories.ErrGroupNotFound) {
+			problem.Write(w, problem.Localized(r, http.StatusNotFound, problem.CodeGroupNotFound, "group.not_found_or_forbidden"))
+			return nil, userID, false
+		}
+		fmt.Printf("Erro ao buscar grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.get_group")
+		return nil, userID, false
+	}
+
+	if !services.RoleCan(group.Role, permission) {
+		denied, found := permissionDenied[permission]
+		if !found {
+			denied.code, denied.message = problem.CodeForbidden, "group.permission_denied"
+		}
+		problem.Write(w, problem.Localized(r, http.StatusForbidden, denied.code, denied.message))
+		return nil, userID, false
+	}
+
+	return group, userID, true
+}
+
+// canManage aplica a regra de autorização das alterações: organizador e
+// co-organizadores alteram qualquer recurso; os demais membros que podem
+// contribuir, apenas o que criaram.
+func canManage(group *models.TravelGroupDetails, userID int, authorID *int) bool {
+	if services.RoleCan(group.Role, services.PermManageContent) {
+		return true
+	}
+	return authorID != nil && *authorID == userID && services.RoleCan(group.Role, services.PermContribute)
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project_lab/internal/i18n"
+	"project_lab/internal/middleware"
+	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/services"
+	"strings"
+)
+
+// UpdateMemberRoleHandler lida com PATCH /groups/{id}/members/{userId}
+// (apenas o organizador): promove ou rebaixa um membro entre co-organizador,
+// participante e leitor.
+func (h *TravelGroupHandler) UpdateMemberRoleHandler(w http.ResponseWriter, r *http.Request, groupID int, memberID int) {
+	if _, _, ok := authorizeGroup(w, r, h.repo, groupID, services.PermChangeRoles); !ok {
+		return
+	}
+
+	var req models.MemberRoleUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	req.Role = strings.TrimSpace(req.Role)
+	if msg := services.ValidateAssignableRole(req.Role); msg != nil {
+		writeValidationError(w, r, http.StatusUnprocessableEntity, problem.CodeValidationFailed, []services.FieldError{{Field: "role", Message: *msg}})
+		return
+	}
+
+	role, err := h.repo.GetMemberRole(r.Context(), groupID, memberID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao buscar membro %d do grupo %d: %v\n", memberID, groupID, err)
+		writeServerError(w, r, err, "internal.get_member")
+		return
+	}
+	if role == models.RoleOrganizer {
+		problem.Write(w, problem.Localized(r, http.StatusConflict, problem.CodeOrganizerRoleFixed, "member.organizer_role_fixed"))
+		return
+	}
+
+	if err := h.repo.UpdateMemberRole(r.Context(), groupID, memberID, req.Role); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao alterar papel do membro %d do grupo %d: %v\n", memberID, groupID, err)
+		writeServerError(w, r, err, "internal.update_member_role")
+		return
+	}
+
+	h.writeMembers(w, r, groupID, false)
+}
+
+// TransferOwnershipHandler lida com POST /groups/{id}/transfer-ownership
+// (apenas o organizador): o membro informado vira o organizador e quem
+// transferiu passa a co-organizador.
+func (h *TravelGroupHandler) TransferOwnershipHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+	_, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermTransferOwnership)
+	if !ok {
+		return
+	}
+
+	var req models.OwnershipTransferRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	if req.UserID == userID {
+		writeValidationError(w, r, http.StatusUnprocessableEntity, problem.CodeValidationFailed,
+			[]services.FieldError{{Field: "userId", Message: i18n.M("validation.transfer_to_self")}})
+		return
+	}
+
+	if err := h.repo.TransferOwnership(r.Context(), groupID, req.UserID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao transferir grupo %d para o usuário %d: %v\n", groupID, req.UserID, err)
+		writeServerError(w, r, err, "internal.transfer_ownership")
+		return
+	}
+
+	h.writeMembers(w, r, groupID, false)
+}
+
+// RemoveMemberHandler lida com DELETE /groups/{id}/members/{userId}.
+// Organizador e co-organizadores removem participantes e leitores; só o
+// organizador remove co-organizadores; ninguém remove o organizador. Quando o
+// alvo é o próprio usuário, valem as regras de LeaveGroupHandler. Os papéis
+// são conferidos de novo na transação da saída (services.CheckMemberRemoval).
+func (h *TravelGroupHandler) RemoveMemberHandler(w http.ResponseWriter, r *http.Request, groupID int, memberID int) {
+	if userID, ok := r.Context().Value(middleware.UserIDKey).(int); ok && userID == memberID {
+		h.LeaveGroupHandler(w, r, groupID)
+		return
+	}
+
+	_, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermRemoveMembers)
+	if !ok {
+		return
+	}
+
+	h.removeMember(w, r, groupID, memberID, userID, "internal.remove_member")
+}
+
+// LeaveGroupHandler lida com POST /groups/{id}/leave: o usuário sai do grupo.
+// O organizador precisa transferir o grupo antes, e ninguém sai com saldo
+// pendente. Despesas e votos antigos continuam, atribuídos a um ex-membro.
+func (h *TravelGroupHandler) LeaveGroupHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+	_, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermView)
+	if !ok {
+		return
+	}
+
+	h.removeMember(w, r, groupID, userID, userID, "internal.leave_group")
+}
+
+// removeMember registra a saída do membro pedida por actorID, desde que os
+// papéis permitam e o saldo dele esteja zerado, respondendo 204.
+func (h *TravelGroupHandler) removeMember(w http.ResponseWriter, r *http.Request, groupID int, memberID int, actorID int, internalID string) {
+	if err := h.settlements.RemoveMember(r.Context(), groupID, memberID, actorID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao remover membro %d do grupo %d: %v\n", memberID, groupID, err)
+		writeServerError(w, r, err, internalID)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeMembers responde com a lista de membros já com os papéis atualizados;
+// com includeFormer, inclui os ex-membros.
+func (h *TravelGroupHandler) writeMembers(w http.ResponseWriter, r *http.Request, groupID int, includeFormer bool) {
+	members, err := h.repo.ListGroupMembers(r.Context(), groupID, includeFormer)
+	if err != nil {
+		fmt.Printf("Erro ao buscar lista de membros do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.list_members")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(members)
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"project_lab/internal/middleware"
+	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+	"strings"
+	"time"
+)
+
+// defaultInviteExpiration é usado quando o organizador não informa expiresInHours.
+const defaultInviteExpiration = 7 * 24 * time.Hour
+
+type InviteHandler struct {
+	inviteRepo repositories.InviteRepository
+	groupRepo  repositories.TravelGroupRepository
+}
+
+func NewInviteHandler(inviteRepo repositories.InviteRepository, groupRepo repositories.TravelGroupRepository) *InviteHandler {
+	return &InviteHandler{inviteRepo: inviteRepo, groupRepo: groupRepo}
+}
+
+// CreateInviteHandler lida com POST /groups/{id}/invites
+func (h *InviteHandler) CreateInviteHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	_, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermInviteMembers)
+	if !ok {
+		return
+	}
+
+	var req models.InviteCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	invite := models.GroupInvite{
+		TravelGroupID: groupID,
+		CreatedBy:     userID,
+	}
+
+	email := strings.TrimSpace(req.Email)
+	if email != "" {
+		if _, err := mail.ParseAddress(email); err != nil {
+			problem.Error(w, r, http.StatusUnprocessableEntity, "invite.invalid_email")
+			return
+		}
+		// Convites por e-mail são sempre de uso único.
+		single := 1
+		invite.Email = &email
+		invite.MaxUses = &single
+	} else if req.MaxUses != nil {
+		if *req.MaxUses < 1 {
+			problem.Error(w, r, http.StatusUnprocessableEntity, "invite.max_uses_positive")
+			return
+		}
+		invite.MaxUses = req.MaxUses
+	}
+
+	expiration := defaultInviteExpiration
+	if req.ExpiresInHours != nil {
+		if *req.ExpiresInHours < 1 {
+			problem.Error(w, r, http.StatusUnprocessableEntity, "invite.min_validity")
+			return
+		}
+		expiration = time.Duration(*req.ExpiresInHours) * time.Hour
+	}
+	expiresAt := time.Now().Add(expiration)
+	invite.ExpiresAt = &expiresAt
+
+	if err := h.inviteRepo.CreateInvite(r.Context(), &invite); err != nil {
+		fmt.Printf("Erro ao criar convite no BD: %v\n", err)
+		writeServerError(w, r, err, "internal.create_invite")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invite)
+}
+
+// ListGroupInvitesHandler lida com GET /groups/{id}/invites
+func (h *InviteHandler) ListGroupInvitesHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermInviteMembers); !ok {
+		return
+	}
+
+	invites, err := h.inviteRepo.ListGroupInvites(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar convites do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.list_group_invites")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invites)
+}
+
+// RevokeInviteHandler lida com DELETE /groups/{id}/invites/{inviteId}
+func (h *InviteHandler) RevokeInviteHandler(w http.ResponseWriter, r *http.Request, groupID int, inviteID int) {
+
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermInviteMembers); !ok {
+		return
+	}
+
+	if err := h.inviteRepo.RevokeInvite(r.Context(), groupID, inviteID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao revogar convite %d: %v\n", inviteID, err)
+		writeServerError(w, r, err, "internal.revoke_invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// ListMyInvitesHandler lida com GET /invites (convites por e-mail pendentes do usuário logado)
+func (h *InviteHandler) ListMyInvitesHandler(w http.ResponseWriter, r *http.Request) {
+	userIDValue := r.Context().Value(middleware.UserIDKey)
+	userID, ok := userIDValue.(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return
+	}
+
+	invites, err := h.inviteRepo.ListPendingInvitesForUser(r.Context(), userID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar convites pendentes do usuário %d: %v\n", userID, err)
+		writeServerError(w, r, err, "internal.list_invites")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(invites)
+}
+
+// writeInviteError traduz os erros de domínio dos convites em respostas HTTP.
+func writeInviteError(w http.ResponseWriter, r *http.Request, err error) {
+	if writeDomainError(w, r, err) {
+		return
+	}
+	fmt.Printf("Erro ao processar convite: %v\n", err)
+	writeServerError(w, r, err, "internal.process_invite")
+}
+
+// AcceptInviteHandler lida com POST /invites/{code}/accept
+func (h *InviteHandler) AcceptInviteHandler(w http.ResponseWriter, r *http.Request, code string) {
+	userIDValue := r.Context().Value(middleware.UserIDKey)
+	userID, ok := userIDValue.(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return
+	}
+
+	groupID, err := h.inviteRepo.AcceptInvite(r.Context(), code, userID)
+	if err != nil {
+		writeInviteError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{"groupId": groupID})
+}
+
+// DeclineInviteHandler lida com POST /invites/{code}/decline
+func (h *InviteHandler) DeclineInviteHandler(w http.ResponseWriter, r *http.Request, code string) {
+	userIDValue := r.Context().Value(middleware.UserIDKey)
+	userID, ok := userIDValue.(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return
+	}
+
+	if err := h.inviteRepo.DeclineInvite(r.Context(), code, userID); err != nil {
+		writeInviteError(w, r, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+	"strings"
+)
+
+type ItineraryHandler struct {
+	itineraryRepo repositories.ItineraryRepository
+	groupRepo     repositories.TravelGroupRepository
+}
+
+func NewItineraryHandler(itineraryRepo repositories.ItineraryRepository, groupRepo repositories.TravelGroupRepository) *ItineraryHandler {
+	return &ItineraryHandler{itineraryRepo: itineraryRepo, groupRepo: groupRepo}
+}
+
+// ListItineraryHandler lida com GET /groups/{id}/itinerary
+func (h *ItineraryHandler) ListItineraryHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView); !ok {
+		return
+	}
+
+	items, err := h.itineraryRepo.ListItems(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar roteiro do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.get_itinerary")
+		return
+	}
+	services.MarkOverlaps(items)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(items)
+}
+
+// ListItineraryDaysHandler lida com GET /groups/{id}/itinerary/days
+func (h *ItineraryHandler) ListItineraryDaysHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	group, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView)
+	if !ok {
+		return
+	}
+
+	items, err := h.itineraryRepo.ListItems(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar roteiro do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.get_itinerary")
+		return
+	}
+	services.MarkOverlaps(items)
+
+	days := services.GroupItineraryByDay(items, group.StartDate, group.EndDate)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(days)
+}
+
+// CreateItineraryItemHandler lida com POST /groups/{id}/itinerary
+func (h *ItineraryHandler) CreateItineraryItemHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	group, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermContribute)
+	if !ok {
+		return
+	}
+
+	item := models.ItineraryItem{
+		TravelGroupID: groupID,
+		CreatedBy:     userID,
+	}
+	if !h.applyItemRequest(w, r, group, &item) {
+		return
+	}
+
+	if err := h.itineraryRepo.CreateItem(r.Context(), &item); err != nil {
+		fmt.Printf("Erro ao criar item do roteiro no BD: %v\n", err)
+		writeServerError(w, r, err, "internal.create_itinerary_item")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(item)
+}
+
+// UpdateItineraryItemHandler lida com PUT /groups/{id}/itinerary/{itemId}
+func (h *ItineraryHandler) UpdateItineraryItemHandler(w http.ResponseWriter, r *http.Request, groupID int, itemID int) {
+
+	group, item, ok := h.loadEditableItem(w, r, groupID, itemID)
+	if !ok {
+		return
+	}
+
+	if !h.applyItemRequest(w, r, group, item) {
+		return
+	}
+
+	if err := h.itineraryRepo.UpdateItem(r.Context(), item); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao alterar item %d do roteiro: %v\n", item.ID, err)
+		writeServerError(w, r, err, "internal.update_itinerary_item")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(item)
+}
+
+// DeleteItineraryItemHandler lida com DELETE /groups/{id}/itinerary/{itemId}
+func (h *ItineraryHandler) DeleteItineraryItemHandler(w http.ResponseWriter, r *http.Request, groupID int, itemID int) {
+
+	_, item, ok := h.loadEditableItem(w, r, groupID, itemID)
+	if !ok {
+		return
+	}
+
+	if err := h.itineraryRepo.DeleteItem(r.Context(), item.TravelGroupID, item.ID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao remover item %d do roteiro: %v\n", item.ID, err)
+		writeServerError(w, r, err, "internal.delete_itinerary_item")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadEditableItem busca o item e garante que o usuário autenticado é o autor
+// do item ou um organizador do grupo.
+func (h *ItineraryHandler) loadEditableItem(w http.ResponseWriter, r *http.Request, groupID int, itemID int) (*models.TravelGroupDetails, *models.ItineraryItem, bool) {
+	group, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermContribute)
+	if !ok {
+		return nil, nil, false
+	}
+
+	item, err := h.itineraryRepo.GetItem(r.Context(), groupID, itemID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return nil, nil, false
+		}
+		fmt.Printf("Erro ao buscar item %d do roteiro: %v\n", itemID, err)
+		writeServerError(w, r, err, "internal.get_itinerary_item")
+		return nil, nil, false
+	}
+
+	if !canManage(group, userID, &item.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "itinerary.author_only")
+		return nil, nil, false
+	}
+
+	return group, item, true
+}
+
+// applyItemRequest lê o corpo da requisição, aplica os campos em item e valida
+// destino, datas da viagem e sobreposição com os demais itens do roteiro.
+func (h *ItineraryHandler) applyItemRequest(w http.ResponseWriter, r *http.Request, group *models.TravelGroupDetails, item *models.ItineraryItem) bool {
+	var req models.ItineraryItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json_datetime")
+		return false
+	}
+
+	if req.StartsAt == nil || req.EndsAt == nil {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "itinerary.times_required")
+		return false
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = group.BaseCurrency
+	}
+	if !models.IsValidCurrency(currency) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "request.invalid_currency")
+		return false
+	}
+	if req.EstimatedCost != nil && *req.EstimatedCost < 0 {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "destination.negative_cost")
+		return false
+	}
+	if req.EstimatedCost != nil && *req.EstimatedCost > models.MaxMoney {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "validation.amount_too_large", models.MaxMoney.String())
+		return false
+	}
+
+	item.DestinationID = req.DestinationID
+	item.DestinationName = nil
+	item.Activity = strings.TrimSpace(req.Activity)
+	item.StartsAt = *req.StartsAt
+	item.EndsAt = *req.EndsAt
+	item.Notes = req.Notes
+	item.EstimatedCost = req.EstimatedCost
+	item.Currency = currency
+
+	if err := services.ValidateItineraryItem(item, group.StartDate, group.EndDate); err != nil {
+		problem.Write(w, problem.New(http.StatusUnprocessableEntity, problem.CodeInvalidItineraryItem, localizeError(r, err, "error.invalid_itinerary_item")))
+		return false
+	}
+
+	if item.DestinationID != nil {
+		name, err := h.itineraryRepo.GetDestinationName(r.Context(), item.TravelGroupID, *item.DestinationID)
+		if err != nil {
+			if errors.Is(err, repositories.ErrDestinationNotFound) {
+				problem.Write(w, problem.Localized(r, http.StatusUnprocessableEntity, problem.CodeDestinationNotFound, "destination.not_in_group"))
+				return false
+			}
+			fmt.Printf("Erro ao validar destino %d: %v\n", *item.DestinationID, err)
+			writeServerError(w, r, err, "internal.validate_destination")
+			return false
+		}
+		item.DestinationName = &name
+	}
+
+	existing, err := h.itineraryRepo.ListItems(r.Context(), item.TravelGroupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar roteiro do grupo %d: %v\n", item.TravelGroupID, err)
+		writeServerError(w, r, err, "internal.get_itinerary")
+		return false
+	}
+
+	conflicts := services.FindOverlaps(item, existing)
+	item.OverlapsWith = []int{}
+	for _, c := range conflicts {
+		item.OverlapsWith = append(item.OverlapsWith, c.ID)
+	}
+	if len(conflicts) > 0 && !req.AllowOverlap {
+		names := make([]string, len(conflicts))
+		for i, c := range conflicts {
+			names[i] = fmt.Sprintf("%s (#%d, %s–%s)", c.Activity, c.ID, c.StartsAt, c.EndsAt)
+		}
+		problem.Error(w, r, http.StatusConflict, "itinerary.conflict", strings.Join(names, "; "))
+		return false
+	}
+
+	return true
+}
@@ -4,37 +4,44 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"project_lab/internal/i18n"
 	"project_lab/internal/middleware"
 	"project_lab/internal/models"
+	"project_lab/internal/problem"
 	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+	"strings"
 )
 
 type ProfileHandler struct {
-	userRepo repositories.UserRepository
+	userRepo      repositories.UserRepository
+	loginAttempts repositories.LoginAttemptRepository
 }
 
-func NewProfileHandler(userRepo repositories.UserRepository) *ProfileHandler {
-	return &ProfileHandler{userRepo: userRepo}
+func NewProfileHandler(userRepo repositories.UserRepository, loginAttempts repositories.LoginAttemptRepository) *ProfileHandler {
+	return &ProfileHandler{userRepo: userRepo, loginAttempts: loginAttempts}
 }
 
+// failedLoginsLimit é quantas tentativas malsucedidas GET /profile/failed-logins devolve.
+const failedLoginsLimit = 50
+
 // GetProfileHandler lida com GET /profile
 func (h *ProfileHandler) GetProfileHandler(w http.ResponseWriter, r *http.Request) {
 	// Obtém o ID do usuário do contexto JWT (AuthMiddleware)
 	userIDValue := r.Context().Value(middleware.UserIDKey)
 	userID, ok := userIDValue.(int)
 	if !ok {
-		http.Error(w, "Não autorizado. ID do usuário não encontrado.", http.StatusUnauthorized)
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
 		return
 	}
 
-	profile, err := h.userRepo.GetUserProfile(userID)
+	profile, err := h.userRepo.GetUserProfile(r.Context(), userID)
 	if err != nil {
-		if err.Error() == "usuário não encontrado" {
-			http.Error(w, "Perfil não encontrado.", http.StatusNotFound)
+		if writeDomainError(w, r, err) {
 			return
 		}
 		fmt.Printf("Erro ao buscar perfil do BD: %v\n", err)
-		http.Error(w, "Erro interno ao buscar perfil.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.get_profile")
 		return
 	}
 
@@ -48,37 +55,89 @@ func (h *ProfileHandler) UpdateProfileHandler(w http.ResponseWriter, r *http.Req
 	userIDValue := r.Context().Value(middleware.UserIDKey)
 	userID, ok := userIDValue.(int)
 	if !ok {
-		http.Error(w, "Não autorizado. ID do usuário não encontrado.", http.StatusUnauthorized)
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
 		return
 	}
 
 	var req models.UserProfileUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Requisição inválida (JSON).", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
-	// Validação de negócio (conforme o que foi feito no frontend)
-	if req.Name == "" || len(req.Name) < 3 {
-		http.Error(w, "Nome é obrigatório e deve ter no mínimo 3 caracteres.", http.StatusUnprocessableEntity)
+	if req.Name == nil && req.Locale == nil {
+		problem.Error(w, r, http.StatusBadRequest, "profile.nothing_to_update")
 		return
 	}
 
-	if err := h.userRepo.UpdateUserName(userID, req.Name); err != nil {
-		fmt.Printf("Erro ao atualizar nome do usuário %d: %v\n", userID, err)
-		http.Error(w, "Erro interno ao atualizar perfil.", http.StatusInternalServerError)
+	// Validação de negócio: mesmas regras do cadastro
+	var fields []services.FieldError
+	if req.Name != nil {
+		*req.Name = services.NormalizeName(*req.Name)
+		if msg := services.ValidateName(*req.Name); msg != nil {
+			fields = append(fields, services.FieldError{Field: "name", Message: *msg})
+		}
+	}
+	if req.Locale != nil {
+		*req.Locale = strings.TrimSpace(*req.Locale)
+		if msg := services.ValidateLocale(*req.Locale); msg != nil {
+			fields = append(fields, services.FieldError{Field: "locale", Message: *msg})
+		}
+	}
+	if len(fields) > 0 {
+		writeValidationError(w, r, http.StatusUnprocessableEntity, problem.CodeValidationFailed, fields)
 		return
 	}
 
+	if req.Name != nil {
+		if err := h.userRepo.UpdateUserName(r.Context(), userID, *req.Name); err != nil {
+			fmt.Printf("Erro ao atualizar nome do usuário %d: %v\n", userID, err)
+			writeServerError(w, r, err, "internal.update_profile")
+			return
+		}
+	}
+	if req.Locale != nil {
+		// Grava a forma canônica ("en-US" vira "en").
+		locale := ""
+		if parsed, ok := i18n.Parse(*req.Locale); ok {
+			locale = string(parsed)
+		}
+		if err := h.userRepo.UpdatePreferredLocale(r.Context(), userID, locale); err != nil {
+			fmt.Printf("Erro ao atualizar idioma do usuário %d: %v\n", userID, err)
+			writeServerError(w, r, err, "internal.update_profile")
+			return
+		}
+	}
+
 	// Retorna o perfil atualizado, conforme o YAML (200 OK)
-	updatedProfile, err := h.userRepo.GetUserProfile(userID)
+	updatedProfile, err := h.userRepo.GetUserProfile(r.Context(), userID)
 	if err != nil {
 		fmt.Printf("Erro ao buscar perfil atualizado do BD: %v\n", err)
 		// A atualização foi feita, mas falhamos ao ler.
-		http.Error(w, "Perfil atualizado, mas falha ao retornar os dados.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.profile_updated_reload")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(updatedProfile)
 }
+
+// ListFailedLoginsHandler lida com GET /profile/failed-logins: as tentativas de
+// login malsucedidas mais recentes na conta do usuário.
+func (h *ProfileHandler) ListFailedLoginsHandler(w http.ResponseWriter, r *http.Request) {
+	userID, ok := r.Context().Value(middleware.UserIDKey).(int)
+	if !ok {
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return
+	}
+
+	entries, err := h.loginAttempts.ListAuditEntries(r.Context(), userID, failedLoginsLimit)
+	if err != nil {
+		fmt.Printf("Erro ao listar tentativas de login do usuário %d: %v\n", userID, err)
+		writeServerError(w, r, err, "internal.list_failed_logins")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(entries)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"project_lab/internal/models"
+	"project_lab/internal/problem"
+	"project_lab/internal/repositories"
+	"project_lab/internal/services"
+)
+
+type SettlementHandler struct {
+	settlementService services.SettlementService
+	groupRepo         repositories.TravelGroupRepository
+}
+
+func NewSettlementHandler(settlementService services.SettlementService, groupRepo repositories.TravelGroupRepository) *SettlementHandler {
+	return &SettlementHandler{settlementService: settlementService, groupRepo: groupRepo}
+}
+
+// GetGroupBalancesHandler lida com GET /groups/{id}/balances
+func (h *SettlementHandler) GetGroupBalancesHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView); !ok {
+		return
+	}
+
+	balances, err := h.settlementService.GetBalances(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao calcular saldos do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.balances")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(balances)
+}
+
+// GetGroupSettlementsHandler lida com GET /groups/{id}/settlements
+func (h *SettlementHandler) GetGroupSettlementsHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView); !ok {
+		return
+	}
+
+	settlements, err := h.settlementService.GetSettlements(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao calcular acertos do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.settlements")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(settlements)
+}
+
+// ListPaymentsHandler lida com GET /groups/{id}/payments
+func (h *SettlementHandler) ListPaymentsHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	if _, _, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermView); !ok {
+		return
+	}
+
+	payments, err := h.settlementService.ListPayments(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar pagamentos do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.list_payments")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(payments)
+}
+
+// CreatePaymentHandler lida com POST /groups/{id}/payments: registra um
+// pagamento de acerto. Quem contribui registra os pagamentos de que participou;
+// organizador e co-organizadores registram qualquer um.
+func (h *SettlementHandler) CreatePaymentHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+	group, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermContribute)
+	if !ok {
+		return
+	}
+
+	var req models.SettlementPaymentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	if userID != req.FromUserID && userID != req.ToUserID && !services.RoleCan(group.Role, services.PermManageContent) {
+		problem.Error(w, r, http.StatusForbidden, "payment.party_only")
+		return
+	}
+
+	payment := models.SettlementPayment{
+		TravelGroupID: groupID,
+		FromUserID:    req.FromUserID,
+		ToUserID:      req.ToUserID,
+		Amount:        req.Amount,
+		Currency:      req.Currency,
+		CreatedBy:     &userID,
+	}
+	if err := h.settlementService.RecordPayment(r.Context(), &payment); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao registrar pagamento no grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.create_payment")
+		return
+	}
+
+	// Devolve o pagamento com os nomes, como na listagem.
+	created, err := h.settlementService.GetPayment(r.Context(), groupID, payment.ID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar pagamento %d: %v\n", payment.ID, err)
+		writeServerError(w, r, err, "internal.create_payment")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// DeletePaymentHandler lida com DELETE /groups/{id}/payments/{paymentId}: quem
+// registrou o pagamento, o organizador ou os co-organizadores.
+func (h *SettlementHandler) DeletePaymentHandler(w http.ResponseWriter, r *http.Request, groupID int, paymentID int) {
+	group, userID, ok := authorizeGroup(w, r, h.groupRepo, groupID, services.PermContribute)
+	if !ok {
+		return
+	}
+
+	payment, err := h.settlementService.GetPayment(r.Context(), groupID, paymentID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao buscar pagamento %d: %v\n", paymentID, err)
+		writeServerError(w, r, err, "internal.delete_payment")
+		return
+	}
+	if !canManage(group, userID, payment.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "payment.author_only")
+		return
+	}
+
+	if err := h.settlementService.DeletePayment(r.Context(), groupID, paymentID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao apagar pagamento %d: %v\n", paymentID, err)
+		writeServerError(w, r, err, "internal.delete_payment")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
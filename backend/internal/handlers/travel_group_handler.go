@@ -6,45 +6,37 @@ import (
 	"net/http"
 	"project_lab/internal/middleware"
 	"project_lab/internal/models"
+	"project_lab/internal/problem"
 	"project_lab/internal/repositories"
-	"strconv"
+	"project_lab/internal/services"
+	"strings"
 	"time"
 )
 
 type TravelGroupHandler struct {
-	repo repositories.TravelGroupRepository
+	repo                 repositories.TravelGroupRepository
+	rates                services.ExchangeRateService
+	users                repositories.UserRepository
+	settlements          services.SettlementService
+	budgets              services.BudgetService
+	attachments          services.AttachmentService
+	requireVerifiedEmail bool
 }
 
-func NewTravelGroupHandler(repo repositories.TravelGroupRepository) *TravelGroupHandler {
-	return &TravelGroupHandler{repo: repo}
-}
-
-// checkGroupMembership é uma função auxiliar interna para verificar a autorização (Mitigação A01).
-// Ela reutiliza o GetGroupDetails para garantir que o usuário é membro.
-func (h *TravelGroupHandler) checkGroupMembership(w http.ResponseWriter, r *http.Request, groupID int) (userID int, ok bool) {
-	userIDValue := r.Context().Value(middleware.UserIDKey)
-	userID, ok = userIDValue.(int)
-	if !ok {
-		http.Error(w, "Não autorizado. ID do usuário não encontrado.", http.StatusUnauthorized)
-		return 0, false
-	}
-
-	// MITIGAÇÃO A01: Verifica se o usuário tem permissão para acessar este groupID
-	_, err := h.repo.GetGroupDetails(groupID, userID)
-	if err != nil {
-		// Se GetGroupDetails falhar, o usuário não é membro ou o grupo não existe.
-		http.Error(w, "Grupo não encontrado ou não autorizado", http.StatusNotFound)
-		return userID, false
-	}
-
-	return userID, true
+// NewTravelGroupHandler cria o handler de grupos. Com requireVerifiedEmail,
+// apenas usuários com e-mail confirmado podem criar grupos. O serviço de
+// acertos é usado para impedir a saída de quem ainda tem saldo pendente, e o
+// de orçamento, para avisar quando uma despesa estoura uma categoria; o de
+// comprovantes apaga os arquivos das despesas removidas.
+func NewTravelGroupHandler(repo repositories.TravelGroupRepository, rates services.ExchangeRateService, users repositories.UserRepository, settlements services.SettlementService, budgets services.BudgetService, attachments services.AttachmentService, requireVerifiedEmail bool) *TravelGroupHandler {
+	return &TravelGroupHandler{repo: repo, rates: rates, users: users, settlements: settlements, budgets: budgets, attachments: attachments, requireVerifiedEmail: requireVerifiedEmail}
 }
 
 func (h *TravelGroupHandler) CreateGroupHandler(w http.ResponseWriter, r *http.Request) {
 
 	var req models.TravelGroupCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Requisição inválida ou formato JSON incorreto.", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
@@ -52,44 +44,67 @@ func (h *TravelGroupHandler) CreateGroupHandler(w http.ResponseWriter, r *http.R
 
 	startDate, err := time.Parse(layout, req.StartDate)
 	if err != nil {
-		http.Error(w, "Formato de data de início inválido. Use YYYY-MM-DD.", http.StatusUnprocessableEntity)
+		problem.Error(w, r, http.StatusUnprocessableEntity, "group.invalid_start_date")
 		return
 	}
 
 	endDate, err := time.Parse(layout, req.EndDate)
 	if err != nil {
-		http.Error(w, "Formato de data de término inválido. Use YYYY-MM-DD.", http.StatusUnprocessableEntity)
+		problem.Error(w, r, http.StatusUnprocessableEntity, "group.invalid_end_date")
 		return
 	}
 
 	if req.Name == "" || req.StartDate == "" || req.EndDate == "" {
-		http.Error(w, "Nome, data de início e data de término são obrigatórios.", http.StatusUnprocessableEntity)
+		problem.Error(w, r, http.StatusUnprocessableEntity, "group.required_fields")
 		return
 	}
 
 	if startDate.After(endDate) {
-		http.Error(w, "A data de início deve ser anterior ou igual à data de término.", http.StatusUnprocessableEntity)
+		problem.Error(w, r, http.StatusUnprocessableEntity, "group.dates_order")
+		return
+	}
+
+	baseCurrency := strings.ToUpper(strings.TrimSpace(req.BaseCurrency))
+	if baseCurrency == "" {
+		baseCurrency = models.DefaultCurrency
+	}
+	if !models.IsValidCurrency(baseCurrency) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "group.invalid_base_currency")
 		return
 	}
 
 	userIDValue := r.Context().Value(middleware.UserIDKey)
 	creatorID, ok := userIDValue.(int)
 	if !ok {
-		http.Error(w, "Não autorizado. ID do usuário não encontrado.", http.StatusUnauthorized)
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
 		return
 	}
 
+	if h.requireVerifiedEmail {
+		creator, err := h.users.FindByID(r.Context(), creatorID)
+		if err != nil {
+			fmt.Printf("Erro ao buscar usuário no BD: %v\n", err)
+			writeServerError(w, r, err, "internal.get_user")
+			return
+		}
+		if creator.EmailVerifiedAt == nil {
+			problem.Write(w, problem.Localized(r, http.StatusForbidden, problem.CodeEmailNotVerified, "auth.email_not_verified"))
+			return
+		}
+	}
+
 	group := models.TravelGroup{
-		Name:        req.Name,
-		Description: req.Description,
-		StartDate:   startDate,
-		EndDate:     endDate,
-		CreatorID:   creatorID,
+		Name:         req.Name,
+		Description:  req.Description,
+		BaseCurrency: baseCurrency,
+		StartDate:    startDate,
+		EndDate:      endDate,
+		CreatorID:    creatorID,
 	}
 
-	if err := h.repo.CreateTravelGroup(&group); err != nil {
+	if err := h.repo.CreateTravelGroup(r.Context(), &group); err != nil {
 		fmt.Printf("Erro ao criar grupo no BD: %v\n", err)
-		http.Error(w, "Erro interno ao salvar grupo de viagem.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.create_group")
 		return
 	}
 
@@ -97,7 +112,7 @@ func (h *TravelGroupHandler) CreateGroupHandler(w http.ResponseWriter, r *http.R
 	w.WriteHeader(http.StatusCreated)
 
 	if err := json.NewEncoder(w).Encode(group); err != nil {
-		http.Error(w, "Erro ao serializar resposta.", http.StatusInternalServerError)
+		problem.Error(w, r, http.StatusInternalServerError, "internal.encode_response")
 		return
 	}
 }
@@ -108,94 +123,61 @@ func (h *TravelGroupHandler) ListGroups(w http.ResponseWriter, r *http.Request)
 
 	userID, ok := userIdValue.(int)
 	if !ok {
-		http.Error(w, "Falha na autenticação. ID de usuário não disponível.", http.StatusInternalServerError)
+		problem.Error(w, r, http.StatusInternalServerError, "auth.missing_user_internal")
 		return
 	}
 
-	groups, err := h.repo.ListGroupsByUserId(userID)
+	groups, err := h.repo.ListGroupsByUserId(r.Context(), userID)
 	if err != nil {
 		fmt.Printf("Erro ao buscar grupos para userID %d: %v\n", userID, err)
-		http.Error(w, "Erro interno ao buscar grupos de viagem.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.list_groups")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	if err := json.NewEncoder(w).Encode(groups); err != nil {
-		http.Error(w, "Erro ao serializar resposta JSON.", http.StatusInternalServerError)
+		problem.Error(w, r, http.StatusInternalServerError, "internal.encode_response")
 		return
 	}
 }
 
 // GetGroupDetailsWithID (MITIGADO)
-func (h *TravelGroupHandler) GetGroupDetailsWithID(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido. Deve ser um número.", http.StatusBadRequest)
-		return
-	}
+func (h *TravelGroupHandler) GetGroupDetailsWithID(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// MITIGAÇÃO A01: Verifica se o usuário é membro ANTES de buscar detalhes.
-	userID, ok := h.checkGroupMembership(w, r, groupID)
+	details, _, ok := authorizeGroup(w, r, h.repo, groupID, services.PermView)
 	if !ok {
 		return // O erro já foi enviado pela função auxiliar
 	}
 
-	details, err := h.repo.GetGroupDetails(groupID, userID)
-	if err != nil {
-		// Este erro não deve ocorrer se o checkGroupMembership passou, mas é uma boa defesa.
-		fmt.Printf("Erro ao buscar detalhes do grupo %d: %v\n", groupID, err)
-		http.Error(w, "Erro interno ao buscar detalhes do grupo.", http.StatusInternalServerError)
-		return
-	}
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(details)
 }
 
 // ListGroupMembersHandler (MITIGADO)
-func (h *TravelGroupHandler) ListGroupMembersHandler(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido.", http.StatusBadRequest)
-		return
-	}
+// Com ?former=true, a lista inclui os ex-membros (com leftAt).
+func (h *TravelGroupHandler) ListGroupMembersHandler(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// MITIGAÇÃO A01 (IDOR): Verifica se o usuário é membro ANTES de listar.
-	if _, ok := h.checkGroupMembership(w, r, groupID); !ok {
+	if _, _, ok := authorizeGroup(w, r, h.repo, groupID, services.PermView); !ok {
 		return // Bloqueia se não for membro
 	}
 
-	members, err := h.repo.ListGroupMembers(groupID)
-	if err != nil {
-		fmt.Printf("Erro ao buscar lista de membros do grupo %d: %v\n", groupID, err)
-		http.Error(w, "Erro interno ao buscar membros do grupo.", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(members)
+	h.writeMembers(w, r, groupID, r.URL.Query().Get("former") == "true")
 }
 
 // ListGroupDestinationsHandler (MITIGADO)
-func (h *TravelGroupHandler) ListGroupDestinationsHandler(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido.", http.StatusBadRequest)
-		return
-	}
+func (h *TravelGroupHandler) ListGroupDestinationsHandler(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// MITIGAÇÃO A01 (IDOR): Verifica se o usuário é membro ANTES de listar.
-	if _, ok := h.checkGroupMembership(w, r, groupID); !ok {
+	if _, _, ok := authorizeGroup(w, r, h.repo, groupID, services.PermView); !ok {
 		return // Bloqueia se não for membro
 	}
 
-	destinations, err := h.repo.ListGroupDestinations(groupID)
+	destinations, err := h.repo.ListGroupDestinations(r.Context(), groupID)
 	if err != nil {
 		fmt.Printf("Erro ao buscar lista de destinos do grupo %d: %v\n", groupID, err)
-		http.Error(w, "Erro interno ao buscar destinos do grupo.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.list_destinations")
 		return
 	}
 
@@ -204,24 +186,18 @@ func (h *TravelGroupHandler) ListGroupDestinationsHandler(w http.ResponseWriter,
 }
 
 // ListGroupVotingsHandler (MITIGADO)
-func (h *TravelGroupHandler) ListGroupVotingsHandler(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido.", http.StatusBadRequest)
-		return
-	}
+func (h *TravelGroupHandler) ListGroupVotingsHandler(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// MITIGAÇÃO A01 (IDOR): Verifica se o usuário é membro ANTES de listar.
-	userID, ok := h.checkGroupMembership(w, r, groupID)
+	_, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermView)
 	if !ok {
 		return // Bloqueia se não for membro
 	}
 
-	votings, err := h.repo.ListGroupVotings(groupID, userID)
+	votings, err := h.repo.ListGroupVotings(r.Context(), groupID, userID)
 	if err != nil {
 		fmt.Printf("Erro ao buscar votações do grupo %d: %v\n", groupID, err)
-		http.Error(w, "Erro interno ao buscar votações do grupo.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.list_votings")
 		return
 	}
 
@@ -230,23 +206,31 @@ func (h *TravelGroupHandler) ListGroupVotingsHandler(w http.ResponseWriter, r *h
 }
 
 // ListGroupExpensesHandler (MITIGADO)
-func (h *TravelGroupHandler) ListGroupExpensesHandler(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido.", http.StatusBadRequest)
-		return
-	}
+func (h *TravelGroupHandler) ListGroupExpensesHandler(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// MITIGAÇÃO A01 (IDOR): Verifica se o usuário é membro ANTES de listar.
-	if _, ok := h.checkGroupMembership(w, r, groupID); !ok {
+	if _, _, ok := authorizeGroup(w, r, h.repo, groupID, services.PermView); !ok {
 		return // Bloqueia se não for membro
 	}
 
-	expenses, err := h.repo.ListGroupExpenses(groupID)
+	expenses, err := h.repo.ListGroupExpenses(r.Context(), groupID)
 	if err != nil {
 		fmt.Printf("Erro ao buscar despesas do grupo %d: %v\n", groupID, err)
-		http.Error(w, "Erro interno ao buscar despesas do grupo.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.list_expenses")
+		return
+	}
+
+	baseCurrency, err := h.repo.GetGroupBaseCurrency(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar moeda base do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.list_expenses")
+		return
+	}
+
+	// Converte cada despesa para a moeda base usando a tabela local de câmbio.
+	if err := h.rates.ConvertExpenses(r.Context(), expenses, baseCurrency); err != nil {
+		fmt.Printf("Erro ao converter despesas do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.convert_expenses")
 		return
 	}
 
@@ -255,27 +239,22 @@ func (h *TravelGroupHandler) ListGroupExpensesHandler(w http.ResponseWriter, r *
 }
 
 // CreateDestinationHandler (MITIGADO)
-func (h *TravelGroupHandler) CreateDestinationHandler(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido.", http.StatusBadRequest)
-		return
-	}
+func (h *TravelGroupHandler) CreateDestinationHandler(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// MITIGAÇÃO A01 (Criação Arbitrária): Verifica se o usuário é membro ANTES de criar.
-	if _, ok := h.checkGroupMembership(w, r, groupID); !ok {
+	_, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermContribute)
+	if !ok {
 		return // Bloqueia se não for membro
 	}
 
 	var req models.DestinationCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Requisição inválida (JSON).", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
 	if req.Name == "" {
-		http.Error(w, "O nome do destino é obrigatório.", http.StatusUnprocessableEntity)
+		problem.Error(w, r, http.StatusUnprocessableEntity, "destination.name_required")
 		return
 	}
 
@@ -284,11 +263,12 @@ func (h *TravelGroupHandler) CreateDestinationHandler(w http.ResponseWriter, r *
 		Name:          req.Name,
 		Location:      req.Location,
 		Description:   req.Description,
+		CreatedBy:     &userID,
 	}
 
-	if err := h.repo.CreateDestination(&destination); err != nil {
+	if err := h.repo.CreateDestination(r.Context(), &destination); err != nil {
 		fmt.Printf("Erro ao criar destino no BD: %v\n", err)
-		http.Error(w, "Erro interno ao salvar destino.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.create_destination")
 		return
 	}
 
@@ -298,73 +278,58 @@ func (h *TravelGroupHandler) CreateDestinationHandler(w http.ResponseWriter, r *
 }
 
 // CreateVotingHandler (MITIGADO)
-func (h *TravelGroupHandler) CreateVotingHandler(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido.", http.StatusBadRequest)
-		return
-	}
+func (h *TravelGroupHandler) CreateVotingHandler(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// MITIGAÇÃO A01 (Criação Arbitrária): Verifica se o usuário é membro ANTES de criar.
-	if _, ok := h.checkGroupMembership(w, r, groupID); !ok {
+	_, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermContribute)
+	if !ok {
 		return // Bloqueia se não for membro
 	}
 
 	var req models.VotingCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Requisição inválida (JSON).", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
-	if req.Question == "" || len(req.Options) < 2 {
-		http.Error(w, "A pergunta e pelo menos 2 opções são obrigatórias.", http.StatusUnprocessableEntity)
-		return
+	voting := models.Voting{
+		TravelGroupID: groupID,
+		Question:      req.Question,
+		Options:       req.Options,
+		Type:          req.Type,
+		MaxSelections: req.MaxSelections,
+		CreatedBy:     &userID,
+		ClosesAt:      req.ClosesAt,
 	}
 
-	optionsJSON, err := json.Marshal(req.Options)
-	if err != nil {
-		http.Error(w, "Erro ao processar opções da votação.", http.StatusInternalServerError)
+	if err := services.ValidateVoting(&voting, time.Now()); err != nil {
+		problem.Write(w, problem.New(http.StatusUnprocessableEntity, problem.CodeInvalidVoting, localizeError(r, err, "error.invalid_voting")))
 		return
 	}
 
-	newVotingID, err := h.repo.CreateVoting(groupID, req.Question, string(optionsJSON))
-	if err != nil {
+	if err := h.repo.CreateVoting(r.Context(), &voting); err != nil {
 		fmt.Printf("Erro ao criar votação no BD: %v\n", err)
-		http.Error(w, "Erro interno ao salvar votação.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.create_voting")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
-	json.NewEncoder(w).Encode(map[string]int{"id": newVotingID})
+	json.NewEncoder(w).Encode(map[string]int{"id": voting.ID})
 }
 
-func (h *TravelGroupHandler) CreateExpenseHandler(w http.ResponseWriter, r *http.Request, groupIDStr string) {
-
-	groupID, err := strconv.Atoi(groupIDStr)
-	if err != nil {
-		http.Error(w, "ID do grupo inválido.", http.StatusBadRequest)
-		return
-	}
+func (h *TravelGroupHandler) CreateExpenseHandler(w http.ResponseWriter, r *http.Request, groupID int) {
 
 	// 1. MITIGAÇÃO A01 (Check de Membro): Verifica se o usuário é membro do grupo.
 	// O userID é o ID do usuário AUTENTICADO (do token).
-	userID, ok := h.checkGroupMembership(w, r, groupID)
+	_, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermContribute)
 	if !ok {
 		return // Bloqueia se não for membro (IDOR)
 	}
 
 	var req models.ExpenseCreateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Requisição inválida (JSON).", http.StatusBadRequest)
-		return
-	}
-
-	// Validações básicas
-	if req.Description == "" || req.Amount <= 0 || len(req.ParticipantIDs) == 0 {
-		// Removemos a checagem de req.PayerID <= 0, pois não usaremos o PayerID do JSON.
-		http.Error(w, "Descrição, valor (positivo) e lista de participantes são obrigatórios.", http.StatusUnprocessableEntity)
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
@@ -372,20 +337,445 @@ func (h *TravelGroupHandler) CreateExpenseHandler(w http.ResponseWriter, r *http
 	// O PayerID da despesa AGORA usa o ID do usuário AUTENTICADO (userID)
 	// em vez de confiar no valor enviado no corpo da requisição (req.PayerID).
 	expense := models.Expense{
-		TravelGroupID:  groupID,
-		Description:    req.Description,
-		Amount:         req.Amount,
-		PayerID:        userID, // <-- CORRIGIDO! Usa o ID do token.
-		ParticipantIDs: req.ParticipantIDs,
+		TravelGroupID: groupID,
+		PayerID:       userID, // <-- CORRIGIDO! Usa o ID do token.
+		CreatedBy:     &userID,
+	}
+	if !h.applyExpenseRequest(w, r, req, &expense) {
+		return
 	}
 
-	if err := h.repo.CreateExpense(&expense); err != nil {
+	if err := h.repo.CreateExpense(r.Context(), &expense); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
 		fmt.Printf("Erro ao criar despesa no BD: %v\n", err)
-		http.Error(w, "Erro interno ao salvar despesa e participantes.", http.StatusInternalServerError)
+		writeServerError(w, r, err, "internal.create_expense_participants")
 		return
 	}
+	checkBudgetAlerts(r, h.budgets, groupID)
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(expense)
 }
+
+// applyExpenseRequest valida o payload (valor, moeda, divisão e participantes)
+// e preenche os campos correspondentes da despesa. Usado na criação e na alteração.
+func (h *TravelGroupHandler) applyExpenseRequest(w http.ResponseWriter, r *http.Request, req models.ExpenseCreateRequest, expense *models.Expense) bool {
+	groupID := expense.TravelGroupID
+
+	// Validações básicas
+	if req.Description == "" || req.Amount <= 0 {
+		// Removemos a checagem de req.PayerID <= 0, pois não usaremos o PayerID do JSON.
+		problem.Error(w, r, http.StatusUnprocessableEntity, "expense.required_fields")
+		return false
+	}
+	if req.Amount > models.MaxMoney {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "validation.amount_too_large", models.MaxMoney.String())
+		return false
+	}
+
+	baseCurrency, err := h.repo.GetGroupBaseCurrency(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar moeda base do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.create_expense")
+		return false
+	}
+
+	category := strings.TrimSpace(req.Category)
+	if category == "" {
+		category = models.CategoryOther
+	}
+	if !models.IsValidExpenseCategory(category) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "validation.expense_category_invalid", strings.Join(models.ExpenseCategories, ", "))
+		return false
+	}
+
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = baseCurrency
+	}
+	if !models.IsValidCurrency(currency) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "request.invalid_currency")
+		return false
+	}
+
+	// Só aceita moedas que possam ser convertidas para a moeda base do grupo,
+	// senão a despesa ficaria de fora dos saldos.
+	if _, err := h.rates.Convert(r.Context(), req.Amount, currency, baseCurrency, time.Now()); err != nil {
+		if writeDomainError(w, r, err) {
+			return false
+		}
+		fmt.Printf("Erro ao validar câmbio da despesa: %v\n", err)
+		writeServerError(w, r, err, "internal.validate_expense_currency")
+		return false
+	}
+
+	splitMode := req.SplitMode
+	if splitMode == "" {
+		splitMode = models.SplitModeEqual
+	}
+
+	// Calcula a parte de cada participante conforme o modo de divisão.
+	shares, err := services.SplitExpense(req.Amount, splitMode, req.ParticipantIDs, req.Splits)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return false
+		}
+		fmt.Printf("Erro ao calcular divisão da despesa: %v\n", err)
+		writeServerError(w, r, err, "internal.split_expense")
+		return false
+	}
+
+	participantIDs := make([]int, len(shares))
+	for i, share := range shares {
+		participantIDs[i] = share.UserID
+	}
+
+	// MITIGAÇÃO A01: todos os participantes do rateio precisam ser membros do grupo.
+	allMembers, err := h.repo.AreGroupMembers(r.Context(), groupID, participantIDs)
+	if err != nil {
+		fmt.Printf("Erro ao validar participantes da despesa: %v\n", err)
+		writeServerError(w, r, err, "internal.validate_participants")
+		return false
+	}
+	if !allMembers {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "expense.participants_not_members")
+		return false
+	}
+
+	expense.Description = req.Description
+	expense.Amount = req.Amount
+	expense.Currency = currency
+	expense.SplitMode = splitMode
+	expense.Category = category
+	expense.ParticipantIDs = participantIDs
+	expense.Shares = shares
+	return true
+}
+
+// UpdateGroupHandler lida com PATCH /groups/{id} (organizador e co-organizadores)
+func (h *TravelGroupHandler) UpdateGroupHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	details, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermEditGroup)
+	if !ok {
+		return
+	}
+
+	var req models.TravelGroupUpdateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	group := models.TravelGroup{
+		ID:           groupID,
+		Name:         details.Name,
+		CreatorID:    details.CreatorID,
+		Description:  details.Description,
+		BaseCurrency: details.BaseCurrency,
+		StartDate:    details.StartDate,
+		EndDate:      details.EndDate,
+	}
+
+	const layout = "2006-01-02"
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			problem.Error(w, r, http.StatusUnprocessableEntity, "group.name_required")
+			return
+		}
+		group.Name = *req.Name
+	}
+	if req.Description != nil {
+		group.Description = *req.Description
+	}
+	if req.StartDate != nil {
+		startDate, err := time.Parse(layout, *req.StartDate)
+		if err != nil {
+			problem.Error(w, r, http.StatusUnprocessableEntity, "group.invalid_start_date")
+			return
+		}
+		group.StartDate = startDate
+	}
+	if req.EndDate != nil {
+		endDate, err := time.Parse(layout, *req.EndDate)
+		if err != nil {
+			problem.Error(w, r, http.StatusUnprocessableEntity, "group.invalid_end_date")
+			return
+		}
+		group.EndDate = endDate
+	}
+	if group.StartDate.After(group.EndDate) {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "group.dates_order")
+		return
+	}
+
+	if req.BaseCurrency != nil {
+		baseCurrency := strings.ToUpper(strings.TrimSpace(*req.BaseCurrency))
+		if !models.IsValidCurrency(baseCurrency) {
+			problem.Error(w, r, http.StatusUnprocessableEntity, "group.invalid_base_currency")
+			return
+		}
+		if baseCurrency != group.BaseCurrency && !h.canConvertExpensesTo(w, r, groupID, baseCurrency) {
+			return
+		}
+		group.BaseCurrency = baseCurrency
+	}
+
+	if err := h.repo.UpdateTravelGroup(r.Context(), &group); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao alterar grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.update_group")
+		return
+	}
+
+	updated, err := h.repo.GetGroupDetails(r.Context(), groupID, userID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar grupo alterado %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.group_updated_reload")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(updated)
+}
+
+// canConvertExpensesTo garante que todas as moedas das despesas do grupo têm
+// taxa de câmbio para a nova moeda base; senão os saldos deixariam de fechar.
+func (h *TravelGroupHandler) canConvertExpensesTo(w http.ResponseWriter, r *http.Request, groupID int, baseCurrency string) bool {
+	expenses, err := h.repo.ListGroupExpenses(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar despesas do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.validate_base_currency")
+		return false
+	}
+
+	for _, e := range expenses {
+		if _, err := h.rates.Convert(r.Context(), e.Amount, e.Currency, baseCurrency, e.CreatedAt); err != nil {
+			if writeDomainError(w, r, err) {
+				return false
+			}
+			fmt.Printf("Erro ao validar câmbio da despesa %d: %v\n", e.ID, err)
+			writeServerError(w, r, err, "internal.validate_base_currency")
+			return false
+		}
+	}
+	return true
+}
+
+// DeleteGroupHandler lida com DELETE /groups/{id} (apenas o organizador).
+// Todos os dados do grupo são apagados em cascata.
+func (h *TravelGroupHandler) DeleteGroupHandler(w http.ResponseWriter, r *http.Request, groupID int) {
+
+	if _, _, ok := authorizeGroup(w, r, h.repo, groupID, services.PermDeleteGroup); !ok {
+		return
+	}
+
+	// Os registros dos comprovantes saem com o grupo (ON DELETE CASCADE); os
+	// arquivos são apagados depois, pela lista lida antes.
+	attachments, err := h.attachments.ListByGroup(r.Context(), groupID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar comprovantes do grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.delete_group")
+		return
+	}
+
+	if err := h.repo.DeleteTravelGroup(r.Context(), groupID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao apagar grupo %d: %v\n", groupID, err)
+		writeServerError(w, r, err, "internal.delete_group")
+		return
+	}
+	if err := h.attachments.DeleteFiles(r.Context(), attachments); err != nil {
+		fmt.Printf("Erro ao apagar arquivos dos comprovantes do grupo %d: %v\n", groupID, err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadDestinationForManager busca o destino e garante que o usuário é o autor
+// do destino ou um organizador do grupo.
+func (h *TravelGroupHandler) loadDestinationForManager(w http.ResponseWriter, r *http.Request, groupID int, destinationID int) (*models.Destination, bool) {
+	group, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermContribute)
+	if !ok {
+		return nil, false
+	}
+
+	destination, err := h.repo.GetDestination(r.Context(), groupID, destinationID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return nil, false
+		}
+		fmt.Printf("Erro ao buscar destino %d: %v\n", destinationID, err)
+		writeServerError(w, r, err, "internal.get_destination")
+		return nil, false
+	}
+
+	if !canManage(group, userID, destination.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "destination.author_only")
+		return nil, false
+	}
+
+	return destination, true
+}
+
+// UpdateDestinationHandler lida com PUT /groups/{id}/destinations/{destinationId}
+func (h *TravelGroupHandler) UpdateDestinationHandler(w http.ResponseWriter, r *http.Request, groupID int, destinationID int) {
+
+	destination, ok := h.loadDestinationForManager(w, r, groupID, destinationID)
+	if !ok {
+		return
+	}
+
+	var req models.DestinationCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	if req.Name == "" {
+		problem.Error(w, r, http.StatusUnprocessableEntity, "destination.name_required")
+		return
+	}
+
+	destination.Name = req.Name
+	destination.Location = req.Location
+	destination.Description = req.Description
+
+	if err := h.repo.UpdateDestination(r.Context(), destination); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao alterar destino %d: %v\n", destination.ID, err)
+		writeServerError(w, r, err, "internal.update_destination")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(models.DestinationDTO{
+		ID:          destination.ID,
+		Name:        destination.Name,
+		Location:    destination.Location,
+		Description: destination.Description,
+		CreatedBy:   destination.CreatedBy,
+	})
+}
+
+// DeleteDestinationHandler lida com DELETE /groups/{id}/destinations/{destinationId}.
+// Itens do roteiro ligados ao destino são mantidos, sem destino.
+func (h *TravelGroupHandler) DeleteDestinationHandler(w http.ResponseWriter, r *http.Request, groupID int, destinationID int) {
+
+	destination, ok := h.loadDestinationForManager(w, r, groupID, destinationID)
+	if !ok {
+		return
+	}
+
+	if err := h.repo.DeleteDestination(r.Context(), destination.TravelGroupID, destination.ID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao apagar destino %d: %v\n", destination.ID, err)
+		writeServerError(w, r, err, "internal.delete_destination")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadExpenseForManager busca a despesa e garante que o usuário é quem a lançou
+// ou um organizador do grupo.
+func (h *TravelGroupHandler) loadExpenseForManager(w http.ResponseWriter, r *http.Request, groupID int, expenseID int) (*models.Expense, bool) {
+	group, userID, ok := authorizeGroup(w, r, h.repo, groupID, services.PermContribute)
+	if !ok {
+		return nil, false
+	}
+
+	expense, err := h.repo.GetExpense(r.Context(), groupID, expenseID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return nil, false
+		}
+		fmt.Printf("Erro ao buscar despesa %d: %v\n", expenseID, err)
+		writeServerError(w, r, err, "internal.get_expense")
+		return nil, false
+	}
+
+	if !canManage(group, userID, expense.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "expense.author_only")
+		return nil, false
+	}
+
+	return expense, true
+}
+
+// UpdateExpenseHandler lida com PUT /groups/{id}/expenses/{expenseId}.
+// Recalcula a divisão; o pagador continua sendo quem lançou a despesa.
+func (h *TravelGroupHandler) UpdateExpenseHandler(w http.ResponseWriter, r *http.Request, groupID int, expenseID int) {
+
+	expense, ok := h.loadExpenseForManager(w, r, groupID, expenseID)
+	if !ok {
+		return
+	}
+
+	var req models.ExpenseCreateRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return
+	}
+
+	if !h.applyExpenseRequest(w, r, req, expense) {
+		return
+	}
+
+	if err := h.repo.UpdateExpense(r.Context(), expense); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao alterar despesa %d: %v\n", expense.ID, err)
+		writeServerError(w, r, err, "internal.update_expense")
+		return
+	}
+	checkBudgetAlerts(r, h.budgets, groupID)
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(expense)
+}
+
+// DeleteExpenseHandler lida com DELETE /groups/{id}/expenses/{expenseId}
+func (h *TravelGroupHandler) DeleteExpenseHandler(w http.ResponseWriter, r *http.Request, groupID int, expenseID int) {
+
+	expense, ok := h.loadExpenseForManager(w, r, groupID, expenseID)
+	if !ok {
+		return
+	}
+
+	// Os registros dos comprovantes saem com a despesa (ON DELETE CASCADE); os
+	// arquivos são apagados depois, pela lista lida antes.
+	attachments, err := h.attachments.List(r.Context(), groupID, expense.ID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar comprovantes da despesa %d: %v\n", expense.ID, err)
+		writeServerError(w, r, err, "internal.delete_expense")
+		return
+	}
+
+	if err := h.repo.DeleteExpense(r.Context(), expense.TravelGroupID, expense.ID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao apagar despesa %d: %v\n", expense.ID, err)
+		writeServerError(w, r, err, "internal.delete_expense")
+		return
+	}
+	if err := h.attachments.DeleteFiles(r.Context(), attachments); err != nil {
+		fmt.Printf("Erro ao apagar arquivos dos comprovantes da despesa %d: %v\n", expense.ID, err)
+	}
+	checkBudgetAlerts(r, h.budgets, groupID)
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
+	"project_lab/internal/i18n"
 	"project_lab/internal/middleware"
 	"project_lab/internal/models"
+	"project_lab/internal/problem"
 	"project_lab/internal/repositories"
-	"strconv"
+	"project_lab/internal/services"
+	"slices"
+	"time"
 )
 
 type VoteHandler struct {
@@ -19,86 +24,313 @@ func NewVoteHandler(voteRepo repositories.VoteRepository, groupRepo repositories
 	return &VoteHandler{voteRepo: voteRepo, groupRepo: groupRepo}
 }
 
-// VoteHandler lida com o registro de um voto
-func (h *VoteHandler) VoteHandler(w http.ResponseWriter, r *http.Request, votingIDStr string) {
+// VoteHandler lida com o registro de um voto (POST /votings/{id}/vote)
+func (h *VoteHandler) VoteHandler(w http.ResponseWriter, r *http.Request, votingID int) {
+	voting, userID, ok := h.loadOpenVotingForMember(w, r, votingID)
+	if !ok {
+		return
+	}
 
-	votingID, err := strconv.Atoi(votingIDStr)
-	if err != nil {
-		http.Error(w, "ID da votação inválido.", http.StatusBadRequest)
+	selections, ok := decodeBallot(w, r, voting)
+	if !ok {
+		return
+	}
+
+	vote := models.Vote{
+		VotingID:        voting.ID,
+		UserID:          userID,
+		SelectedOption:  selections[0],
+		SelectedOptions: selections,
+	}
+
+	// A restrição única (voting_id, user_id) garante um voto por usuário,
+	// mesmo com requisições concorrentes.
+	if err := h.voteRepo.CastVote(r.Context(), &vote); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao registrar voto: %v\n", err)
+		writeServerError(w, r, err, "internal.cast_vote")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]string{"message": i18n.T(i18n.FromContext(r.Context()), "vote.cast")})
+}
+
+// ChangeVoteHandler lida com a troca de um voto já registrado (PUT /votings/{id}/vote)
+func (h *VoteHandler) ChangeVoteHandler(w http.ResponseWriter, r *http.Request, votingID int) {
+	voting, userID, ok := h.loadOpenVotingForMember(w, r, votingID)
+	if !ok {
+		return
+	}
+
+	selections, ok := decodeBallot(w, r, voting)
+	if !ok {
+		return
+	}
+
+	vote := models.Vote{
+		VotingID:        voting.ID,
+		UserID:          userID,
+		SelectedOption:  selections[0],
+		SelectedOptions: selections,
+	}
+
+	if err := h.voteRepo.UpdateVote(r.Context(), &vote); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao alterar voto: %v\n", err)
+		writeServerError(w, r, err, "internal.update_vote")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{"message": i18n.T(i18n.FromContext(r.Context()), "vote.updated")})
+}
+
+// RetractVoteHandler lida com a retirada de um voto (DELETE /votings/{id}/vote)
+func (h *VoteHandler) RetractVoteHandler(w http.ResponseWriter, r *http.Request, votingID int) {
+	voting, userID, ok := h.loadOpenVotingForMember(w, r, votingID)
+	if !ok {
+		return
+	}
+
+	if err := h.voteRepo.DeleteVote(r.Context(), voting.ID, userID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao retirar voto: %v\n", err)
+		writeServerError(w, r, err, "internal.delete_vote")
 		return
 	}
 
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// loadOpenVotingForMember aplica as regras comuns para votar: membro do grupo
+// com papel que permite contribuir e votação ainda aberta.
+func (h *VoteHandler) loadOpenVotingForMember(w http.ResponseWriter, r *http.Request, votingID int) (*models.Voting, int, bool) {
+	voting, group, userID, ok := h.loadVotingForMember(w, r, votingID)
+	if !ok {
+		return nil, userID, false
+	}
+
+	if !services.RoleCan(group.Role, services.PermContribute) {
+		problem.Write(w, problem.Localized(r, http.StatusForbidden, problem.CodeReadOnlyMember, "group.read_only"))
+		return nil, userID, false
+	}
+
+	if voting.Status(time.Now()) == models.VotingStatusClosed {
+		problem.Write(w, problem.Localized(r, http.StatusConflict, problem.CodeVotingClosed, "voting.closed"))
+		return nil, userID, false
+	}
+
+	return voting, userID, true
+}
+
+// decodeBallot lê o corpo da requisição e valida as opções conforme o tipo da votação.
+func decodeBallot(w http.ResponseWriter, r *http.Request, voting *models.Voting) ([]string, bool) {
+	var req models.VoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
+		return nil, false
+	}
+
+	selections, err := services.NormalizeBallot(voting, req)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return nil, false
+		}
+		fmt.Printf("Erro ao validar voto: %v\n", err)
+		writeServerError(w, r, err, "internal.validate_vote")
+		return nil, false
+	}
+
+	return selections, true
+}
+
+// loadVotingForMember busca a votação e garante que o usuário autenticado é membro
+// do grupo ao qual ela pertence. Retorna também os detalhes do grupo.
+func (h *VoteHandler) loadVotingForMember(w http.ResponseWriter, r *http.Request, votingID int) (*models.Voting, *models.TravelGroupDetails, int, bool) {
 	userIDValue := r.Context().Value(middleware.UserIDKey)
 	userID, ok := userIDValue.(int)
 	if !ok {
-		http.Error(w, "Não autorizado. ID do usuário não encontrado.", http.StatusUnauthorized)
+		problem.Error(w, r, http.StatusUnauthorized, "auth.missing_user")
+		return nil, nil, 0, false
+	}
+
+	voting, err := h.voteRepo.GetVoting(r.Context(), votingID)
+	if err != nil {
+		if writeDomainError(w, r, err) {
+			return nil, nil, userID, false
+		}
+		fmt.Printf("Erro ao buscar votação %d: %v\n", votingID, err)
+		writeServerError(w, r, err, "internal.get_voting")
+		return nil, nil, userID, false
+	}
+
+	// MITIGAÇÃO A01 (IDOR): só membros do grupo enxergam a votação.
+	group, err := h.groupRepo.GetGroupDetails(r.Context(), voting.TravelGroupID, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrGroupNotFound) {
+			problem.Write(w, problem.Localized(r, http.StatusNotFound, problem.CodeVotingNotFound, "voting.not_found"))
+			return nil, nil, userID, false
+		}
+		fmt.Printf("Erro ao buscar grupo %d da votação %d: %v\n", voting.TravelGroupID, votingID, err)
+		writeServerError(w, r, err, "internal.get_group")
+		return nil, nil, userID, false
+	}
+
+	return voting, group, userID, true
+}
+
+// CloseVotingHandler lida com POST /votings/{id}/close (autor da votação ou organizadores)
+func (h *VoteHandler) CloseVotingHandler(w http.ResponseWriter, r *http.Request, votingID int) {
+	voting, group, userID, ok := h.loadVotingForMember(w, r, votingID)
+	if !ok {
 		return
 	}
 
-	var req models.VoteRequest
+	if !canManage(group, userID, voting.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "voting.author_only_close")
+		return
+	}
+
+	if voting.Status(time.Now()) == models.VotingStatusClosed {
+		problem.Write(w, problem.Localized(r, http.StatusConflict, problem.CodeVotingClosed, "voting.already_closed"))
+		return
+	}
+
+	if err := h.voteRepo.CloseVoting(r.Context(), voting.ID); err != nil {
+		fmt.Printf("Erro ao encerrar votação %d: %v\n", voting.ID, err)
+		writeServerError(w, r, err, "internal.close_voting")
+		return
+	}
+
+	h.writeResults(w, r, voting.ID)
+}
+
+// UpdateVotingHandler lida com PATCH /votings/{id} (autor da votação ou organizadores).
+// Pergunta e prazo podem mudar enquanto a votação estiver aberta; opções, tipo
+// e limite de escolhas só enquanto ninguém votou.
+func (h *VoteHandler) UpdateVotingHandler(w http.ResponseWriter, r *http.Request, votingID int) {
+	voting, group, userID, ok := h.loadVotingForMember(w, r, votingID)
+	if !ok {
+		return
+	}
+
+	if !canManage(group, userID, voting.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "voting.author_only_update")
+		return
+	}
+
+	if voting.Status(time.Now()) == models.VotingStatusClosed {
+		problem.Write(w, problem.Localized(r, http.StatusConflict, problem.CodeVotingClosed, "voting.closed"))
+		return
+	}
+
+	var req models.VotingUpdateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Requisição inválida (JSON).", http.StatusBadRequest)
+		problem.Error(w, r, http.StatusBadRequest, "request.invalid_json")
 		return
 	}
 
-	if req.SelectedOption == "" {
-		http.Error(w, "Opção de voto é obrigatória.", http.StatusUnprocessableEntity)
+	structural := false
+	if req.Question != nil {
+		voting.Question = *req.Question
+	}
+	if req.Options != nil {
+		structural = structural || !slices.Equal(req.Options, voting.Options)
+		voting.Options = req.Options
+	}
+	if req.Type != nil && *req.Type != voting.Type {
+		structural = true
+		voting.Type = *req.Type
+	}
+	if req.MaxSelections != nil && (voting.MaxSelections == nil || *req.MaxSelections != *voting.MaxSelections) {
+		structural = true
+		voting.MaxSelections = req.MaxSelections
+	}
+	if req.ClearClosesAt {
+		voting.ClosesAt = nil
+	} else if req.ClosesAt != nil {
+		voting.ClosesAt = req.ClosesAt
+	}
+
+	if err := services.ValidateVoting(voting, time.Now()); err != nil {
+		problem.Write(w, problem.New(http.StatusUnprocessableEntity, problem.CodeInvalidVoting, localizeError(r, err, "error.invalid_voting")))
 		return
 	}
 
-	//  Lógica de Validação e Registro
-	validOptions, err := h.voteRepo.GetVotingOptions(votingID)
-	if err != nil {
-		if err.Error() == "votação não encontrada" {
-			http.Error(w, "Votação não encontrada.", http.StatusNotFound)
+	if err := h.voteRepo.UpdateVoting(r.Context(), voting, structural); err != nil {
+		if writeDomainError(w, r, err) {
 			return
 		}
-		fmt.Printf("Erro ao buscar opções da votação %d: %v\n", votingID, err)
-		http.Error(w, "Erro interno de validação.", http.StatusInternalServerError)
+		fmt.Printf("Erro ao alterar votação %d: %v\n", voting.ID, err)
+		writeServerError(w, r, err, "internal.update_voting")
 		return
 	}
 
-	isValidOption := false
-	for _, opt := range validOptions {
-		if opt == req.SelectedOption {
-			isValidOption = true
-			break
-		}
+	h.writeResults(w, r, voting.ID)
+}
+
+// DeleteVotingHandler lida com DELETE /votings/{id} (autor da votação ou organizadores).
+// Os votos são apagados em cascata.
+func (h *VoteHandler) DeleteVotingHandler(w http.ResponseWriter, r *http.Request, votingID int) {
+	voting, group, userID, ok := h.loadVotingForMember(w, r, votingID)
+	if !ok {
+		return
 	}
-	if !isValidOption {
-		http.Error(w, "Opção de voto inválida para esta votação.", http.StatusUnprocessableEntity)
+
+	if !canManage(group, userID, voting.CreatedBy) {
+		problem.Error(w, r, http.StatusForbidden, "voting.author_only_delete")
 		return
 	}
 
-	//  Verificar se o usuário já votou
-	alreadyVoted, err := h.voteRepo.CheckUserVote(votingID, userID)
-	if err != nil {
-		fmt.Printf("Erro ao checar voto: %v\n", err)
-		http.Error(w, "Erro interno de checagem de voto.", http.StatusInternalServerError)
+	if err := h.voteRepo.DeleteVoting(r.Context(), voting.ID); err != nil {
+		if writeDomainError(w, r, err) {
+			return
+		}
+		fmt.Printf("Erro ao apagar votação %d: %v\n", voting.ID, err)
+		writeServerError(w, r, err, "internal.delete_voting")
 		return
 	}
 
-	if alreadyVoted {
-		// Se a regra é "só pode votar uma vez", retorne um erro.
-		http.Error(w, "Você já votou nesta enquete.", http.StatusConflict)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetVotingResultsHandler lida com GET /votings/{id}/results
+func (h *VoteHandler) GetVotingResultsHandler(w http.ResponseWriter, r *http.Request, votingID int) {
+	voting, _, _, ok := h.loadVotingForMember(w, r, votingID)
+	if !ok {
 		return
 	}
 
-	// Nota: Idealmente, você também deveria checar se o usuário (userID) é membro do grupo
-	// ao qual a votação pertence. Isso requer uma função extra no TravelGroupRepository.
+	h.writeResults(w, r, voting.ID)
+}
 
-	vote := models.Vote{
-		VotingID:       votingID,
-		UserID:         userID,
-		SelectedOption: req.SelectedOption,
+// writeResults apura a votação e escreve o resultado como JSON.
+func (h *VoteHandler) writeResults(w http.ResponseWriter, r *http.Request, votingID int) {
+	// Relê a votação para refletir um encerramento recém-feito.
+	voting, err := h.voteRepo.GetVoting(r.Context(), votingID)
+	if err != nil {
+		fmt.Printf("Erro ao buscar votação %d: %v\n", votingID, err)
+		writeServerError(w, r, err, "internal.tally_voting")
+		return
 	}
 
-	if err := h.voteRepo.CastVote(&vote); err != nil {
-		fmt.Printf("Erro ao registrar voto: %v\n", err)
-		http.Error(w, "Erro interno ao registrar voto.", http.StatusInternalServerError)
+	ballots, err := h.voteRepo.ListBallots(r.Context(), votingID)
+	if err != nil {
+		fmt.Printf("Erro ao apurar votação %d: %v\n", votingID, err)
+		writeServerError(w, r, err, "internal.tally_voting")
 		return
 	}
 
-	w.WriteHeader(http.StatusCreated)
-	w.Write([]byte(`{"message": "Voto registrado com sucesso."}`))
+	results := services.TallyVoting(voting, ballots, time.Now())
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(results)
 }
@@ -0,0 +1,169 @@
+// Package i18n traduz as mensagens exibidas aos usuários. Cada mensagem tem um
+// ID estável; os textos de cada idioma ficam em locales/<idioma>.json, no
+// formato de fmt.Sprintf quando recebem argumentos.
+package i18n
+
+import (
+	"context"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Locale é uma tag de idioma suportada pela API.
+type Locale string
+
+const (
+	PtBR    Locale = "pt-BR"
+	English Locale = "en"
+
+	// Default é usado quando o cliente não pede nenhum idioma suportado.
+	Default = PtBR
+)
+
+// Supported lista os idiomas com catálogo, na ordem de preferência da API.
+var Supported = []Locale{PtBR, English}
+
+//go:embed locales/*.json
+var files embed.FS
+
+var catalogs = mustLoad()
+
+// mustLoad lê os catálogos e exige que todo idioma traduza todas as mensagens
+// do idioma padrão, para que uma tradução esquecida falhe já na inicialização.
+func mustLoad() map[Locale]map[string]string {
+	loaded := make(map[Locale]map[string]string)
+	for _, locale := range Supported {
+		data, err := files.ReadFile(path.Join("locales", string(locale)+".json"))
+		if err != nil {
+			panic(fmt.Sprintf("i18n: catálogo %s ausente: %v", locale, err))
+		}
+		var messages map[string]string
+		if err := json.Unmarshal(data, &messages); err != nil {
+			panic(fmt.Sprintf("i18n: catálogo %s inválido: %v", locale, err))
+		}
+		loaded[locale] = messages
+	}
+
+	for _, locale := range Supported {
+		for id := range loaded[Default] {
+			if _, ok := loaded[locale][id]; !ok {
+				panic(fmt.Sprintf("i18n: mensagem %q sem tradução em %s", id, locale))
+			}
+		}
+	}
+	return loaded
+}
+
+// T traduz a mensagem para o idioma. Um ID sem texto no catálogo é devolvido
+// como está, para que o erro apareça sem derrubar a requisição.
+func T(locale Locale, id string, args ...any) string {
+	text, ok := catalogs[locale][id]
+	if !ok {
+		if text, ok = catalogs[Default][id]; !ok {
+			return id
+		}
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Parse reconhece uma tag de idioma, ignorando maiúsculas e a região quando
+// o idioma só tem uma variante ("en-US" vira "en"; "pt" e "pt-PT" viram "pt-BR").
+func Parse(tag string) (Locale, bool) {
+	tag = strings.TrimSpace(tag)
+	for _, locale := range Supported {
+		if strings.EqualFold(tag, string(locale)) {
+			return locale, true
+		}
+	}
+	language, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	switch language {
+	case "pt":
+		return PtBR, true
+	case "en":
+		return English, true
+	}
+	return "", false
+}
+
+// Negotiate escolhe o idioma pelo cabeçalho Accept-Language, respeitando os
+// pesos "q". Sem nenhum idioma suportado, devolve Default.
+func Negotiate(acceptLanguage string) Locale {
+	best, bestQ := Default, 0.0
+	for _, part := range strings.Split(acceptLanguage, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		locale, ok := Parse(tag)
+		if !ok || q <= bestQ {
+			continue
+		}
+		best, bestQ = locale, q
+	}
+	return best
+}
+
+type contextKey struct{}
+
+// WithLocale guarda o idioma da requisição no contexto.
+func WithLocale(ctx context.Context, locale Locale) context.Context {
+	return context.WithValue(ctx, contextKey{}, locale)
+}
+
+// FromContext devolve o idioma guardado por WithLocale, ou Default.
+func FromContext(ctx context.Context) Locale {
+	if locale, ok := ctx.Value(contextKey{}).(Locale); ok && slices.Contains(Supported, locale) {
+		return locale
+	}
+	return Default
+}
+
+// Message é uma mensagem ainda não traduzida: o texto final depende do idioma
+// de quem vai lê-la.
+type Message struct {
+	ID   string
+	Args []any
+}
+
+// M monta uma Message.
+func M(id string, args ...any) Message {
+	return Message{ID: id, Args: args}
+}
+
+// In traduz a mensagem para o idioma.
+func (m Message) In(locale Locale) string {
+	return T(locale, m.ID, m.Args...)
+}
+
+// Error acrescenta a um erro de domínio (o sentinela em Err) uma mensagem
+// traduzível com os detalhes do caso. Error() usa o idioma padrão, para logs.
+type Error struct {
+	Err     error
+	Message Message
+}
+
+// Errorf cria um Error sobre o sentinela.
+func Errorf(err error, id string, args ...any) error {
+	return &Error{Err: err, Message: M(id, args...)}
+}
+
+func (e *Error) Error() string {
+	return e.Err.Error() + ": " + e.Message.In(Default)
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,143 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+const (
+	clientIPKey     contextKey = "clientIP"
+	clientSchemeKey contextKey = "clientScheme"
+)
+
+// ParseTrustedProxies lê uma lista de CIDRs (ou IPs isolados) separada por vírgulas.
+func ParseTrustedProxies(spec string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("proxy confiável inválido: %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				bits = 32
+			}
+			entry = fmt.Sprintf("%s/%d", entry, bits)
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("proxy confiável inválido: %q", entry)
+		}
+		nets = append(nets, ipNet)
+	}
+	return nets, nil
+}
+
+// RealIP determina o IP do cliente e o guarda no contexto para ClientIP. O
+// X-Forwarded-For só é considerado quando a conexão vem de um proxy confiável:
+// a lista é percorrida da direita para a esquerda, pulando os proxies
+// confiáveis, e o primeiro endereço restante é o do cliente. Entradas mais à
+// esquerda podem ter sido forjadas pelo próprio cliente e são ignoradas. Da
+// mesma forma, o X-Forwarded-Proto só vale para Scheme quando vem de um proxy
+// confiável.
+func RealIP(trusted []*net.IPNet) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), clientIPKey, resolveClientIP(r, trusted))
+			ctx = context.WithValue(ctx, clientSchemeKey, resolveScheme(r, trusted))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// resolveScheme usa o último X-Forwarded-Proto (o do proxy mais próximo) se a
+// conexão vier de um proxy confiável; senão, o da própria conexão.
+func resolveScheme(r *http.Request, trusted []*net.IPNet) string {
+	if isTrusted(remoteIP(r), trusted) {
+		if values := r.Header.Values("X-Forwarded-Proto"); len(values) > 0 {
+			protos := strings.Split(values[len(values)-1], ",")
+			switch proto := strings.ToLower(strings.TrimSpace(protos[len(protos)-1])); proto {
+			case "http", "https":
+				return proto
+			}
+		}
+	}
+	return connScheme(r)
+}
+
+func connScheme(r *http.Request) string {
+	if r.TLS != nil {
+		return "https"
+	}
+	return "http"
+}
+
+func resolveClientIP(r *http.Request, trusted []*net.IPNet) string {
+	ip := remoteIP(r)
+	if !isTrusted(ip, trusted) {
+		return ip
+	}
+
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop := strings.TrimSpace(hops[i])
+		if net.ParseIP(hop) == nil {
+			break
+		}
+		ip = hop
+		if !isTrusted(hop, trusted) {
+			break
+		}
+	}
+	return ip
+}
+
+func isTrusted(ip string, trusted []*net.IPNet) bool {
+	parsed := net.ParseIP(ip)
+	if parsed == nil {
+		return false
+	}
+	for _, n := range trusted {
+		if n.Contains(parsed) {
+			return true
+		}
+	}
+	return false
+}
+
+func remoteIP(r *http.Request) string {
+	ip, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return ip
+}
+
+// ClientIP devolve o IP do cliente resolvido por RealIP (ou o da conexão, se
+// o middleware não estiver em uso).
+func ClientIP(r *http.Request) string {
+	if ip, ok := r.Context().Value(clientIPKey).(string); ok {
+		return ip
+	}
+	return remoteIP(r)
+}
+
+// Scheme devolve o esquema ("http" ou "https") usado pelo cliente, resolvido
+// por RealIP (ou o da conexão, se o middleware não estiver em uso).
+func Scheme(r *http.Request) string {
+	if scheme, ok := r.Context().Value(clientSchemeKey).(string); ok {
+		return scheme
+	}
+	return connScheme(r)
+}
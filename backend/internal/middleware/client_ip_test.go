@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRealIPAndScheme(t *testing.T) {
+	trusted, err := ParseTrustedProxies("10.0.0.0/8, 127.0.0.1")
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		tls        bool
+		forwarded  []string
+		proto      []string
+		wantIP     string
+		wantScheme string
+	}{
+		{name: "conexão direta", remoteAddr: "203.0.113.7:5000", wantIP: "203.0.113.7", wantScheme: "http"},
+		{name: "conexão direta com TLS", remoteAddr: "203.0.113.7:5000", tls: true, wantIP: "203.0.113.7", wantScheme: "https"},
+		{
+			name:       "cabeçalhos de cliente não confiável são ignorados",
+			remoteAddr: "203.0.113.7:5000",
+			forwarded:  []string{"1.1.1.1"},
+			proto:      []string{"https"},
+			wantIP:     "203.0.113.7",
+			wantScheme: "http",
+		},
+		{
+			name:       "proxy confiável",
+			remoteAddr: "10.1.2.3:5000",
+			forwarded:  []string{"198.51.100.9"},
+			proto:      []string{"https"},
+			wantIP:     "198.51.100.9",
+			wantScheme: "https",
+		},
+		{
+			name:       "entradas forjadas à esquerda são ignoradas",
+			remoteAddr: "10.1.2.3:5000",
+			forwarded:  []string{"6.6.6.6, 198.51.100.9", "127.0.0.1"},
+			proto:      []string{"http, https"},
+			wantIP:     "198.51.100.9",
+			wantScheme: "https",
+		},
+		{
+			name:       "proto desconhecido do proxy cai no da conexão",
+			remoteAddr: "127.0.0.1:5000",
+			proto:      []string{"gopher"},
+			wantIP:     "127.0.0.1",
+			wantScheme: "http",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			if tt.tls {
+				r.TLS = &tls.ConnectionState{}
+			}
+			for _, v := range tt.forwarded {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			for _, v := range tt.proto {
+				r.Header.Add("X-Forwarded-Proto", v)
+			}
+
+			var gotIP, gotScheme string
+			RealIP(trusted)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				gotIP, gotScheme = ClientIP(r), Scheme(r)
+			})).ServeHTTP(httptest.NewRecorder(), r)
+
+			if gotIP != tt.wantIP || gotScheme != tt.wantScheme {
+				t.Fatalf("IP %s e esquema %s, esperado %s e %s", gotIP, gotScheme, tt.wantIP, tt.wantScheme)
+			}
+		})
+	}
+}
+
+func TestSchemeWithoutMiddleware(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set("X-Forwarded-Proto", "https")
+	if got := Scheme(r); got != "http" {
+		t.Fatalf("esquema = %s, esperado http", got)
+	}
+}
+
+func TestParseTrustedProxiesRejectsInvalid(t *testing.T) {
+	for _, spec := range []string{"10.0.0.0/33", "not-an-ip", "10.0.0.1/x"} {
+		if _, err := ParseTrustedProxies(spec); err == nil {
+			t.Errorf("ParseTrustedProxies(%q): esperado erro", spec)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"project_lab/internal/i18n"
+)
+
+// Locale escolhe o idioma das respostas pelo cabeçalho Accept-Language e o
+// guarda no contexto da requisição (veja i18n.FromContext).
+func Locale(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		locale := i18n.Negotiate(r.Header.Get("Accept-Language"))
+		w.Header().Add("Vary", "Accept-Language")
+		next.ServeHTTP(w, withLocale(w, r, locale))
+	})
+}
+
+// PreferredLocale sobrepõe o idioma negociado pelo idioma preferido do usuário
+// autenticado, quando ele tiver um. Deve vir depois de AuthMiddleware. Uma
+// falha na consulta não impede a requisição: o Accept-Language continua valendo.
+func PreferredLocale(lookup func(ctx context.Context, userID int) (string, error)) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userID, ok := r.Context().Value(UserIDKey).(int)
+			if !ok {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			preferred, err := lookup(r.Context(), userID)
+			if err != nil {
+				fmt.Printf("Erro ao buscar idioma preferido do usuário %d: %v\n", userID, err)
+				next.ServeHTTP(w, r)
+				return
+			}
+			if locale, ok := i18n.Parse(preferred); ok && preferred != "" {
+				r = withLocale(w, r, locale)
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func withLocale(w http.ResponseWriter, r *http.Request, locale i18n.Locale) *http.Request {
+	w.Header().Set("Content-Language", string(locale))
+	return r.WithContext(i18n.WithLocale(r.Context(), locale))
+}
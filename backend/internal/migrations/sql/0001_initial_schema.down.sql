@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "expense_participants";
+DROP TABLE IF EXISTS "expenses";
+DROP TABLE IF EXISTS "votes";
+DROP TABLE IF EXISTS "votings";
+DROP TABLE IF EXISTS "destinations";
+DROP TABLE IF EXISTS "group_members";
+DROP TABLE IF EXISTS "travel_groups";
+DROP TABLE IF EXISTS "users";
@@ -0,0 +1,70 @@
+CREATE TABLE IF NOT EXISTS "users" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "name" varchar(255) NOT NULL,
+  "email" varchar(255) UNIQUE NOT NULL,
+  "password_hash" varchar(255) NOT NULL,
+  "created_at" timestamp,
+  "updated_at" timestamp
+);
+
+CREATE TABLE IF NOT EXISTS "travel_groups" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "name" varchar(255) NOT NULL,
+  "description" text,
+  "creator_id" integer NOT NULL REFERENCES "users" ("id"),
+  "start_date" date,
+  "end_date" date,
+  "created_at" timestamp
+);
+
+-- Bancos criados antes da coluna existir no script original.
+ALTER TABLE "travel_groups" ADD COLUMN IF NOT EXISTS "description" text;
+
+CREATE TABLE IF NOT EXISTS "group_members" (
+  "travel_group_id" integer NOT NULL REFERENCES "travel_groups" ("id"),
+  "user_id" integer NOT NULL REFERENCES "users" ("id"),
+  "created_at" timestamp,
+  PRIMARY KEY (travel_group_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS "destinations" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "travel_group_id" integer NOT NULL REFERENCES "travel_groups" ("id"),
+  "name" varchar(255) NOT NULL,
+  "location" varchar(255),
+  "description" text,
+  "created_at" timestamp
+);
+
+CREATE TABLE IF NOT EXISTS "votings" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "travel_group_id" integer NOT NULL REFERENCES "travel_groups" ("id"),
+  "question" text NOT NULL,
+  "options" text,
+  "created_at" timestamp
+);
+
+COMMENT ON COLUMN "votings"."options" IS 'JSON array of options';
+
+CREATE TABLE IF NOT EXISTS "votes" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "voting_id" integer NOT NULL REFERENCES "votings" ("id"),
+  "user_id" integer NOT NULL REFERENCES "users" ("id"),
+  "selected_option" varchar(255) NOT NULL,
+  "created_at" timestamp
+);
+
+CREATE TABLE IF NOT EXISTS "expenses" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "travel_group_id" integer NOT NULL REFERENCES "travel_groups" ("id"),
+  "description" text,
+  "amount" decimal(10,2) NOT NULL,
+  "payer_id" integer NOT NULL REFERENCES "users" ("id"),
+  "created_at" timestamp
+);
+
+CREATE TABLE IF NOT EXISTS "expense_participants" (
+  "expense_id" integer NOT NULL REFERENCES "expenses" ("id"),
+  "user_id" integer NOT NULL REFERENCES "users" ("id"),
+  PRIMARY KEY (expense_id, user_id)
+);
@@ -0,0 +1,14 @@
+CREATE TABLE IF NOT EXISTS "group_invites" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "travel_group_id" integer NOT NULL REFERENCES "travel_groups" ("id"),
+  "code" varchar(64) UNIQUE NOT NULL,
+  "email" varchar(255),
+  "max_uses" integer,
+  "uses" integer NOT NULL DEFAULT 0,
+  "status" varchar(20) NOT NULL DEFAULT 'pending',
+  "expires_at" timestamptz,
+  "created_by" integer NOT NULL REFERENCES "users" ("id"),
+  "created_at" timestamp
+);
+
+COMMENT ON COLUMN "group_invites"."email" IS 'NULL para códigos compartilháveis';
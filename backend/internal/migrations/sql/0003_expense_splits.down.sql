@@ -0,0 +1,3 @@
+ALTER TABLE "expense_participants" DROP COLUMN IF EXISTS "share_amount";
+
+ALTER TABLE "expenses" DROP COLUMN IF EXISTS "split_mode";
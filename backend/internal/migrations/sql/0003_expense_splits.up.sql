@@ -0,0 +1,20 @@
+ALTER TABLE "expenses" ADD COLUMN IF NOT EXISTS "split_mode" varchar(20) NOT NULL DEFAULT 'equal';
+
+ALTER TABLE "expense_participants" ADD COLUMN IF NOT EXISTS "share_amount" decimal(10,2);
+
+-- Despesas antigas não tinham o valor por participante: preenche com a divisão
+-- igualitária, distribuindo os centavos restantes pelos menores user_id.
+UPDATE "expense_participants" ep
+SET "share_amount" = s.share
+FROM (
+  SELECT
+    p.expense_id,
+    p.user_id,
+    (FLOOR(e.amount * 100 / COUNT(*) OVER w)
+      + CASE WHEN ROW_NUMBER() OVER w_ord <= (e.amount * 100)::bigint % COUNT(*) OVER w THEN 1 ELSE 0 END
+    ) / 100 AS share
+  FROM "expense_participants" p
+  JOIN "expenses" e ON e.id = p.expense_id
+  WINDOW w AS (PARTITION BY p.expense_id), w_ord AS (PARTITION BY p.expense_id ORDER BY p.user_id)
+) s
+WHERE ep.expense_id = s.expense_id AND ep.user_id = s.user_id AND ep.share_amount IS NULL;
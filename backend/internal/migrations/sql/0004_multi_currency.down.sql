@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "exchange_rates";
+
+ALTER TABLE "expenses" DROP COLUMN IF EXISTS "currency";
+
+ALTER TABLE "travel_groups" DROP COLUMN IF EXISTS "base_currency";
@@ -0,0 +1,14 @@
+ALTER TABLE "travel_groups" ADD COLUMN IF NOT EXISTS "base_currency" varchar(3) NOT NULL DEFAULT 'BRL';
+
+ALTER TABLE "expenses" ADD COLUMN IF NOT EXISTS "currency" varchar(3) NOT NULL DEFAULT 'BRL';
+
+CREATE TABLE IF NOT EXISTS "exchange_rates" (
+  "base_currency" varchar(3) NOT NULL,
+  "quote_currency" varchar(3) NOT NULL,
+  "rate" decimal(18,8) NOT NULL,
+  "valid_on" date NOT NULL,
+  "created_at" timestamp,
+  PRIMARY KEY (base_currency, quote_currency, valid_on)
+);
+
+COMMENT ON COLUMN "exchange_rates"."rate" IS '1 base_currency = rate quote_currency';
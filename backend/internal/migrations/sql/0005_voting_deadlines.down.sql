@@ -0,0 +1,5 @@
+ALTER TABLE "votings" DROP COLUMN IF EXISTS "closed_at";
+
+ALTER TABLE "votings" DROP COLUMN IF EXISTS "closes_at";
+
+ALTER TABLE "votings" DROP COLUMN IF EXISTS "created_by";
@@ -0,0 +1,5 @@
+ALTER TABLE "votings" ADD COLUMN IF NOT EXISTS "created_by" integer REFERENCES "users" ("id");
+
+ALTER TABLE "votings" ADD COLUMN IF NOT EXISTS "closes_at" timestamptz;
+
+ALTER TABLE "votings" ADD COLUMN IF NOT EXISTS "closed_at" timestamptz;
@@ -0,0 +1 @@
+DROP INDEX IF EXISTS "votes_voting_id_user_id_key";
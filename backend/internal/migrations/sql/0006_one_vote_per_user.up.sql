@@ -0,0 +1,7 @@
+-- Um voto por usuário em cada votação: remove duplicatas antigas (mantém o mais
+-- recente) antes de criar o índice único.
+DELETE FROM "votes" a
+USING "votes" b
+WHERE a.voting_id = b.voting_id AND a.user_id = b.user_id AND a.id < b.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "votes_voting_id_user_id_key" ON "votes" ("voting_id", "user_id");
@@ -0,0 +1,5 @@
+ALTER TABLE "votes" DROP COLUMN IF EXISTS "selections";
+
+ALTER TABLE "votings" DROP COLUMN IF EXISTS "max_selections";
+
+ALTER TABLE "votings" DROP COLUMN IF EXISTS "voting_type";
@@ -0,0 +1,7 @@
+ALTER TABLE "votings" ADD COLUMN IF NOT EXISTS "voting_type" varchar(20) NOT NULL DEFAULT 'single';
+
+ALTER TABLE "votings" ADD COLUMN IF NOT EXISTS "max_selections" integer;
+
+ALTER TABLE "votes" ADD COLUMN IF NOT EXISTS "selections" text;
+
+COMMENT ON COLUMN "votes"."selections" IS 'JSON array com as opções escolhidas (em ordem de preferência no tipo ranked)';
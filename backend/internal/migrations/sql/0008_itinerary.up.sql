@@ -0,0 +1,18 @@
+CREATE TABLE IF NOT EXISTS "itinerary_items" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "travel_group_id" integer NOT NULL REFERENCES "travel_groups" ("id"),
+  "destination_id" integer REFERENCES "destinations" ("id") ON DELETE SET NULL,
+  "activity" varchar(255) NOT NULL,
+  "starts_at" timestamp NOT NULL,
+  "ends_at" timestamp NOT NULL,
+  "notes" text,
+  "estimated_cost" decimal(10,2),
+  "currency" varchar(3) NOT NULL DEFAULT 'BRL',
+  "created_by" integer NOT NULL REFERENCES "users" ("id"),
+  "created_at" timestamp,
+  CHECK ("ends_at" > "starts_at")
+);
+
+COMMENT ON COLUMN "itinerary_items"."starts_at" IS 'Horário local da viagem (sem fuso)';
+
+CREATE INDEX IF NOT EXISTS "itinerary_items_group_starts_at_idx" ON "itinerary_items" ("travel_group_id", "starts_at");
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS "calendar_feed_tokens" (
+  "user_id" integer PRIMARY KEY REFERENCES "users" ("id"),
+  "token_hash" varchar(64) UNIQUE NOT NULL,
+  "created_at" timestamp
+);
+
+COMMENT ON COLUMN "calendar_feed_tokens"."token_hash" IS 'SHA-256 do token da URL do feed';
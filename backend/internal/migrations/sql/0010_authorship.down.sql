@@ -0,0 +1,3 @@
+ALTER TABLE "expenses" DROP COLUMN IF EXISTS "created_by";
+
+ALTER TABLE "destinations" DROP COLUMN IF EXISTS "created_by";
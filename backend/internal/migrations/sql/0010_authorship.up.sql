@@ -0,0 +1,6 @@
+ALTER TABLE "destinations" ADD COLUMN IF NOT EXISTS "created_by" integer;
+
+ALTER TABLE "expenses" ADD COLUMN IF NOT EXISTS "created_by" integer;
+
+-- Despesas antigas não registravam o autor: quem pagou é quem lançou.
+UPDATE "expenses" SET "created_by" = "payer_id" WHERE "created_by" IS NULL;
@@ -0,0 +1,36 @@
+-- Volta às chaves sem regra ON DELETE (exceto o destino do roteiro, que já
+-- nasceu com SET NULL) e remove as chaves de autoria, que não existiam antes.
+ALTER TABLE "destinations" DROP CONSTRAINT IF EXISTS "destinations_created_by_fkey";
+
+ALTER TABLE "expenses" DROP CONSTRAINT IF EXISTS "expenses_created_by_fkey";
+
+DO $$
+DECLARE
+  spec record;
+BEGIN
+  FOR spec IN SELECT * FROM (VALUES
+    ('travel_groups', 'creator_id', 'users', 'NO ACTION'),
+    ('group_members', 'travel_group_id', 'travel_groups', 'NO ACTION'),
+    ('group_members', 'user_id', 'users', 'NO ACTION'),
+    ('destinations', 'travel_group_id', 'travel_groups', 'NO ACTION'),
+    ('votings', 'travel_group_id', 'travel_groups', 'NO ACTION'),
+    ('votings', 'created_by', 'users', 'NO ACTION'),
+    ('votes', 'voting_id', 'votings', 'NO ACTION'),
+    ('votes', 'user_id', 'users', 'NO ACTION'),
+    ('expenses', 'travel_group_id', 'travel_groups', 'NO ACTION'),
+    ('expenses', 'payer_id', 'users', 'NO ACTION'),
+    ('expense_participants', 'expense_id', 'expenses', 'NO ACTION'),
+    ('expense_participants', 'user_id', 'users', 'NO ACTION'),
+    ('group_invites', 'travel_group_id', 'travel_groups', 'NO ACTION'),
+    ('group_invites', 'created_by', 'users', 'NO ACTION'),
+    ('itinerary_items', 'travel_group_id', 'travel_groups', 'NO ACTION'),
+    ('itinerary_items', 'destination_id', 'destinations', 'SET NULL'),
+    ('itinerary_items', 'created_by', 'users', 'NO ACTION'),
+    ('calendar_feed_tokens', 'user_id', 'users', 'NO ACTION')
+  ) AS s(tbl, col, ref, rule)
+  LOOP
+    EXECUTE format('ALTER TABLE %I DROP CONSTRAINT IF EXISTS %I', spec.tbl, spec.tbl || '_' || spec.col || '_fkey');
+    EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (%I) REFERENCES %I ("id") ON DELETE %s',
+      spec.tbl, spec.tbl || '_' || spec.col || '_fkey', spec.col, spec.ref, spec.rule);
+  END LOOP;
+END $$;
@@ -0,0 +1,56 @@
+-- Chaves estrangeiras com regra ON DELETE explícita: apagar um grupo remove
+-- tudo o que pertence a ele; apagar uma votação remove os votos; apagar uma
+-- despesa remove as partes dos participantes. Versões anteriores adicionavam
+-- uma cópia sem regra a cada inicialização: todas as chaves da coluna são
+-- substituídas por uma única constraint nomeada, e nada é feito se ela já existe
+-- com a regra certa.
+DO $$
+DECLARE
+  spec record;
+  fk record;
+BEGIN
+  FOR spec IN SELECT * FROM (VALUES
+    ('travel_groups', 'creator_id', 'users', 'RESTRICT', 'r'),
+    ('group_members', 'travel_group_id', 'travel_groups', 'CASCADE', 'c'),
+    ('group_members', 'user_id', 'users', 'CASCADE', 'c'),
+    ('destinations', 'travel_group_id', 'travel_groups', 'CASCADE', 'c'),
+    ('destinations', 'created_by', 'users', 'SET NULL', 'n'),
+    ('votings', 'travel_group_id', 'travel_groups', 'CASCADE', 'c'),
+    ('votings', 'created_by', 'users', 'SET NULL', 'n'),
+    ('votes', 'voting_id', 'votings', 'CASCADE', 'c'),
+    ('votes', 'user_id', 'users', 'CASCADE', 'c'),
+    ('expenses', 'travel_group_id', 'travel_groups', 'CASCADE', 'c'),
+    ('expenses', 'payer_id', 'users', 'RESTRICT', 'r'),
+    ('expenses', 'created_by', 'users', 'SET NULL', 'n'),
+    ('expense_participants', 'expense_id', 'expenses', 'CASCADE', 'c'),
+    ('expense_participants', 'user_id', 'users', 'RESTRICT', 'r'),
+    ('group_invites', 'travel_group_id', 'travel_groups', 'CASCADE', 'c'),
+    ('group_invites', 'created_by', 'users', 'CASCADE', 'c'),
+    ('itinerary_items', 'travel_group_id', 'travel_groups', 'CASCADE', 'c'),
+    ('itinerary_items', 'destination_id', 'destinations', 'SET NULL', 'n'),
+    ('itinerary_items', 'created_by', 'users', 'RESTRICT', 'r'),
+    ('calendar_feed_tokens', 'user_id', 'users', 'CASCADE', 'c')
+  ) AS s(tbl, col, ref, rule, code)
+  LOOP
+    IF (
+      SELECT COUNT(*) = 1 AND BOOL_AND(c.conname = spec.tbl || '_' || spec.col || '_fkey' AND c.confdeltype = spec.code)
+      FROM pg_constraint c
+      JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+      WHERE c.contype = 'f' AND c.conrelid = spec.tbl::regclass AND a.attname = spec.col
+    ) THEN
+      CONTINUE;
+    END IF;
+
+    FOR fk IN
+      SELECT c.conname
+      FROM pg_constraint c
+      JOIN pg_attribute a ON a.attrelid = c.conrelid AND a.attnum = c.conkey[1]
+      WHERE c.contype = 'f' AND c.conrelid = spec.tbl::regclass AND a.attname = spec.col
+    LOOP
+      EXECUTE format('ALTER TABLE %I DROP CONSTRAINT %I', spec.tbl, fk.conname);
+    END LOOP;
+
+    EXECUTE format('ALTER TABLE %I ADD CONSTRAINT %I FOREIGN KEY (%I) REFERENCES %I ("id") ON DELETE %s',
+      spec.tbl, spec.tbl || '_' || spec.col || '_fkey', spec.col, spec.ref, spec.rule);
+  END LOOP;
+END $$;
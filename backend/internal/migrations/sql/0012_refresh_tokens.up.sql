@@ -0,0 +1,16 @@
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" integer NOT NULL REFERENCES "users" ("id") ON DELETE CASCADE,
+  "family_id" varchar(64) NOT NULL,
+  "token_hash" varchar(64) UNIQUE NOT NULL,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz,
+  "revoked_at" timestamptz,
+  "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN "refresh_tokens"."family_id" IS 'Sessão iniciada no login; todos os tokens trocados a partir dela compartilham o valor';
+
+COMMENT ON COLUMN "refresh_tokens"."token_hash" IS 'SHA-256 do refresh token';
+
+CREATE INDEX IF NOT EXISTS "refresh_tokens_family_id_idx" ON "refresh_tokens" ("family_id");
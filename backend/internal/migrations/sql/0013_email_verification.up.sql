@@ -0,0 +1,13 @@
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "email_verified_at" timestamptz;
+
+-- Contas anteriores à verificação de e-mail não receberam o link: são
+-- consideradas verificadas para não ficarem bloqueadas.
+UPDATE "users" SET "email_verified_at" = COALESCE("created_at", NOW()) WHERE "email_verified_at" IS NULL;
+
+CREATE TABLE IF NOT EXISTS "used_tokens" (
+  "jti" varchar(64) PRIMARY KEY,
+  "expires_at" timestamptz NOT NULL,
+  "used_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON TABLE "used_tokens" IS 'Tokens assinados de uso único (verificação de e-mail, redefinição de senha) já consumidos';
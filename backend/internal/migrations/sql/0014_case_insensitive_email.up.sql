@@ -0,0 +1,6 @@
+-- E-mails passam a ser gravados em minúsculas e comparados sem diferenciar
+-- maiúsculas. Se duas contas diferirem só nas maiúsculas, a migração falha
+-- e a duplicata precisa ser resolvida manualmente antes de migrar.
+UPDATE "users" SET "email" = LOWER(TRIM("email")) WHERE "email" <> LOWER(TRIM("email"));
+
+CREATE UNIQUE INDEX IF NOT EXISTS "users_email_lower_key" ON "users" (LOWER("email"));
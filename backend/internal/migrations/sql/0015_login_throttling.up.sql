@@ -0,0 +1,22 @@
+CREATE TABLE IF NOT EXISTS "login_throttles" (
+  "email" varchar(255) PRIMARY KEY,
+  "failed_count" integer NOT NULL DEFAULT 0,
+  "last_failed_at" timestamptz NOT NULL,
+  "locked_until" timestamptz
+);
+
+COMMENT ON TABLE "login_throttles" IS 'Falhas de login seguidas por e-mail normalizado (existente ou não)';
+
+CREATE TABLE IF NOT EXISTS "login_audit" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "user_id" integer REFERENCES "users" ("id") ON DELETE CASCADE,
+  "email" varchar(255) NOT NULL,
+  "ip" varchar(64) NOT NULL,
+  "user_agent" varchar(255) NOT NULL DEFAULT '',
+  "reason" varchar(20) NOT NULL,
+  "created_at" timestamptz NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN "login_audit"."user_id" IS 'NULL quando o e-mail não pertence a nenhuma conta';
+
+CREATE INDEX IF NOT EXISTS "login_audit_user_id_created_at_idx" ON "login_audit" ("user_id", "created_at" DESC);
@@ -0,0 +1,3 @@
+-- Idioma preferido do usuário para mensagens da API e e-mails. NULL segue o
+-- Accept-Language de cada requisição.
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "preferred_locale" varchar(10);
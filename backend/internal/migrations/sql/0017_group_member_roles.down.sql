@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "group_members_one_organizer";
+
+ALTER TABLE "group_members" DROP CONSTRAINT IF EXISTS "group_members_role_check";
+
+ALTER TABLE "group_members" DROP COLUMN IF EXISTS "role";
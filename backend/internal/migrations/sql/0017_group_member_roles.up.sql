@@ -0,0 +1,23 @@
+-- Papel de cada membro no grupo. O organizador é o dono (travel_groups.creator_id)
+-- e é único por grupo; os demais entram como participantes.
+ALTER TABLE "group_members" ADD COLUMN IF NOT EXISTS "role" varchar(20) NOT NULL DEFAULT 'participant';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'group_members_role_check') THEN
+    ALTER TABLE "group_members" ADD CONSTRAINT "group_members_role_check"
+      CHECK ("role" IN ('organizer', 'co_organizer', 'participant', 'viewer'));
+  END IF;
+END $$;
+
+-- Grupos antigos: garante que o criador é membro e marca-o como organizador.
+INSERT INTO "group_members" ("travel_group_id", "user_id", "created_at")
+SELECT tg.id, tg.creator_id, NOW()
+FROM "travel_groups" tg
+ON CONFLICT DO NOTHING;
+
+UPDATE "group_members" gm SET "role" = 'organizer'
+FROM "travel_groups" tg
+WHERE tg.id = gm.travel_group_id AND tg.creator_id = gm.user_id AND gm.role <> 'organizer';
+
+CREATE UNIQUE INDEX IF NOT EXISTS "group_members_one_organizer" ON "group_members" ("travel_group_id") WHERE "role" = 'organizer';
@@ -0,0 +1,4 @@
+-- Sem left_at, os ex-membros voltariam a ser membros: remove-os antes.
+DELETE FROM "group_members" WHERE "left_at" IS NOT NULL;
+
+ALTER TABLE "group_members" DROP COLUMN IF EXISTS "left_at";
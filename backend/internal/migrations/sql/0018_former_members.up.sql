@@ -0,0 +1,3 @@
+-- Quem sai ou é removido do grupo não perde a linha em group_members: left_at
+-- marca o ex-membro, cujas despesas, partes e votos continuam no histórico.
+ALTER TABLE "group_members" ADD COLUMN IF NOT EXISTS "left_at" timestamp;
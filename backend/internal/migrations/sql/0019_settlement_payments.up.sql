@@ -0,0 +1,15 @@
+-- Pagamentos já feitos entre membros para acertar as contas. Entram nos saldos
+-- como uma transferência: quem pagou fica com saldo maior; quem recebeu, menor.
+CREATE TABLE IF NOT EXISTS "settlement_payments" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "travel_group_id" integer NOT NULL REFERENCES "travel_groups" ("id") ON DELETE CASCADE,
+  "from_user_id" integer NOT NULL REFERENCES "users" ("id"),
+  "to_user_id" integer NOT NULL REFERENCES "users" ("id"),
+  "amount" decimal(10,2) NOT NULL CHECK ("amount" > 0),
+  "currency" varchar(3) NOT NULL,
+  "created_by" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+  "created_at" timestamp NOT NULL DEFAULT NOW(),
+  CHECK ("from_user_id" <> "to_user_id")
+);
+
+CREATE INDEX IF NOT EXISTS "settlement_payments_travel_group_id" ON "settlement_payments" ("travel_group_id");
@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS "group_budget_categories";
+DROP TABLE IF EXISTS "group_budgets";
+ALTER TABLE "expenses" DROP CONSTRAINT IF EXISTS "expenses_category_check";
+ALTER TABLE "expenses" DROP COLUMN IF EXISTS "category";
@@ -0,0 +1,30 @@
+-- Categoria de cada despesa, usada no orçamento do grupo.
+ALTER TABLE "expenses" ADD COLUMN IF NOT EXISTS "category" varchar(20) NOT NULL DEFAULT 'other';
+
+DO $$
+BEGIN
+  IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'expenses_category_check') THEN
+    ALTER TABLE "expenses" ADD CONSTRAINT "expenses_category_check"
+      CHECK ("category" IN ('lodging', 'transport', 'food', 'activities', 'shopping', 'other'));
+  END IF;
+END $$;
+
+-- Orçamento do grupo, na moeda base: total, meta por pessoa e o percentual do
+-- planejado a partir do qual os organizadores são avisados.
+CREATE TABLE IF NOT EXISTS "group_budgets" (
+  "travel_group_id" integer PRIMARY KEY REFERENCES "travel_groups" ("id") ON DELETE CASCADE,
+  "total_amount" decimal(10,2),
+  "per_person_amount" decimal(10,2),
+  "alert_threshold" smallint NOT NULL DEFAULT 100 CHECK ("alert_threshold" BETWEEN 1 AND 1000),
+  "updated_at" timestamp NOT NULL DEFAULT NOW()
+);
+
+-- Valor planejado por categoria. alerted_at marca o aviso já enviado, para não
+-- repetir a cada despesa; volta a NULL quando o gasto fica abaixo do limite.
+CREATE TABLE IF NOT EXISTS "group_budget_categories" (
+  "travel_group_id" integer NOT NULL REFERENCES "group_budgets" ("travel_group_id") ON DELETE CASCADE,
+  "category" varchar(20) NOT NULL,
+  "planned_amount" decimal(10,2) NOT NULL,
+  "alerted_at" timestamp,
+  PRIMARY KEY ("travel_group_id", "category")
+);
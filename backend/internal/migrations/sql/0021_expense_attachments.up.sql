@@ -0,0 +1,15 @@
+-- Comprovantes das despesas. O arquivo fica no BlobStore (diretório local ou
+-- S3); aqui só a chave, o tipo detectado no conteúdo e a miniatura, se houver.
+CREATE TABLE IF NOT EXISTS "expense_attachments" (
+  "id" INTEGER GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+  "expense_id" integer NOT NULL REFERENCES "expenses" ("id") ON DELETE CASCADE,
+  "uploaded_by" integer REFERENCES "users" ("id") ON DELETE SET NULL,
+  "file_name" varchar(255) NOT NULL,
+  "content_type" varchar(100) NOT NULL,
+  "size_bytes" bigint NOT NULL,
+  "storage_key" varchar(255) NOT NULL UNIQUE,
+  "thumbnail_key" varchar(255),
+  "created_at" timestamp NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS "expense_attachments_expense_id" ON "expense_attachments" ("expense_id");
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"mime/multipart"
+	"time"
+)
+
+// ExpenseAttachment é um comprovante anexado a uma despesa (expense_attachments).
+// URL e ThumbnailURL apontam para as rotas de download da API, que conferem se
+// quem baixa é membro do grupo.
+type ExpenseAttachment struct {
+	ID           int       `json:"id"`
+	ExpenseID    int       `json:"expenseId"`
+	FileName     string    `json:"fileName"`
+	ContentType  string    `json:"contentType"`
+	Size         int64     `json:"size"`
+	UploadedBy   *int      `json:"uploadedBy"`
+	CreatedAt    time.Time `json:"createdAt"`
+	URL          string    `json:"url"`
+	ThumbnailURL *string   `json:"thumbnailUrl"`
+
+	StorageKey   string  `json:"-"`
+	ThumbnailKey *string `json:"-"`
+}
+
+// AttachmentUploadForm documenta o corpo multipart/form-data do upload: um
+// único arquivo no campo "file".
+type AttachmentUploadForm struct {
+	File *multipart.FileHeader `json:"file"`
+}
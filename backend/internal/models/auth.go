@@ -0,0 +1,13 @@
+package models
+
+// AuthTokens é a resposta de login e de /auth/refresh.
+type AuthTokens struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+	ExpiresIn    int    `json:"expiresIn"` // validade do token de acesso, em segundos
+}
+
+// RefreshTokenRequest corresponde ao payload de /auth/refresh e /auth/logout.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
@@ -0,0 +1,94 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Categorias de despesa (expenses.category), usadas no orçamento do grupo.
+const (
+	CategoryLodging    = "lodging"
+	CategoryTransport  = "transport"
+	CategoryFood       = "food"
+	CategoryActivities = "activities"
+	CategoryShopping   = "shopping"
+	CategoryOther      = "other" // padrão quando a despesa não informa categoria
+)
+
+// ExpenseCategories lista as categorias na ordem em que aparecem no orçamento.
+var ExpenseCategories = []string{CategoryLodging, CategoryTransport, CategoryFood, CategoryActivities, CategoryShopping, CategoryOther}
+
+// IsValidExpenseCategory verifica se a categoria é uma das aceitas.
+func IsValidExpenseCategory(category string) bool {
+	return slices.Contains(ExpenseCategories, category)
+}
+
+// DefaultAlertThreshold é o percentual do planejado a partir do qual uma
+// categoria gera aviso quando o grupo não define outro.
+const DefaultAlertThreshold = 100
+
+// CategoryBudgetInput é o valor planejado para uma categoria.
+type CategoryBudgetInput struct {
+	Category string `json:"category"`
+	Planned  Money  `json:"planned"`
+}
+
+// BudgetUpdateRequest é o payload de PUT /groups/{id}/budget. Os valores estão
+// na moeda base do grupo; o orçamento anterior é substituído por inteiro.
+type BudgetUpdateRequest struct {
+	Total          *Money                `json:"total"`
+	PerPerson      *Money                `json:"perPerson"`
+	AlertThreshold int                   `json:"alertThreshold"` // % do planejado; 0 usa o padrão (100)
+	Categories     []CategoryBudgetInput `json:"categories"`
+}
+
+// GroupBudget é o orçamento gravado do grupo (group_budgets e group_budget_categories).
+type GroupBudget struct {
+	TravelGroupID  int
+	Total          *Money
+	PerPerson      *Money
+	AlertThreshold int
+	Categories     []CategoryBudget
+}
+
+// CategoryBudget é o planejado de uma categoria e quando o último aviso foi enviado.
+type CategoryBudget struct {
+	Category  string
+	Planned   Money
+	AlertedAt *time.Time
+}
+
+// CategoryBudgetReport compara o planejado e o gasto de uma categoria.
+type CategoryBudgetReport struct {
+	Category    string `json:"category"`
+	Planned     *Money `json:"planned"`
+	Spent       Money  `json:"spent"`
+	Remaining   *Money `json:"remaining"`
+	PercentUsed *int   `json:"percentUsed"`
+	OverBudget  bool   `json:"overBudget"`
+	Alert       bool   `json:"alert"` // atingiu o limite de aviso
+}
+
+// MemberBudgetReport compara o que cabe a um membro nas despesas com a meta por pessoa.
+type MemberBudgetReport struct {
+	UserID       int    `json:"userId"`
+	Name         string `json:"name"`
+	Spent        Money  `json:"spent"`
+	Target       *Money `json:"target"`
+	OverBudget   bool   `json:"overBudget"`
+	FormerMember bool   `json:"formerMember,omitempty"`
+}
+
+// BudgetReport é a resposta de GET /groups/{id}/budget: planejado x realizado
+// na moeda base do grupo.
+type BudgetReport struct {
+	Currency       string                 `json:"currency"`
+	AlertThreshold int                    `json:"alertThreshold"`
+	Planned        *Money                 `json:"planned"`
+	Spent          Money                  `json:"spent"`
+	Remaining      *Money                 `json:"remaining"`
+	OverBudget     bool                   `json:"overBudget"`
+	PerPerson      *Money                 `json:"perPerson"`
+	Categories     []CategoryBudgetReport `json:"categories"`
+	Members        []MemberBudgetReport   `json:"members"`
+}
@@ -0,0 +1,14 @@
+package models
+
+import (
+	"time"
+)
+
+// ExchangeRate mapeia a tabela exchange_rates: 1 BaseCurrency = Rate QuoteCurrency
+// na data ValidOn. Rate é mantido como decimal em texto para não perder precisão.
+type ExchangeRate struct {
+	BaseCurrency  string    `json:"baseCurrency"`
+	QuoteCurrency string    `json:"quoteCurrency"`
+	Rate          string    `json:"rate"`
+	ValidOn       time.Time `json:"validOn"`
+}
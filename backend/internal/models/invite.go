@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+)
+
+// Status possíveis de um convite de grupo.
+const (
+	InviteStatusPending  = "pending"
+	InviteStatusAccepted = "accepted"
+	InviteStatusDeclined = "declined"
+	InviteStatusRevoked  = "revoked"
+)
+
+// GroupInvite mapeia a tabela group_invites.
+// Convites com Email são de uso único e direcionados a uma pessoa;
+// convites sem Email são códigos compartilháveis (MaxUses opcional).
+type GroupInvite struct {
+	ID            int        `json:"id"`
+	TravelGroupID int        `json:"groupId"`
+	Code          string     `json:"code"`
+	Email         *string    `json:"email"`
+	MaxUses       *int       `json:"maxUses"`
+	Uses          int        `json:"uses"`
+	Status        string     `json:"status"`
+	ExpiresAt     *time.Time `json:"expiresAt"`
+	CreatedBy     int        `json:"createdBy"`
+	CreatedAt     time.Time  `json:"createdAt"`
+}
+
+// InviteCreateRequest é o payload para criar um convite.
+// Se Email for informado, o convite é de uso único para aquele e-mail.
+type InviteCreateRequest struct {
+	Email          string `json:"email"`
+	MaxUses        *int   `json:"maxUses"`
+	ExpiresInHours *int   `json:"expiresInHours"`
+}
+
+// PendingInviteDTO representa um convite pendente endereçado ao usuário logado.
+type PendingInviteDTO struct {
+	Code      string     `json:"code"`
+	GroupID   int        `json:"groupId"`
+	GroupName string     `json:"groupName"`
+	InvitedBy string     `json:"invitedBy"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// ItineraryItem mapeia a tabela itinerary_items: uma atividade do roteiro,
+// opcionalmente ligada a um destino do grupo. StartsAt e EndsAt são horários
+// locais da viagem (ver LocalDateTime).
+type ItineraryItem struct {
+	ID              int           `json:"id"`
+	TravelGroupID   int           `json:"groupId"`
+	DestinationID   *int          `json:"destinationId"`
+	DestinationName *string       `json:"destinationName"`
+	Activity        string        `json:"activity"`
+	StartsAt        LocalDateTime `json:"startsAt"`
+	EndsAt          LocalDateTime `json:"endsAt"`
+	Notes           string        `json:"notes"`
+	EstimatedCost   *Money        `json:"estimatedCost"`
+	Currency        string        `json:"currency"`
+	CreatedBy       int           `json:"createdBy"`
+	CreatedAt       time.Time     `json:"createdAt"`
+	// OverlapsWith lista os IDs dos itens cujo horário se sobrepõe a este.
+	OverlapsWith []int `json:"overlapsWith"`
+}
+
+// ItineraryItemRequest é o payload para criar ou alterar um item do roteiro.
+// Sobreposição de horários é rejeitada, a menos que AllowOverlap seja true
+// (por exemplo, quando o grupo se divide em atividades paralelas).
+type ItineraryItemRequest struct {
+	DestinationID *int           `json:"destinationId"`
+	Activity      string         `json:"activity"`
+	StartsAt      *LocalDateTime `json:"startsAt"`
+	EndsAt        *LocalDateTime `json:"endsAt"`
+	Notes         string         `json:"notes"`
+	EstimatedCost *Money         `json:"estimatedCost"`
+	Currency      string         `json:"currency"`
+	AllowOverlap  bool           `json:"allowOverlap"`
+}
+
+// ItineraryDay agrupa os itens de um dia da viagem. Itens que atravessam a
+// meia-noite aparecem em todos os dias que ocupam.
+type ItineraryDay struct {
+	Date      string          `json:"date"`
+	DayNumber int             `json:"dayNumber"`
+	Items     []ItineraryItem `json:"items"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// LocalDateTimeLayout é o formato de data e hora local usado na API ("2025-07-10T09:30").
+const LocalDateTimeLayout = "2006-01-02T15:04"
+
+// LocalDateTime é uma data e hora "de relógio" no local da viagem, sem fuso horário.
+// O roteiro é pensado no horário do destino: 09:00 em Paris continua sendo 09:00
+// para qualquer membro, independente de onde ele esteja. O valor é mantido em UTC
+// apenas como suporte e gravado em colunas timestamp (sem time zone).
+type LocalDateTime struct {
+	time.Time
+}
+
+// NewLocalDateTime cria um LocalDateTime com a data e hora de relógio de t.
+func NewLocalDateTime(t time.Time) LocalDateTime {
+	return LocalDateTime{time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)}
+}
+
+// ParseLocalDateTime aceita "2025-07-10T09:30" ou "2025-07-10T09:30:00".
+func ParseLocalDateTime(s string) (LocalDateTime, error) {
+	s = strings.TrimSpace(s)
+	for _, layout := range []string{LocalDateTimeLayout, "2006-01-02T15:04:05"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return LocalDateTime{t}, nil
+		}
+	}
+	return LocalDateTime{}, fmt.Errorf("data e hora inválida %q (use YYYY-MM-DDTHH:MM)", s)
+}
+
+// Date retorna a data (à meia-noite) do valor.
+func (d LocalDateTime) Date() time.Time {
+	return time.Date(d.Year(), d.Month(), d.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// String formata o valor no layout da API.
+func (d LocalDateTime) String() string {
+	return d.Format(LocalDateTimeLayout)
+}
+
+// MarshalJSON escreve o valor como string JSON sem fuso horário.
+func (d LocalDateTime) MarshalJSON() ([]byte, error) {
+	return []byte(`"` + d.String() + `"`), nil
+}
+
+// UnmarshalJSON lê uma string JSON no layout da API.
+func (d *LocalDateTime) UnmarshalJSON(data []byte) error {
+	s := string(data)
+	if s == "null" {
+		return nil
+	}
+	parsed, err := ParseLocalDateTime(strings.Trim(s, `"`))
+	if err != nil {
+		return err
+	}
+	*d = parsed
+	return nil
+}
+
+// Value implementa driver.Valuer enviando o texto sem fuso, para que o banco
+// não faça nenhuma conversão.
+func (d LocalDateTime) Value() (driver.Value, error) {
+	return d.Format("2006-01-02 15:04:05"), nil
+}
+
+// Scan implementa sql.Scanner para colunas timestamp.
+func (d *LocalDateTime) Scan(src any) error {
+	switch v := src.(type) {
+	case time.Time:
+		*d = NewLocalDateTime(v)
+		return nil
+	case nil:
+		*d = LocalDateTime{}
+		return nil
+	default:
+		return fmt.Errorf("tipo não suportado para LocalDateTime: %T", src)
+	}
+}
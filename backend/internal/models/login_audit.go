@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// Motivos registrados na auditoria de login.
+const (
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureUnknownUser   = "unknown_user"
+	LoginFailureLocked        = "locked"
+)
+
+// ClientInfo identifica de onde veio uma requisição de login.
+type ClientInfo struct {
+	IP        string
+	UserAgent string
+}
+
+// LoginAuditEntry é uma tentativa de login malsucedida, listada em GET /profile/failed-logins.
+type LoginAuditEntry struct {
+	ID        int       `json:"id"`
+	UserID    *int      `json:"-"`
+	Email     string    `json:"-"`
+	IP        string    `json:"ip"`
+	UserAgent string    `json:"userAgent"`
+	Reason    string    `json:"reason"`
+	CreatedAt time.Time `json:"createdAt"`
+}
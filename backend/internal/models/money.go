@@ -0,0 +1,156 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Money representa um valor monetário em unidades menores (centavos), sempre
+// com 2 casas decimais. Evita os erros de arredondamento do float64: no JSON e
+// no banco (decimal) o valor trafega como número decimal exato, ex.: 45.50.
+type Money int64
+
+// MaxMoney é o maior valor que cabe nas colunas decimal(10,2) do banco
+// (99.999.999,99); valores informados pelo usuário acima dele são rejeitados
+// na validação, antes de chegar ao banco.
+const MaxMoney Money = 99_999_999_99
+
+// DefaultCurrency é a moeda usada quando o grupo ou a despesa não informam uma.
+const DefaultCurrency = "BRL"
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// IsValidCurrency verifica se o código está no formato ISO 4217 (três letras maiúsculas).
+func IsValidCurrency(code string) bool {
+	return currencyCodePattern.MatchString(code)
+}
+
+// ParseMoney converte um decimal em texto ("12", "12.3", "-0.45") para Money.
+// Valores com mais de 2 casas decimais são rejeitados em vez de arredondados.
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("valor monetário vazio")
+	}
+
+	negative := false
+	switch s[0] {
+	case '-':
+		negative = true
+		s = s[1:]
+	case '+':
+		s = s[1:]
+	}
+
+	intPart, fracPart, _ := strings.Cut(s, ".")
+	// Zeros à direita ("1.2300") não alteram o valor.
+	fracPart = strings.TrimRight(fracPart, "0")
+	if (intPart == "" && fracPart == "") || !isDigits(intPart) || !isDigits(fracPart) {
+		return 0, fmt.Errorf("valor monetário inválido: %q", s)
+	}
+	if len(fracPart) > 2 {
+		return 0, fmt.Errorf("valor monetário com mais de 2 casas decimais: %q", s)
+	}
+	fracPart += strings.Repeat("0", 2-len(fracPart))
+	if intPart == "" {
+		intPart = "0"
+	}
+
+	units, err := strconv.ParseInt(intPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor monetário inválido: %q", s)
+	}
+	cents, err := strconv.ParseInt(fracPart, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("valor monetário inválido: %q", s)
+	}
+
+	if units > (math.MaxInt64-cents)/100 {
+		return 0, fmt.Errorf("valor monetário fora do limite: %q", s)
+	}
+	m := Money(units*100 + cents)
+	if negative {
+		m = -m
+	}
+	return m, nil
+}
+
+func isDigits(s string) bool {
+	for _, c := range s {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// String formata o valor com duas casas decimais.
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// MarshalJSON escreve o valor como número JSON com duas casas decimais.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON aceita tanto número quanto string JSON ("45.50").
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "null" {
+		return nil
+	}
+	v, err := ParseMoney(s)
+	if err != nil {
+		return err
+	}
+	*m = v
+	return nil
+}
+
+// Value implementa driver.Valuer, enviando o valor como decimal em texto.
+func (m Money) Value() (driver.Value, error) {
+	return m.String(), nil
+}
+
+// Scan implementa sql.Scanner para colunas decimal/numeric.
+func (m *Money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+		return nil
+	case []byte:
+		parsed, err := ParseMoney(string(v))
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case string:
+		parsed, err := ParseMoney(v)
+		if err != nil {
+			return err
+		}
+		*m = parsed
+		return nil
+	case int64:
+		if v > math.MaxInt64/100 || v < math.MinInt64/100 {
+			return fmt.Errorf("valor monetário fora do limite: %d", v)
+		}
+		*m = Money(v * 100)
+		return nil
+	default:
+		return fmt.Errorf("tipo não suportado para Money: %T", src)
+	}
+}
@@ -0,0 +1,147 @@
+package models
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+)
+
+func TestParseMoney(t *testing.T) {
+	tests := []struct {
+		input string
+		want  Money
+	}{
+		{"12", 1200},
+		{"12.3", 1230},
+		{"12.30", 1230},
+		{"12.340000", 1234},
+		{"0.01", 1},
+		{".5", 50},
+		{"7.", 700},
+		{"+3.10", 310},
+		{" 45.50 ", 4550},
+		{"-0.45", -45},
+		{"-12", -1200},
+		{"0", 0},
+		{"-0", 0},
+		{"92233720368547758.07", 9223372036854775807},
+		{"-92233720368547758.07", -9223372036854775807},
+	}
+	for _, tt := range tests {
+		got, err := ParseMoney(tt.input)
+		if err != nil {
+			t.Errorf("ParseMoney(%q): erro inesperado: %v", tt.input, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseMoney(%q) = %d, esperado %d", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestParseMoneyRejects(t *testing.T) {
+	inputs := []string{
+		"",
+		"   ",
+		".",
+		"-",
+		"+",
+		"--1",
+		"+-1",
+		"1.234",  // terceira casa decimal
+		"0.001",  // terceira casa decimal
+		"-1.005", // terceira casa decimal, negativo
+		"1,50",
+		"1.2.3",
+		"1e3",
+		"abc",
+		"12a",
+		"0x10",
+		" - 1",
+		"92233720368547758.08",   // um centavo acima de MaxInt64
+		"-92233720368547758.08",  // idem, negativo
+		"92233720368547758080",   // cabe em int64 só antes de virar centavos
+		"99999999999999999999.9", // não cabe nem em int64
+	}
+	for _, input := range inputs {
+		if got, err := ParseMoney(input); err == nil {
+			t.Errorf("ParseMoney(%q) = %d, esperado erro", input, got)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		value Money
+		want  string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{4550, "45.50"},
+		{-123456, "-1234.56"},
+	}
+	for _, tt := range tests {
+		if got := tt.value.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, esperado %q", tt.value, got, tt.want)
+		}
+		back, err := ParseMoney(tt.want)
+		if err != nil || back != tt.value {
+			t.Errorf("ParseMoney(%q) = %d, %v; esperado %d", tt.want, back, err, tt.value)
+		}
+	}
+}
+
+func TestMoneyJSON(t *testing.T) {
+	var v struct {
+		A Money  `json:"a"`
+		B Money  `json:"b"`
+		C *Money `json:"c"`
+	}
+	if err := json.Unmarshal([]byte(`{"a": 45.5, "b": "-0.45", "c": null}`), &v); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if v.A != 4550 || v.B != -45 || v.C != nil {
+		t.Fatalf("valores = %d, %d, %v", v.A, v.B, v.C)
+	}
+
+	out, _ := json.Marshal(v)
+	if string(out) != `{"a":45.50,"b":-0.45,"c":null}` {
+		t.Fatalf("JSON = %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"a": 0.101}`), &v); err == nil {
+		t.Fatal("esperado erro para três casas decimais")
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	var m Money
+	for _, src := range []any{[]byte("12.34"), "12.34"} {
+		if err := m.Scan(src); err != nil || m != 1234 {
+			t.Fatalf("Scan(%v) = %d, %v", src, m, err)
+		}
+	}
+	if err := m.Scan(int64(3)); err != nil || m != 300 {
+		t.Fatalf("Scan(int64) = %d, %v", m, err)
+	}
+	if err := m.Scan(nil); err != nil || m != 0 {
+		t.Fatalf("Scan(nil) = %d, %v", m, err)
+	}
+	if err := m.Scan(1.5); err == nil {
+		t.Fatal("esperado erro para float64")
+	}
+	for _, src := range []int64{math.MaxInt64 / 10, math.MinInt64 / 10} {
+		if err := m.Scan(src); err == nil {
+			t.Fatalf("Scan(%d) = %d, esperado erro de limite", src, m)
+		}
+	}
+}
+
+func TestIsValidCurrency(t *testing.T) {
+	for code, want := range map[string]bool{"BRL": true, "EUR": true, "brl": false, "BR": false, "BRLX": false, "": false, "B1L": false} {
+		if got := IsValidCurrency(code); got != want {
+			t.Errorf("IsValidCurrency(%q) = %v, esperado %v", code, got, want)
+		}
+	}
+}
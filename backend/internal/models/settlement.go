@@ -0,0 +1,56 @@
+package models
+
+import "time"
+
+// MemberBalance é a posição financeira de um membro no grupo.
+// Balance positivo significa que o membro tem a receber; negativo, a pagar.
+// Sent e Received são os pagamentos de acerto já feitos e recebidos, que
+// entram no saldo: Balance = Paid - Owed + Sent - Received.
+// Os valores estão na moeda base do grupo (Currency).
+type MemberBalance struct {
+	UserID   int    `json:"userId"`
+	Name     string `json:"name"`
+	Paid     Money  `json:"paid"`
+	Owed     Money  `json:"owed"`
+	Sent     Money  `json:"sent"`
+	Received Money  `json:"received"`
+	Balance  Money  `json:"balance"`
+	Currency string `json:"currency"`
+	// FormerMember indica que o usuário já saiu do grupo; o saldo dele
+	// continua no histórico.
+	FormerMember bool `json:"formerMember,omitempty"`
+}
+
+// Settlement é uma transferência sugerida para quitar as dívidas do grupo.
+type Settlement struct {
+	FromUserID int    `json:"fromUserId"`
+	FromName   string `json:"fromName"`
+	ToUserID   int    `json:"toUserId"`
+	ToName     string `json:"toName"`
+	Amount     Money  `json:"amount"`
+	Currency   string `json:"currency"`
+}
+
+// SettlementPayment é um pagamento já feito entre dois membros para acertar as
+// contas do grupo, na moeda em que foi feito.
+type SettlementPayment struct {
+	ID            int       `json:"id"`
+	TravelGroupID int       `json:"groupId"`
+	FromUserID    int       `json:"fromUserId"`
+	FromName      string    `json:"fromName"`
+	ToUserID      int       `json:"toUserId"`
+	ToName        string    `json:"toName"`
+	Amount        Money     `json:"amount"`
+	Currency      string    `json:"currency"`
+	CreatedBy     *int      `json:"createdBy"`
+	CreatedAt     time.Time `json:"createdAt"`
+}
+
+// SettlementPaymentRequest é o corpo de POST /groups/{id}/payments. Sem
+// currency, vale a moeda base do grupo.
+type SettlementPaymentRequest struct {
+	FromUserID int    `json:"fromUserId"`
+	ToUserID   int    `json:"toUserId"`
+	Amount     Money  `json:"amount"`
+	Currency   string `json:"currency"`
+}
@@ -1,25 +1,38 @@
 package models
 
 import (
+	"encoding/json"
 	"time"
 )
 
 // TravelGroup é a estrutura principal que mapeia a tabela travel_groups.
 type TravelGroup struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	CreatorID   int       `json:"creator_id"`
-	Description string    `json:"description"`
-	StartDate   time.Time `json:"start_date"`
-	EndDate     time.Time `json:"end_date"`
-	CreatedAt   time.Time `json:"created_at"`
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	CreatorID    int       `json:"creator_id"`
+	Description  string    `json:"description"`
+	BaseCurrency string    `json:"base_currency"`
+	StartDate    time.Time `json:"start_date"`
+	EndDate      time.Time `json:"end_date"`
+	CreatedAt    time.Time `json:"created_at"`
 }
 
 type TravelGroupCreateRequest struct {
-	Name        string `json:"name"`
-	Description string `json:"description"`
-	StartDate   string `json:"start_date"`
-	EndDate     string `json:"end_date"`
+	Name         string `json:"name"`
+	Description  string `json:"description"`
+	BaseCurrency string `json:"base_currency"`
+	StartDate    string `json:"start_date"`
+	EndDate      string `json:"end_date"`
+}
+
+// TravelGroupUpdateRequest é o payload de PATCH /groups/{id}: apenas os campos
+// enviados são alterados.
+type TravelGroupUpdateRequest struct {
+	Name         *string `json:"name"`
+	Description  *string `json:"description"`
+	BaseCurrency *string `json:"base_currency"`
+	StartDate    *string `json:"start_date"`
+	EndDate      *string `json:"end_date"`
 }
 
 // TravelGroupListItem é a estrutura simplificada para a tela de listagem.
@@ -37,22 +50,44 @@ type TravelGroupListItem struct {
 
 // TravelGroupDetails representa os dados básicos de um grupo para a página de visualização
 type TravelGroupDetails struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Description string    `json:"description"`
-	StartDate   time.Time `json:"startDate"`
-	EndDate     time.Time `json:"endDate"`
-	CreatorID   int       `json:"creatorId"`
-	CreatorName string    `json:"organizerName"`
-	MemberCount int       `json:"memberCount"`
+	ID           int       `json:"id"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	BaseCurrency string    `json:"baseCurrency"`
+	StartDate    time.Time `json:"startDate"`
+	EndDate      time.Time `json:"endDate"`
+	CreatorID    int       `json:"creatorId"`
+	CreatorName  string    `json:"organizerName"`
+	MemberCount  int       `json:"memberCount"`
+	Role         string    `json:"role"` // papel de quem fez a requisição
 }
 
+// Papéis de um membro no grupo (group_members.role).
+const (
+	RoleOrganizer   = "organizer"    // dono do grupo (travel_groups.creator_id), único por grupo
+	RoleCoOrganizer = "co_organizer" // administra o grupo junto com o organizador
+	RoleParticipant = "participant"  // contribui com destinos, votações, despesas e roteiro
+	RoleViewer      = "viewer"       // apenas acompanha o grupo
+)
+
 // GroupMemberDTO representa um item na lista de membros (para a aba Membros)
 type GroupMemberDTO struct {
 	UserID int    `json:"userId"`
 	Name   string `json:"name"`
 	Email  string `json:"email"`
 	Role   string `json:"role"`
+	// LeftAt só vem preenchido para ex-membros.
+	LeftAt *time.Time `json:"leftAt,omitempty"`
+}
+
+// MemberRoleUpdateRequest é o payload de PATCH /groups/{id}/members/{userId}.
+type MemberRoleUpdateRequest struct {
+	Role string `json:"role"`
+}
+
+// OwnershipTransferRequest é o payload de POST /groups/{id}/transfer-ownership.
+type OwnershipTransferRequest struct {
+	UserID int `json:"userId"`
 }
 
 // DestinationDTO representa um destino sugerido para um grupo
@@ -61,31 +96,47 @@ type DestinationDTO struct {
 	Name        string `json:"name"`
 	Location    string `json:"location"`
 	Description string `json:"description"`
+	CreatedBy   *int   `json:"createdBy"`
 }
 
 type VotingDTO struct {
-	ID         int       `json:"id"`
-	Question   string    `json:"question"`
-	Options    []string  `json:"options"`
-	TotalVotes int       `json:"totalVotes"`
-	UserVote   *string   `json:"userVote"`
-	CreatedAt  time.Time `json:"createdAt"`
-	// Status (Aberto/Fechado) pode ser inferido pelo backend ou adicionado aqui.
+	ID            int        `json:"id"`
+	Question      string     `json:"question"`
+	Options       []string   `json:"options"`
+	Type          string     `json:"type"`
+	MaxSelections *int       `json:"maxSelections"`
+	TotalVotes    int        `json:"totalVotes"`
+	UserVote      *string    `json:"userVote"`
+	UserVotes     []string   `json:"userVotes"`
+	Status        string     `json:"status"`
+	CreatedBy     *int       `json:"createdBy"`
+	ClosesAt      *time.Time `json:"closesAt"`
+	ClosedAt      *time.Time `json:"closedAt"`
+	CreatedAt     time.Time  `json:"createdAt"`
 }
 
-// ExpenseDTO representa uma despesa do grupo
+// ExpenseDTO representa uma despesa do grupo.
+// Amount está na moeda da despesa; ConvertedAmount, na moeda base do grupo
+// (nil se não houver taxa de câmbio cadastrada para o par).
 type ExpenseDTO struct {
-	ID                int       `json:"id"`
-	Description       string    `json:"description"`
-	Amount            float64   `json:"amount"`
-	PayerID           int       `json:"payerId"`
-	PayerName         string    `json:"payerName"`
-	ParticipantsIDs   []int     `json:"participantsIds"`
-	ParticipantsCount int       `json:"participantsCount"`
-	CreatedAt         time.Time `json:"createdAt"`
+	ID                int            `json:"id"`
+	Description       string         `json:"description"`
+	Amount            Money          `json:"amount"`
+	Currency          string         `json:"currency"`
+	ConvertedAmount   *Money         `json:"convertedAmount"`
+	BaseCurrency      string         `json:"baseCurrency"`
+	PayerID           int            `json:"payerId"`
+	PayerName         string         `json:"payerName"`
+	CreatedBy         *int           `json:"createdBy"`
+	SplitMode         string         `json:"splitMode"`
+	Category          string         `json:"category"`
+	ParticipantsIDs   []int          `json:"participantsIds"`
+	ParticipantsCount int            `json:"participantsCount"`
+	Shares            []ExpenseShare `json:"shares"`
+	CreatedAt         time.Time      `json:"createdAt"`
 }
 
-// DestinationCreateRequest é o payload para criar um novo destino
+// DestinationCreateRequest é o payload para criar um novo destino (e para alterá-lo, via PUT)
 type DestinationCreateRequest struct {
 	Name        string `json:"name"`
 	Location    string `json:"location"`
@@ -93,9 +144,26 @@ type DestinationCreateRequest struct {
 }
 
 // VotingCreateRequest é o payload para criar uma nova votação
+// ClosesAt é opcional; sem ele a votação fica aberta até ser encerrada manualmente.
+// Type padrão é "single"; MaxSelections é obrigatório apenas em "multiple".
 type VotingCreateRequest struct {
-	Question string   `json:"question"`
-	Options  []string `json:"options"`
+	Question      string     `json:"question"`
+	Options       []string   `json:"options"`
+	Type          string     `json:"type"`
+	MaxSelections *int       `json:"maxSelections"`
+	ClosesAt      *time.Time `json:"closesAt"`
+}
+
+// VotingUpdateRequest é o payload de PATCH /votings/{id}: apenas os campos
+// enviados são alterados. Options, Type e MaxSelections só podem mudar enquanto
+// ninguém votou. ClearClosesAt remove o prazo de encerramento.
+type VotingUpdateRequest struct {
+	Question      *string    `json:"question"`
+	Options       []string   `json:"options"`
+	Type          *string    `json:"type"`
+	MaxSelections *int       `json:"maxSelections"`
+	ClosesAt      *time.Time `json:"closesAt"`
+	ClearClosesAt bool       `json:"clearClosesAt"`
 }
 
 // Destination Model (para passar para o repository se necessário)
@@ -105,22 +173,54 @@ type Destination struct {
 	Name          string
 	Location      string
 	Description   string
+	CreatedBy     *int
+}
+
+// Modos de divisão de uma despesa entre os participantes.
+const (
+	SplitModeEqual      = "equal"      // partes iguais entre ParticipantIDs
+	SplitModeExact      = "exact"      // Value é o valor exato de cada participante
+	SplitModePercentage = "percentage" // Value é o percentual de cada participante (soma 100)
+	SplitModeShares     = "shares"     // Value é o peso (cotas) de cada participante
+)
+
+// ExpenseSplitInput é a parte de um participante informada na criação da despesa.
+// O significado de Value depende do SplitMode.
+type ExpenseSplitInput struct {
+	UserID int         `json:"userId"`
+	Value  json.Number `json:"value"`
+}
+
+// ExpenseShare é o valor que cabe a cada participante (expense_participants.share_amount).
+type ExpenseShare struct {
+	UserID int   `json:"userId"`
+	Amount Money `json:"amount"`
 }
 
-// ExpenseCreateRequest é o payload para criar uma nova despesa
+// ExpenseCreateRequest é o payload para criar uma nova despesa (e para alterá-la, via PUT).
+// Para SplitMode "equal" (padrão) usa ParticipantIDs; nos demais modos usa Splits.
 type ExpenseCreateRequest struct {
-	Description    string  `json:"description"`
-	Amount         float64 `json:"amount"`
-	PayerID        int     `json:"payerId"`
-	ParticipantIDs []int   `json:"participantIds"`
+	Description    string              `json:"description"`
+	Amount         Money               `json:"amount"`
+	Currency       string              `json:"currency"`
+	PayerID        int                 `json:"payerId"`
+	SplitMode      string              `json:"splitMode"`
+	Category       string              `json:"category"` // opcional; padrão "other"
+	ParticipantIDs []int               `json:"participantIds"`
+	Splits         []ExpenseSplitInput `json:"splits"`
 }
 
 // Expense Model (para uso interno no Repository)
 type Expense struct {
-	ID             int
-	TravelGroupID  int
-	Description    string
-	Amount         float64
-	PayerID        int
-	ParticipantIDs []int
+	ID             int            `json:"id"`
+	TravelGroupID  int            `json:"groupId"`
+	Description    string         `json:"description"`
+	Amount         Money          `json:"amount"`
+	Currency       string         `json:"currency"`
+	PayerID        int            `json:"payerId"`
+	CreatedBy      *int           `json:"createdBy"`
+	SplitMode      string         `json:"splitMode"`
+	Category       string         `json:"category"`
+	ParticipantIDs []int          `json:"participantsIds"`
+	Shares         []ExpenseShare `json:"shares"`
 }
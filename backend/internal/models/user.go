@@ -1,12 +1,24 @@
 package models
 
+import "time"
+
 // User representa o modelo de dados de um usuário.
 type User struct {
-	ID           int    `json:"id"`
-	Name         string `json:"name"`
-	Email        string `json:"email"`
-	Password     string `json:"password"`
-	PasswordHash string `json:"-"` // O hash não deve ser exposto no JSON
+	ID              int        `json:"id"`
+	Name            string     `json:"name"`
+	Email           string     `json:"email"`
+	Password        string     `json:"password"`
+	PasswordHash    string     `json:"-"` // O hash não deve ser exposto no JSON
+	EmailVerifiedAt *time.Time `json:"-"`
+	// Locale é o idioma preferido (pt-BR, en); vazio segue o Accept-Language.
+	Locale string `json:"-"`
+}
+
+// UserRegisterRequest corresponde ao payload de POST /auth/register.
+type UserRegisterRequest struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
 }
 
 // UserLogin representa a requisição de login.
@@ -17,11 +29,31 @@ type UserLogin struct {
 
 // UserProfileResponse corresponde ao DTO retornado por GET /profile
 type UserProfileResponse struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name          string  `json:"name"`
+	Email         string  `json:"email"`
+	EmailVerified bool    `json:"emailVerified"`
+	Locale        *string `json:"locale"`
 }
 
-// UserProfileUpdateRequest corresponde ao payload de PATCH /profile
+// UserProfileUpdateRequest corresponde ao payload de PATCH /profile. Campos
+// omitidos não são alterados; locale "" remove a preferência de idioma.
 type UserProfileUpdateRequest struct {
-	Name string `json:"name"`
+	Name   *string `json:"name"`
+	Locale *string `json:"locale"`
+}
+
+// EmailTokenRequest corresponde ao payload de POST /auth/verify.
+type EmailTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// ForgotPasswordRequest corresponde ao payload de POST /auth/forgot-password.
+type ForgotPasswordRequest struct {
+	Email string `json:"email"`
+}
+
+// ResetPasswordRequest corresponde ao payload de POST /auth/reset-password.
+type ResetPasswordRequest struct {
+	Token    string `json:"token"`
+	Password string `json:"password"`
 }
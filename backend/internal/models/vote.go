@@ -1,13 +1,102 @@
 package models
 
-// VoteRequest é o payload para registrar um voto
+import (
+	"time"
+)
+
+// Status possíveis de uma votação.
+const (
+	VotingStatusOpen   = "open"
+	VotingStatusClosed = "closed"
+)
+
+// Tipos de votação.
+const (
+	VotingTypeSingle   = "single"   // uma opção por eleitor (maioria simples)
+	VotingTypeMultiple = "multiple" // até MaxSelections opções por eleitor
+	VotingTypeApproval = "approval" // o eleitor aprova quantas opções quiser
+	VotingTypeRanked   = "ranked"   // ranking de preferência, apurado por segundo turno instantâneo
+)
+
+// IsValidVotingType verifica se o tipo de votação é suportado.
+func IsValidVotingType(t string) bool {
+	switch t {
+	case VotingTypeSingle, VotingTypeMultiple, VotingTypeApproval, VotingTypeRanked:
+		return true
+	}
+	return false
+}
+
+// VoteRequest é o payload para registrar um voto.
+// Votações "single" usam SelectedOption; as demais usam SelectedOptions
+// (em "ranked", na ordem de preferência).
 type VoteRequest struct {
-	SelectedOption string `json:"selectedOption"`
+	SelectedOption  string   `json:"selectedOption"`
+	SelectedOptions []string `json:"selectedOptions"`
 }
 
 // Vote Model (para registro interno)
+// SelectedOption guarda a primeira escolha; SelectedOptions, a cédula completa.
 type Vote struct {
-	VotingID       int
-	UserID         int
-	SelectedOption string
+	VotingID        int
+	UserID          int
+	SelectedOption  string
+	SelectedOptions []string
+}
+
+// Voting Model (para uso interno): dados da votação necessários às regras de negócio.
+type Voting struct {
+	ID            int
+	TravelGroupID int
+	Question      string
+	Options       []string
+	Type          string
+	MaxSelections *int
+	CreatedBy     *int
+	ClosesAt      *time.Time
+	ClosedAt      *time.Time
+	CreatedAt     time.Time
+}
+
+// Status informa se a votação está aberta ou encerrada no instante now.
+// Ela é encerrada manualmente (ClosedAt) ou ao atingir o prazo (ClosesAt).
+func (v *Voting) Status(now time.Time) string {
+	if v.ClosedAt != nil || (v.ClosesAt != nil && !now.Before(*v.ClosesAt)) {
+		return VotingStatusClosed
+	}
+	return VotingStatusOpen
+}
+
+// OptionResult é a apuração de uma opção da votação.
+type OptionResult struct {
+	Option     string  `json:"option"`
+	Votes      int     `json:"votes"`
+	Percentage float64 `json:"percentage"`
+}
+
+// RunoffRound é uma rodada da apuração por segundo turno instantâneo (ranked).
+// Options traz os votos de primeira preferência entre as opções ainda na disputa.
+type RunoffRound struct {
+	Round      int            `json:"round"`
+	Options    []OptionResult `json:"options"`
+	Eliminated []string       `json:"eliminated"`
+}
+
+// VotingResults é o resultado de GET /votings/{id}/results.
+// TotalVotes é o número de eleitores; em votações com várias escolhas a soma dos
+// votos das opções pode ser maior, e Percentage é relativo ao total de eleitores.
+// Em "ranked", Options traz a primeira preferência e Rounds, cada rodada.
+// Winners tem mais de um item quando há empate (IsTie).
+type VotingResults struct {
+	VotingID   int            `json:"votingId"`
+	Question   string         `json:"question"`
+	Type       string         `json:"type"`
+	Status     string         `json:"status"`
+	ClosesAt   *time.Time     `json:"closesAt"`
+	ClosedAt   *time.Time     `json:"closedAt"`
+	TotalVotes int            `json:"totalVotes"`
+	Options    []OptionResult `json:"options"`
+	Rounds     []RunoffRound  `json:"rounds,omitempty"`
+	Winners    []string       `json:"winners"`
+	IsTie      bool           `json:"isTie"`
 }
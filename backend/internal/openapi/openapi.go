@@ -0,0 +1,237 @@
+// Package openapi gera o documento OpenAPI 3 da API a partir da tabela de rotas
+// (router.Route) e dos tipos Go dos corpos JSON, e oferece um middleware que
+// confere requisições e respostas contra esse documento.
+package openapi
+
+import (
+	"net/http"
+	"project_lab/internal/problem"
+	"project_lab/internal/router"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Version é a versão da especificação OpenAPI gerada.
+const Version = "3.0.3"
+
+// Document é a raiz do documento OpenAPI.
+type Document struct {
+	OpenAPI    string              `json:"openapi"`
+	Info       Info                `json:"info"`
+	Servers    []Server            `json:"servers,omitempty"`
+	Tags       []Tag               `json:"tags,omitempty"`
+	Paths      map[string]PathItem `json:"paths"`
+	Components Components          `json:"components"`
+}
+
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+type Server struct {
+	URL         string `json:"url"`
+	Description string `json:"description,omitempty"`
+}
+
+type Tag struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// PathItem associa o método HTTP (em minúsculas) à operação.
+type PathItem map[string]*Operation
+
+type Operation struct {
+	Tags        []string              `json:"tags,omitempty"`
+	Summary     string                `json:"summary,omitempty"`
+	OperationID string                `json:"operationId"`
+	Parameters  []Parameter           `json:"parameters,omitempty"`
+	RequestBody *RequestBody          `json:"requestBody,omitempty"`
+	Responses   map[string]*Response  `json:"responses"`
+	Security    []map[string][]string `json:"security,omitempty"`
+}
+
+type Parameter struct {
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required"`
+	Schema   *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+	Schemas         map[string]*Schema        `json:"schemas"`
+	SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+	Type         string `json:"type"`
+	Scheme       string `json:"scheme,omitempty"`
+	BearerFormat string `json:"bearerFormat,omitempty"`
+}
+
+// Schema é o subconjunto do Schema Object usado pela API.
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Example              any                `json:"example,omitempty"`
+}
+
+// bearerAuth é o nome do esquema de segurança das rotas com Auth.
+const bearerAuth = "bearerAuth"
+
+// Options complementa as rotas com os dados do documento que não vêm delas.
+type Options struct {
+	Info    Info
+	Servers []Server
+	Tags    []Tag
+	// Types descreve os tipos com serialização JSON própria (MarshalJSON),
+	// que a reflexão não consegue inferir.
+	Types map[reflect.Type]*Schema
+}
+
+var pathParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// Generate monta o documento com uma operação por rota. Os parâmetros de rota
+// terminados em "Id" são inteiros (o router os converte); os demais, texto.
+// Toda operação documenta as respostas de erro no formato de problem.
+func Generate(opts Options, routes []router.Route) *Document {
+	g := newSchemaGenerator(opts.Types)
+	problemSchema := g.schema(reflect.TypeOf(problem.Problem{}), true)
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    opts.Info,
+		Servers: opts.Servers,
+		Tags:    opts.Tags,
+		Paths:   make(map[string]PathItem),
+		Components: Components{
+			Schemas: g.schemas,
+			SecuritySchemes: map[string]SecurityScheme{
+				bearerAuth: {Type: "http", Scheme: "bearer", BearerFormat: "JWT"},
+			},
+		},
+	}
+
+	for _, route := range routes {
+		op := &Operation{
+			Summary:     route.Summary,
+			OperationID: operationID(route),
+			Responses:   make(map[string]*Response),
+		}
+		if route.Tag != "" {
+			op.Tags = []string{route.Tag}
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(route.Pattern, -1) {
+			schema := &Schema{Type: "string"}
+			if strings.HasSuffix(match[1], "Id") {
+				schema = &Schema{Type: "integer", Format: "int64"}
+			}
+			op.Parameters = append(op.Parameters, Parameter{Name: match[1], In: "path", Required: true, Schema: schema})
+		}
+
+		if route.Request != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{requestContentType(route): {Schema: g.schema(reflect.TypeOf(route.Request), false)}},
+			}
+		}
+
+		status := successStatus(route)
+		success := &Response{Description: http.StatusText(status)}
+		switch {
+		case route.Response != nil:
+			success.Content = map[string]MediaType{contentType(route): {Schema: g.schema(reflect.TypeOf(route.Response), true)}}
+		case route.ContentType != "":
+			success.Content = map[string]MediaType{route.ContentType: {Schema: &Schema{Type: "string"}}}
+		}
+		op.Responses[strconv.Itoa(status)] = success
+
+		problemResponse := func(description string) *Response {
+			return &Response{Description: description, Content: map[string]MediaType{problem.ContentType: {Schema: problemSchema}}}
+		}
+		if route.Auth {
+			op.Security = []map[string][]string{{bearerAuth: {}}}
+			op.Responses["401"] = problemResponse(http.StatusText(http.StatusUnauthorized))
+		}
+		if route.RateLimit != "" {
+			op.Responses["429"] = problemResponse(http.StatusText(http.StatusTooManyRequests))
+		}
+		op.Responses["default"] = problemResponse("Erro (RFC 7807)")
+
+		item, ok := doc.Paths[route.Pattern]
+		if !ok {
+			item = make(PathItem)
+			doc.Paths[route.Pattern] = item
+		}
+		item[strings.ToLower(route.Method)] = op
+	}
+	return doc
+}
+
+func successStatus(route router.Route) int {
+	if route.Status != 0 {
+		return route.Status
+	}
+	return http.StatusOK
+}
+
+func requestContentType(route router.Route) string {
+	if route.RequestContentType != "" {
+		return route.RequestContentType
+	}
+	return "application/json"
+}
+
+func contentType(route router.Route) string {
+	if route.ContentType != "" {
+		return route.ContentType
+	}
+	return "application/json"
+}
+
+// operationID deriva um identificador estável do método e do caminho, ex.:
+// "GET /groups/{groupId}/expenses" vira "getGroupsByGroupIdExpenses".
+func operationID(route router.Route) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(route.Method))
+	for _, segment := range strings.Split(route.Pattern, "/") {
+		if segment == "" {
+			continue
+		}
+		if name, ok := strings.CutPrefix(segment, "{"); ok {
+			b.WriteString("By")
+			segment = strings.TrimSuffix(name, "}")
+		}
+		for _, word := range strings.FieldsFunc(segment, func(r rune) bool { return r == '-' || r == '.' || r == '_' }) {
+			b.WriteString(strings.ToUpper(word[:1]) + word[1:])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,115 @@
+package openapi
+
+import (
+	"encoding/json"
+	"mime/multipart"
+	"reflect"
+	"strings"
+	"time"
+)
+
+// schemaGenerator converte tipos Go em schemas, seguindo as regras do
+// encoding/json. Structs nomeadas viram componentes referenciados por $ref.
+type schemaGenerator struct {
+	types   map[reflect.Type]*Schema
+	schemas map[string]*Schema
+}
+
+func newSchemaGenerator(types map[reflect.Type]*Schema) *schemaGenerator {
+	builtin := map[reflect.Type]*Schema{
+		reflect.TypeOf(time.Time{}):       {Type: "string", Format: "date-time"},
+		reflect.TypeOf(json.Number("")):   {Type: "number"},
+		reflect.TypeOf(json.RawMessage{}): {},
+		// Arquivos enviados em multipart/form-data.
+		reflect.TypeOf(multipart.FileHeader{}): {Type: "string", Format: "binary"},
+	}
+	for t, s := range types {
+		builtin[t] = s
+	}
+	return &schemaGenerator{types: builtin, schemas: make(map[string]*Schema)}
+}
+
+// schema devolve o schema de t. Em respostas (response), os campos sem
+// omitempty são obrigatórios, pois o encoding/json sempre os escreve; em
+// requisições nenhum campo é marcado como obrigatório, já que as regras de
+// preenchimento ficam na validação dos handlers (422).
+func (g *schemaGenerator) schema(t reflect.Type, response bool) *Schema {
+	if s, ok := g.types[t]; ok {
+		copied := *s
+		return &copied
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return nullable(g.schema(t.Elem(), response))
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.object(t, response)
+		}
+		name := t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// Reserva o nome antes de descer nos campos, para tipos recursivos.
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.object(t, response)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem(), response)}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem(), response)}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	default:
+		// interface{} e afins: qualquer valor.
+		return &Schema{}
+	}
+}
+
+func (g *schemaGenerator) object(t reflect.Type, response bool) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	for i := range t.NumField() {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && options == "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		fieldSchema := g.schema(field.Type, response)
+		// Slices e maps nil são escritos como null.
+		if kind := field.Type.Kind(); kind == reflect.Slice || kind == reflect.Map {
+			fieldSchema = nullable(fieldSchema)
+		}
+		s.Properties[name] = fieldSchema
+
+		if response && !strings.Contains(options, "omitempty") {
+			s.Required = append(s.Required, name)
+		}
+	}
+	return s
+}
+
+// nullable aceita null além do schema. Em OpenAPI 3.0, "nullable" ao lado de
+// $ref é ignorado, por isso a referência vai dentro de allOf.
+func nullable(s *Schema) *Schema {
+	if s.Ref != "" {
+		return &Schema{AllOf: []*Schema{s}, Nullable: true}
+	}
+	s.Nullable = true
+	return s
+}
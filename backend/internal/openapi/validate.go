@@ -0,0 +1,259 @@
+package openapi
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"project_lab/internal/problem"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Validator devolve um middleware que confere cada requisição e resposta contra
+// o documento e chama report para cada divergência: corpo de requisição que
+// não segue o schema, status de sucesso não documentado, corpo de resposta
+// fora do schema ou erro que não esteja no formato de problem. As respostas
+// passam sem alteração; nos testes, report pode ser t.Error.
+func Validator(doc *Document, report func(error)) func(http.Handler) http.Handler {
+	mux := http.NewServeMux()
+	operations := make(map[string]*Operation)
+	for path, item := range doc.Paths {
+		for method, op := range item {
+			pattern := strings.ToUpper(method) + " " + path
+			mux.Handle(pattern, http.NotFoundHandler())
+			operations[pattern] = op
+		}
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			_, pattern := mux.Handler(r)
+			op := operations[pattern]
+			fail := func(format string, args ...any) {
+				report(fmt.Errorf("%s %s: %s", r.Method, r.URL.Path, fmt.Sprintf(format, args...)))
+			}
+
+			// Só corpos JSON são conferidos; uploads multipart passam direto.
+			if jsonBody, ok := requestJSONSchema(op); ok {
+				body, err := io.ReadAll(r.Body)
+				r.Body.Close()
+				r.Body = io.NopCloser(bytes.NewReader(body))
+				if err == nil {
+					if err := doc.validateBody(jsonBody, body); err != nil {
+						fail("requisição: %v", err)
+					}
+				}
+			}
+
+			rec := &recorder{ResponseWriter: w, status: http.StatusOK}
+			next.ServeHTTP(rec, r)
+
+			if rec.status >= 400 {
+				if err := doc.validateProblem(rec); err != nil {
+					fail("resposta %d: %v", rec.status, err)
+				}
+				return
+			}
+			if op == nil {
+				return
+			}
+			response, ok := op.Responses[strconv.Itoa(rec.status)]
+			if !ok {
+				fail("status %d não documentado", rec.status)
+				return
+			}
+			if err := doc.validateResponse(response, rec); err != nil {
+				fail("resposta %d: %v", rec.status, err)
+			}
+		})
+	}
+}
+
+// recorder repassa a resposta ao cliente e guarda uma cópia para a validação.
+type recorder struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *recorder) WriteHeader(status int) {
+	r.status = status
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *recorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
+
+// requestJSONSchema devolve o schema do corpo JSON da operação, se houver.
+func requestJSONSchema(op *Operation) (*Schema, bool) {
+	if op == nil || op.RequestBody == nil {
+		return nil, false
+	}
+	content, ok := op.RequestBody.Content["application/json"]
+	return content.Schema, ok
+}
+
+func (d *Document) validateProblem(rec *recorder) error {
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	if mediaType != problem.ContentType {
+		return fmt.Errorf("Content-Type %q, esperado %s", mediaType, problem.ContentType)
+	}
+	return d.validateBody(&Schema{Ref: "#/components/schemas/Problem"}, rec.body.Bytes())
+}
+
+func (d *Document) validateResponse(response *Response, rec *recorder) error {
+	if len(response.Content) == 0 {
+		if rec.body.Len() > 0 {
+			return fmt.Errorf("corpo não documentado")
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(rec.Header().Get("Content-Type"))
+	content, ok := response.Content[mediaType]
+	if !ok {
+		// Faixas como "image/*" ou "*/*" documentam respostas de tipo variável (downloads).
+		majorType, _, _ := strings.Cut(mediaType, "/")
+		content, ok = response.Content[majorType+"/*"]
+		if !ok {
+			content, ok = response.Content["*/*"]
+		}
+	}
+	if !ok {
+		return fmt.Errorf("Content-Type %q não documentado", mediaType)
+	}
+	if mediaType != "application/json" {
+		return nil
+	}
+	return d.validateBody(content.Schema, rec.body.Bytes())
+}
+
+func (d *Document) validateBody(schema *Schema, body []byte) error {
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	decoder.UseNumber()
+	var value any
+	if err := decoder.Decode(&value); err != nil {
+		return fmt.Errorf("JSON inválido: %v", err)
+	}
+	return d.validate(schema, value, "$")
+}
+
+// validate confere value (decodificado com UseNumber) contra o schema.
+func (d *Document) validate(s *Schema, value any, path string) error {
+	if s.Ref != "" {
+		name := strings.TrimPrefix(s.Ref, "#/components/schemas/")
+		resolved, ok := d.Components.Schemas[name]
+		if !ok {
+			return fmt.Errorf("%s: schema %s inexistente", path, name)
+		}
+		return d.validate(resolved, value, path)
+	}
+
+	if value == nil {
+		if s.Nullable || (s.Type == "" && len(s.AllOf) == 0) {
+			return nil
+		}
+		return fmt.Errorf("%s: null não permitido", path)
+	}
+	for _, sub := range s.AllOf {
+		if err := d.validate(sub, value, path); err != nil {
+			return err
+		}
+	}
+
+	switch s.Type {
+	case "object":
+		object, ok := value.(map[string]any)
+		if !ok {
+			return typeError(path, s.Type, value)
+		}
+		for _, name := range s.Required {
+			if _, ok := object[name]; !ok {
+				return fmt.Errorf("%s: campo obrigatório %q ausente", path, name)
+			}
+		}
+		keys := make([]string, 0, len(object))
+		for key := range object {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			property, ok := s.Properties[key]
+			if !ok {
+				property = s.AdditionalProperties
+			}
+			if property == nil {
+				continue
+			}
+			if err := d.validate(property, object[key], path+"."+key); err != nil {
+				return err
+			}
+		}
+	case "array":
+		array, ok := value.([]any)
+		if !ok {
+			return typeError(path, s.Type, value)
+		}
+		for i, element := range array {
+			if err := d.validate(s.Items, element, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		text, ok := value.(string)
+		if !ok {
+			return typeError(path, s.Type, value)
+		}
+		if s.Format == "date-time" {
+			if _, err := time.Parse(time.RFC3339, text); err != nil {
+				return fmt.Errorf("%s: data e hora inválida %q", path, text)
+			}
+		}
+	case "integer":
+		number, ok := value.(json.Number)
+		if !ok {
+			return typeError(path, s.Type, value)
+		}
+		if _, err := number.Int64(); err != nil {
+			return fmt.Errorf("%s: esperado inteiro, recebido %s", path, number)
+		}
+	case "number":
+		if _, ok := value.(json.Number); !ok {
+			return typeError(path, s.Type, value)
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return typeError(path, s.Type, value)
+		}
+	}
+	return nil
+}
+
+func typeError(path, expected string, value any) error {
+	return fmt.Errorf("%s: esperado %s, recebido %s", path, expected, jsonType(value))
+}
+
+func jsonType(value any) string {
+	switch value.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "array"
+	case string:
+		return "string"
+	case json.Number:
+		return "number"
+	case bool:
+		return "boolean"
+	default:
+		return reflect.TypeOf(value).String()
+	}
+}
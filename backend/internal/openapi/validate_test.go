@@ -0,0 +1,179 @@
+package openapi
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"project_lab/internal/problem"
+	"project_lab/internal/router"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testItem struct {
+	ID        int        `json:"id"`
+	Name      string     `json:"name"`
+	Tags      []string   `json:"tags"`
+	DoneAt    *time.Time `json:"doneAt"`
+	Note      string     `json:"note,omitempty"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
+
+type testCreate struct {
+	Name  string `json:"name"`
+	Count int    `json:"count"`
+}
+
+func writeJSON(w http.ResponseWriter, status int, body string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(body))
+}
+
+// validatorFor gera o documento de uma rota e devolve o handler validado e a
+// lista de divergências relatadas.
+func validatorFor(route router.Route) (http.Handler, *[]string) {
+	doc := Generate(Options{}, []router.Route{route})
+	var reports []string
+	api := router.New(func(h http.Handler) http.Handler { return h }, nil)
+	api.Handle(route)
+	return Validator(doc, func(err error) { reports = append(reports, err.Error()) })(api), &reports
+}
+
+func TestValidator(t *testing.T) {
+	const item = `{"id":1,"name":"a","tags":null,"doneAt":null,"createdAt":"2025-01-01T10:00:00Z"}`
+	tests := []struct {
+		name       string
+		route      router.Route
+		body       string
+		wantReport string
+	}{
+		{
+			name: "resposta conforme o contrato",
+			route: router.Route{Method: http.MethodGet, Pattern: "/items/{itemId}", Response: testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { writeJSON(w, 200, item) })},
+		},
+		{
+			name: "lista conforme o contrato",
+			route: router.Route{Method: http.MethodGet, Pattern: "/items", Response: []testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { writeJSON(w, 200, "["+item+"]") })},
+		},
+		{
+			name: "campo com tipo errado",
+			route: router.Route{Method: http.MethodGet, Pattern: "/items/{itemId}", Response: testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					writeJSON(w, 200, strings.Replace(item, `"id":1`, `"id":"1"`, 1))
+				})},
+			wantReport: "$.id: esperado integer, recebido string",
+		},
+		{
+			name: "campo obrigatório ausente",
+			route: router.Route{Method: http.MethodGet, Pattern: "/items/{itemId}", Response: testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { writeJSON(w, 200, `{"id":1}`) })},
+			wantReport: `campo obrigatório "name" ausente`,
+		},
+		{
+			name: "data inválida",
+			route: router.Route{Method: http.MethodGet, Pattern: "/items/{itemId}", Response: testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					writeJSON(w, 200, strings.Replace(item, "2025-01-01T10:00:00Z", "01/01/2025", 1))
+				})},
+			wantReport: "data e hora inválida",
+		},
+		{
+			name: "status de sucesso não documentado",
+			route: router.Route{Method: http.MethodPost, Pattern: "/items", Status: http.StatusCreated, Response: testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { writeJSON(w, 200, item) })},
+			body:       `{}`,
+			wantReport: "status 200 não documentado",
+		},
+		{
+			name: "corpo onde não deveria haver",
+			route: router.Route{Method: http.MethodDelete, Pattern: "/items/{itemId}", Status: http.StatusNoContent,
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusNoContent)
+					w.Write([]byte("ok"))
+				})},
+			wantReport: "corpo não documentado",
+		},
+		{
+			name: "erro fora do formato de problem",
+			route: router.Route{Method: http.MethodGet, Pattern: "/items/{itemId}", Response: testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					http.Error(w, "falhou", http.StatusInternalServerError)
+				})},
+			wantReport: "esperado application/problem+json",
+		},
+		{
+			name: "erro no formato de problem",
+			route: router.Route{Method: http.MethodGet, Pattern: "/items/{itemId}", Response: testItem{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { problem.NotFound(w, r) })},
+		},
+		{
+			name: "requisição fora do schema",
+			route: router.Route{Method: http.MethodPost, Pattern: "/items", Request: testCreate{}, Status: http.StatusNoContent,
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { w.WriteHeader(http.StatusNoContent) })},
+			body:       `{"name":"a","count":1.5}`,
+			wantReport: "requisição: $.count: esperado inteiro",
+		},
+		{
+			name: "o handler ainda lê o corpo validado",
+			route: router.Route{Method: http.MethodPost, Pattern: "/items", Request: testCreate{}, Response: testCreate{},
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					var req testCreate
+					json.NewDecoder(r.Body).Decode(&req)
+					writeJSON(w, 200, `{"name":"`+req.Name+`","count":2}`)
+				})},
+			body: `{"name":"a","count":1}`,
+		},
+		{
+			name: "tipo de download coberto por faixa",
+			route: router.Route{Method: http.MethodGet, Pattern: "/files/{fileId}", ContentType: "*/*",
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "application/pdf")
+					w.Write([]byte("%PDF-"))
+				})},
+		},
+		{
+			name: "tipo de resposta não documentado",
+			route: router.Route{Method: http.MethodGet, Pattern: "/files/{fileId}", ContentType: "image/jpeg",
+				Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.Header().Set("Content-Type", "text/html")
+					w.Write([]byte("<p>"))
+				})},
+			wantReport: `Content-Type "text/html" não documentado`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler, reports := validatorFor(tt.route)
+			path := strings.NewReplacer("{itemId}", "1", "{fileId}", "1").Replace(tt.route.Pattern)
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(tt.route.Method, path, strings.NewReader(tt.body)))
+
+			if tt.wantReport == "" {
+				if len(*reports) > 0 {
+					t.Fatalf("divergências inesperadas: %v", *reports)
+				}
+				return
+			}
+			if len(*reports) != 1 || !strings.Contains((*reports)[0], tt.wantReport) {
+				t.Fatalf("divergências = %v, esperado uma contendo %q", *reports, tt.wantReport)
+			}
+		})
+	}
+}
+
+// O validador não altera a resposta enviada ao cliente.
+func TestValidatorPassesResponseThrough(t *testing.T) {
+	handler, _ := validatorFor(router.Route{Method: http.MethodGet, Pattern: "/items", Response: []testItem{},
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { writeJSON(w, http.StatusOK, `{"bad":true}`) })})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != `{"bad":true}` {
+		t.Fatalf("resposta alterada: %d %s", rec.Code, rec.Body.String())
+	}
+}
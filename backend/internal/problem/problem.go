@@ -0,0 +1,176 @@
+// Package problem escreve as respostas de erro da API no formato RFC 7807
+// (application/problem+json). Além dos campos da RFC, cada resposta traz um
+// "code" estável que os clientes podem usar sem depender do texto de "detail",
+// que é uma mensagem para pessoas.
+package problem
+
+import (
+	"encoding/json"
+	"net/http"
+	"project_lab/internal/i18n"
+)
+
+// ContentType é o tipo de mídia das respostas de erro.
+const ContentType = "application/problem+json"
+
+// typePrefix forma o URI do campo "type" a partir do código.
+const typePrefix = "urn:easytrip:problem:"
+
+// Code identifica o tipo do erro. Os valores fazem parte do contrato da API:
+// podem ser acrescentados, mas não renomeados.
+type Code string
+
+// Códigos genéricos, usados quando não há um erro de domínio mais específico.
+const (
+	CodeInvalidRequest   Code = "invalid_request"
+	CodeValidationFailed Code = "validation_failed"
+	CodeUnauthorized     Code = "unauthorized"
+	CodeForbidden        Code = "forbidden"
+	CodeNotFound         Code = "not_found"
+	CodeMethodNotAllowed Code = "method_not_allowed"
+	CodeConflict         Code = "conflict"
+	CodeGone             Code = "gone"
+	CodeTooManyRequests  Code = "too_many_requests"
+	CodeInternal         Code = "internal_error"
+	CodeRequestCanceled  Code = "request_canceled"
+	CodeTimeout          Code = "timeout"
+)
+
+// Códigos de domínio.
+const (
+	CodeInvalidToken              Code = "invalid_token"
+	CodeInvalidCredentials        Code = "invalid_credentials"
+	CodeAccountLocked             Code = "account_locked"
+	CodeRateLimited               Code = "rate_limited"
+	CodeRefreshTokenInvalid       Code = "refresh_token_invalid"
+	CodeRefreshTokenReused        Code = "refresh_token_reused"
+	CodeActionTokenInvalid        Code = "action_token_invalid"
+	CodeEmailTaken                Code = "email_taken"
+	CodeEmailNotVerified          Code = "email_not_verified"
+	CodeUserNotFound              Code = "user_not_found"
+	CodeGroupNotFound             Code = "group_not_found"
+	CodeNotGroupOrganizer         Code = "not_group_organizer"
+	CodeReadOnlyMember            Code = "read_only_member"
+	CodeMemberNotFound            Code = "member_not_found"
+	CodeOrganizerRoleFixed        Code = "organizer_role_fixed"
+	CodeOwnershipTransferRequired Code = "ownership_transfer_required"
+	CodeUnsettledBalance          Code = "unsettled_balance"
+	CodeDestinationNotFound       Code = "destination_not_found"
+	CodeBudgetNotFound            Code = "budget_not_found"
+	CodeAttachmentNotFound        Code = "attachment_not_found"
+	CodeInvalidAttachment         Code = "invalid_attachment"
+	CodeAttachmentTooLarge        Code = "attachment_too_large"
+	CodeTooManyAttachments        Code = "too_many_attachments"
+	CodeExpenseNotFound           Code = "expense_not_found"
+	CodeInvalidSplit              Code = "invalid_split"
+	CodePaymentNotFound           Code = "payment_not_found"
+	CodeInvalidPayment            Code = "invalid_payment"
+	CodeRateNotFound              Code = "exchange_rate_not_found"
+	CodeItineraryItemNotFound     Code = "itinerary_item_not_found"
+	CodeInvalidItineraryItem      Code = "invalid_itinerary_item"
+	CodeItineraryOutsideDates     Code = "itinerary_outside_dates"
+	CodeVotingNotFound            Code = "voting_not_found"
+	CodeInvalidVoting             Code = "invalid_voting"
+	CodeVotingClosed              Code = "voting_closed"
+	CodeVoteNotFound              Code = "vote_not_found"
+	CodeAlreadyVoted              Code = "already_voted"
+	CodeInvalidBallot             Code = "invalid_ballot"
+	CodeInviteNotFound            Code = "invite_not_found"
+	CodeInviteUnavailable         Code = "invite_unavailable"
+	CodeInviteEmailMismatch       Code = "invite_email_mismatch"
+	CodeAlreadyGroupMember        Code = "already_group_member"
+	CodeFeedNotFound              Code = "calendar_feed_not_found"
+)
+
+// StatusClientClosedRequest é o status (fora do padrão, usado pelo nginx) das
+// requisições abandonadas pelo cliente antes da resposta. Só aparece nos logs
+// e nas métricas, já que o cliente não está mais esperando.
+const StatusClientClosedRequest = 499
+
+// FieldError aponta o campo da requisição que falhou na validação.
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// Problem é o corpo de uma resposta de erro.
+type Problem struct {
+	Type   string       `json:"type"`
+	Title  string       `json:"title"`
+	Status int          `json:"status"`
+	Detail string       `json:"detail,omitempty"`
+	Code   Code         `json:"code"`
+	Errors []FieldError `json:"errors,omitempty"`
+}
+
+// New monta um problema com o título padrão do status. detail já deve estar
+// no idioma da requisição; veja Localized.
+func New(status int, code Code, detail string) *Problem {
+	return &Problem{
+		Type:   typePrefix + string(code),
+		Title:  statusText(status),
+		Status: status,
+		Detail: detail,
+		Code:   code,
+	}
+}
+
+// Localized monta um problema com o detail traduzido para o idioma da requisição.
+func Localized(r *http.Request, status int, code Code, id string, args ...any) *Problem {
+	return New(status, code, i18n.T(i18n.FromContext(r.Context()), id, args...))
+}
+
+// Write envia o problema como resposta.
+func Write(w http.ResponseWriter, p *Problem) {
+	w.Header().Set("Content-Type", ContentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	json.NewEncoder(w).Encode(p)
+}
+
+// Error substitui http.Error: responde com o código genérico do status e a
+// mensagem id do catálogo, traduzida para o idioma da requisição.
+func Error(w http.ResponseWriter, r *http.Request, status int, id string, args ...any) {
+	Write(w, Localized(r, status, codeForStatus(status), id, args...))
+}
+
+// NotFound substitui http.NotFound para rotas inexistentes.
+func NotFound(w http.ResponseWriter, r *http.Request) {
+	Error(w, r, http.StatusNotFound, "request.not_found")
+}
+
+func codeForStatus(status int) Code {
+	switch status {
+	case http.StatusBadRequest:
+		return CodeInvalidRequest
+	case http.StatusUnprocessableEntity:
+		return CodeValidationFailed
+	case http.StatusUnauthorized:
+		return CodeUnauthorized
+	case http.StatusForbidden:
+		return CodeForbidden
+	case http.StatusNotFound:
+		return CodeNotFound
+	case http.StatusMethodNotAllowed:
+		return CodeMethodNotAllowed
+	case http.StatusConflict:
+		return CodeConflict
+	case http.StatusGone:
+		return CodeGone
+	case http.StatusTooManyRequests:
+		return CodeTooManyRequests
+	case StatusClientClosedRequest:
+		return CodeRequestCanceled
+	case http.StatusGatewayTimeout:
+		return CodeTimeout
+	default:
+		return CodeInternal
+	}
+}
+
+func statusText(status int) string {
+	if status == StatusClientClosedRequest {
+		return "Client Closed Request"
+	}
+	return http.StatusText(status)
+}
@@ -0,0 +1,53 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// memoryLimiter guarda as janelas no processo. Serve para uma única instância;
+// com várias, cada uma conta separadamente.
+type memoryLimiter struct {
+	mu        sync.Mutex
+	windows   map[string]*window
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+type window struct {
+	count   int
+	resetAt time.Time
+}
+
+// sweepInterval é de quanto em quanto tempo janelas vencidas são descartadas.
+const sweepInterval = time.Minute
+
+func NewMemoryLimiter() Limiter {
+	return &memoryLimiter{windows: make(map[string]*window), now: time.Now}
+}
+
+func (l *memoryLimiter) Allow(_ context.Context, key string, policy Policy) (Result, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= sweepInterval {
+		for k, w := range l.windows {
+			if !now.Before(w.resetAt) {
+				delete(l.windows, k)
+			}
+		}
+		l.lastSweep = now
+	}
+
+	key = policy.Name + ":" + key
+	w, ok := l.windows[key]
+	if !ok || !now.Before(w.resetAt) {
+		w = &window{resetAt: now.Add(policy.Window)}
+		l.windows[key] = w
+	}
+	w.count++
+
+	return result(policy, w.count, w.resetAt.Sub(now)), nil
+}
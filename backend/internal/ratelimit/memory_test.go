@@ -0,0 +1,70 @@
+package ratelimit
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestMemoryLimiterWindow(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter().(*memoryLimiter)
+	limiter.now = func() time.Time { return now }
+	policy := Policy{Name: "login", Limit: 2, Window: time.Minute}
+
+	steps := []struct {
+		advance time.Duration
+		key     string
+		want    Result
+	}{
+		{0, "a", Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Minute}},
+		{10 * time.Second, "a", Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 50 * time.Second}},
+		{10 * time.Second, "a", Result{Allowed: false, Limit: 2, Remaining: 0, ResetAfter: 40 * time.Second}},
+		{0, "b", Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Minute}},
+		// A janela de "a" vence aos 60s e recomeça do zero.
+		{40 * time.Second, "a", Result{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Minute}},
+		{0, "b", Result{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: 20 * time.Second}},
+	}
+	for i, step := range steps {
+		now = now.Add(step.advance)
+		got, err := limiter.Allow(context.Background(), step.key, policy)
+		if err != nil {
+			t.Fatalf("passo %d: erro inesperado: %v", i+1, err)
+		}
+		if got != step.want {
+			t.Fatalf("passo %d: %+v, esperado %+v", i+1, got, step.want)
+		}
+	}
+}
+
+func TestMemoryLimiterPoliciesAreSeparate(t *testing.T) {
+	limiter := NewMemoryLimiter()
+	login := Policy{Name: "login", Limit: 1, Window: time.Minute}
+	register := Policy{Name: "register", Limit: 1, Window: time.Minute}
+
+	limiter.Allow(context.Background(), "k", login)
+	if got, _ := limiter.Allow(context.Background(), "k", login); got.Allowed {
+		t.Fatal("segunda requisição de login deveria ser bloqueada")
+	}
+	if got, _ := limiter.Allow(context.Background(), "k", register); !got.Allowed {
+		t.Fatal("política register não deveria compartilhar a janela de login")
+	}
+}
+
+func TestMemoryLimiterSweepsExpiredWindows(t *testing.T) {
+	now := time.Date(2024, 1, 1, 12, 0, 0, 0, time.UTC)
+	limiter := NewMemoryLimiter().(*memoryLimiter)
+	limiter.now = func() time.Time { return now }
+	policy := Policy{Name: "login", Limit: 1, Window: 10 * time.Second}
+
+	limiter.Allow(context.Background(), "old", policy)
+	now = now.Add(2 * sweepInterval)
+	limiter.Allow(context.Background(), "new", policy)
+
+	if _, ok := limiter.windows["login:old"]; ok {
+		t.Fatal("janela vencida não foi descartada")
+	}
+	if len(limiter.windows) != 1 {
+		t.Fatalf("%d janelas, esperado 1", len(limiter.windows))
+	}
+}
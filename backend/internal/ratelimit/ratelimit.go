@@ -0,0 +1,76 @@
+// Package ratelimit limita requisições por chave em janelas fixas de tempo. Os
+// backends (memória ou um servidor compatível com o protocolo do Redis) contam
+// da mesma forma, então trocar de um para o outro não muda os limites.
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Policy permite Limit requisições por chave a cada Window.
+type Policy struct {
+	Name   string
+	Limit  int
+	Window time.Duration
+}
+
+// Result é a decisão para uma requisição e o estado da janela atual.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	ResetAfter time.Duration
+}
+
+// Limiter conta uma requisição para a chave na política e decide se ela passa.
+type Limiter interface {
+	Allow(ctx context.Context, key string, policy Policy) (Result, error)
+}
+
+// result monta a decisão a partir da contagem na janela (incluindo a requisição atual).
+func result(policy Policy, count int, resetAfter time.Duration) Result {
+	return Result{
+		Allowed:    count <= policy.Limit,
+		Limit:      policy.Limit,
+		Remaining:  max(policy.Limit-count, 0),
+		ResetAfter: max(resetAfter, 0),
+	}
+}
+
+// ParsePolicies aplica sobre defaults as políticas no formato
+// "nome=limite/janela,..." (ex.: "login=10/1m,forgot-password=5/15m").
+func ParsePolicies(spec string, defaults map[string]Policy) (map[string]Policy, error) {
+	policies := make(map[string]Policy, len(defaults))
+	for name, p := range defaults {
+		policies[name] = p
+	}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, rule, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("política de limite inválida %q: use nome=limite/janela", entry)
+		}
+		if _, known := defaults[name]; !known {
+			return nil, fmt.Errorf("política de limite desconhecida: %s", name)
+		}
+		limitText, windowText, ok := strings.Cut(rule, "/")
+		limit, err := strconv.Atoi(limitText)
+		if !ok || err != nil || limit <= 0 {
+			return nil, fmt.Errorf("limite inválido na política %s: %q", name, rule)
+		}
+		window, err := time.ParseDuration(windowText)
+		if err != nil || window < time.Second {
+			return nil, fmt.Errorf("janela inválida na política %s: %q (mínimo 1s)", name, rule)
+		}
+		policies[name] = Policy{Name: name, Limit: limit, Window: window}
+	}
+	return policies, nil
+}
@@ -0,0 +1,225 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// windowScript incrementa o contador da janela e devolve {contagem, ms até o
+// fim da janela}. Roda atômico no servidor, então instâncias concorrentes
+// nunca perdem incrementos nem criam chaves sem expiração.
+const windowScript = `
+local count = redis.call('INCR', KEYS[1])
+local ttl = redis.call('PTTL', KEYS[1])
+if ttl < 0 then
+  redis.call('PEXPIRE', KEYS[1], ARGV[1])
+  ttl = tonumber(ARGV[1])
+end
+return {count, ttl}
+`
+
+const (
+	redisKeyPrefix    = "ratelimit:"
+	redisTimeout      = 500 * time.Millisecond
+	redisMaxIdleConns = 8
+)
+
+// redisLimiter conta as janelas em um servidor que fala o protocolo do Redis
+// (Redis, Valkey, KeyDB...), compartilhando os limites entre instâncias. Usa um
+// cliente RESP mínimo com um pool pequeno de conexões.
+type redisLimiter struct {
+	addr     string
+	password string
+	db       int
+	idle     chan *redisConn
+}
+
+type redisConn struct {
+	conn net.Conn
+	r    *bufio.Reader
+}
+
+// errRedisReply é um erro devolvido pelo servidor ("-ERR ..."); a conexão continua utilizável.
+type errRedisReply string
+
+func (e errRedisReply) Error() string { return "redis: " + string(e) }
+
+// errRedisSend indica que o comando não chegou a ser enviado por inteiro. Só
+// nesse caso é seguro repeti-lo: depois do envio, o servidor pode já ter
+// executado o script, e repetir contaria a requisição duas vezes.
+type errRedisSend struct{ err error }
+
+func (e *errRedisSend) Error() string { return "redis: erro ao enviar comando: " + e.err.Error() }
+func (e *errRedisSend) Unwrap() error { return e.err }
+
+func NewRedisLimiter(addr, password string, db int) Limiter {
+	return &redisLimiter{addr: addr, password: password, db: db, idle: make(chan *redisConn, redisMaxIdleConns)}
+}
+
+func (l *redisLimiter) Allow(ctx context.Context, key string, policy Policy) (Result, error) {
+	windowMs := strconv.FormatInt(policy.Window.Milliseconds(), 10)
+	reply, err := l.do(ctx, "EVAL", windowScript, "1", redisKeyPrefix+policy.Name+":"+key, windowMs)
+	if err != nil {
+		return Result{}, err
+	}
+
+	values, ok := reply.([]any)
+	if !ok || len(values) != 2 {
+		return Result{}, fmt.Errorf("redis: resposta inesperada do script de limite: %v", reply)
+	}
+	count, ok1 := values[0].(int64)
+	ttl, ok2 := values[1].(int64)
+	if !ok1 || !ok2 {
+		return Result{}, fmt.Errorf("redis: resposta inesperada do script de limite: %v", reply)
+	}
+
+	return result(policy, int(count), time.Duration(ttl)*time.Millisecond), nil
+}
+
+// do envia um comando e lê a resposta, devolvendo a conexão ao pool se ela
+// continuar em bom estado. Uma conexão do pool pode ter sido fechada pelo
+// servidor enquanto estava parada; se o envio falhar nela, o comando é repetido
+// em uma conexão nova. Falhas depois do envio (na leitura) não são repetidas.
+func (l *redisLimiter) do(ctx context.Context, args ...string) (any, error) {
+	for {
+		c, pooled, err := l.get(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		reply, err := c.roundTrip(ctx, args...)
+		var replyErr errRedisReply
+		if err != nil && !errors.As(err, &replyErr) {
+			c.conn.Close()
+			var sendErr *errRedisSend
+			if pooled && errors.As(err, &sendErr) {
+				continue
+			}
+			return nil, err
+		}
+		l.put(c)
+		return reply, err
+	}
+}
+
+func (l *redisLimiter) get(ctx context.Context) (c *redisConn, pooled bool, err error) {
+	select {
+	case c := <-l.idle:
+		return c, true, nil
+	default:
+	}
+
+	dialer := net.Dialer{Timeout: redisTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", l.addr)
+	if err != nil {
+		return nil, false, fmt.Errorf("redis: erro ao conectar em %s: %w", l.addr, err)
+	}
+	c = &redisConn{conn: conn, r: bufio.NewReader(conn)}
+
+	if l.password != "" {
+		if _, err := c.roundTrip(ctx, "AUTH", l.password); err != nil {
+			conn.Close()
+			return nil, false, err
+		}
+	}
+	if l.db != 0 {
+		if _, err := c.roundTrip(ctx, "SELECT", strconv.Itoa(l.db)); err != nil {
+			conn.Close()
+			return nil, false, err
+		}
+	}
+	return c, false, nil
+}
+
+func (l *redisLimiter) put(c *redisConn) {
+	select {
+	case l.idle <- c:
+	default:
+		c.conn.Close()
+	}
+}
+
+func (c *redisConn) roundTrip(ctx context.Context, args ...string) (any, error) {
+	deadline := time.Now().Add(redisTimeout)
+	if d, ok := ctx.Deadline(); ok && d.Before(deadline) {
+		deadline = d
+	}
+	c.conn.SetDeadline(deadline)
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(&b, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if _, err := io.WriteString(c.conn, b.String()); err != nil {
+		return nil, &errRedisSend{err: err}
+	}
+	return readReply(c.r)
+}
+
+// readReply lê uma resposta RESP2: +simples, -erro, :inteiro, $bulk e *array.
+func readReply(r *bufio.Reader) (any, error) {
+	line, err := r.ReadString('\n')
+	if err != nil {
+		return nil, fmt.Errorf("redis: erro ao ler resposta: %w", err)
+	}
+	line = strings.TrimSuffix(line, "\r\n")
+	if line == "" {
+		return nil, errors.New("redis: resposta vazia")
+	}
+
+	payload := line[1:]
+	switch line[0] {
+	case '+':
+		return payload, nil
+	case '-':
+		return nil, errRedisReply(payload)
+	case ':':
+		n, err := strconv.ParseInt(payload, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("redis: inteiro inválido %q", payload)
+		}
+		return n, nil
+	case '$':
+		size, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: tamanho inválido %q", payload)
+		}
+		if size < 0 {
+			return nil, nil
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			return nil, fmt.Errorf("redis: erro ao ler resposta: %w", err)
+		}
+		return string(buf[:size]), nil
+	case '*':
+		count, err := strconv.Atoi(payload)
+		if err != nil {
+			return nil, fmt.Errorf("redis: tamanho inválido %q", payload)
+		}
+		if count < 0 {
+			return nil, nil
+		}
+		items := make([]any, count)
+		for i := range items {
+			if items[i], err = readReply(r); err != nil {
+				var replyErr errRedisReply
+				if !errors.As(err, &replyErr) {
+					return nil, err
+				}
+				items[i] = replyErr
+			}
+		}
+		return items, nil
+	default:
+		return nil, fmt.Errorf("redis: tipo de resposta desconhecido %q", line[0])
+	}
+}
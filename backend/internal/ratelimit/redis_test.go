@@ -0,0 +1,267 @@
+package ratelimit
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestReadReply(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    any
+		wantErr string
+	}{
+		{name: "simples", input: "+OK\r\n", want: "OK"},
+		{name: "inteiro", input: ":42\r\n", want: int64(42)},
+		{name: "inteiro negativo", input: ":-2\r\n", want: int64(-2)},
+		{name: "bulk", input: "$5\r\nhello\r\n", want: "hello"},
+		{name: "bulk vazio", input: "$0\r\n\r\n", want: ""},
+		{name: "bulk com CRLF no conteúdo", input: "$4\r\na\r\nb\r\n", want: "a\r\nb"},
+		{name: "bulk nulo", input: "$-1\r\n", want: nil},
+		{name: "array", input: "*2\r\n:3\r\n:59000\r\n", want: []any{int64(3), int64(59000)}},
+		{name: "array vazio", input: "*0\r\n", want: []any{}},
+		{name: "array nulo", input: "*-1\r\n", want: nil},
+		{name: "array aninhado", input: "*2\r\n*1\r\n+a\r\n$1\r\nb\r\n", want: []any{[]any{"a"}, "b"}},
+		{name: "erro dentro do array", input: "*2\r\n-ERR x\r\n:1\r\n", want: []any{errRedisReply("ERR x"), int64(1)}},
+		{name: "erro", input: "-ERR unknown command\r\n", wantErr: "redis: ERR unknown command"},
+		{name: "inteiro inválido", input: ":abc\r\n", wantErr: "inteiro inválido"},
+		{name: "tamanho de bulk inválido", input: "$x\r\n", wantErr: "tamanho inválido"},
+		{name: "tamanho de array inválido", input: "*x\r\n", wantErr: "tamanho inválido"},
+		{name: "bulk mais curto que o tamanho", input: "$10\r\nabc\r\n", wantErr: "erro ao ler resposta"},
+		{name: "array incompleto", input: "*2\r\n:1\r\n", wantErr: "erro ao ler resposta"},
+		{name: "linha vazia", input: "\r\n", wantErr: "resposta vazia"},
+		{name: "tipo desconhecido", input: "!x\r\n", wantErr: "tipo de resposta desconhecido"},
+		{name: "sem fim de linha", input: "+OK", wantErr: "erro ao ler resposta"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := readReply(bufio.NewReader(strings.NewReader(tt.input)))
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("erro = %v, esperado contendo %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("resposta = %#v, esperado %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReadReplyServerErrorKeepsType(t *testing.T) {
+	_, err := readReply(bufio.NewReader(strings.NewReader("-WRONGPASS invalid\r\n")))
+	var replyErr errRedisReply
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("erro = %T, esperado errRedisReply", err)
+	}
+}
+
+// fakeRedis atende o subconjunto do protocolo usado pelo limitador: AUTH,
+// SELECT e o EVAL do script de janela, com as janelas em memória.
+type fakeRedis struct {
+	t        *testing.T
+	listener net.Listener
+	password string
+
+	mu       sync.Mutex
+	counts   map[string]int64
+	commands []string
+	// dropNextEval faz o servidor ler o próximo EVAL, executá-lo e fechar a
+	// conexão sem responder, como em um timeout de leitura no cliente.
+	dropNextEval bool
+}
+
+func newFakeRedis(t *testing.T, password string) *fakeRedis {
+	t.Helper()
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("erro ao abrir o servidor: %v", err)
+	}
+	f := &fakeRedis{t: t, listener: listener, password: password, counts: make(map[string]int64)}
+	t.Cleanup(func() { listener.Close() })
+	go f.serve()
+	return f
+}
+
+func (f *fakeRedis) addr() string { return f.listener.Addr().String() }
+
+func (f *fakeRedis) serve() {
+	for {
+		conn, err := f.listener.Accept()
+		if err != nil {
+			return
+		}
+		go f.handle(conn)
+	}
+}
+
+func (f *fakeRedis) handle(conn net.Conn) {
+	defer conn.Close()
+	r := bufio.NewReader(conn)
+	authed := f.password == ""
+	for {
+		reply, err := readReply(r)
+		if err != nil {
+			return
+		}
+		items, _ := reply.([]any)
+		args := make([]string, len(items))
+		for i, item := range items {
+			args[i], _ = item.(string)
+		}
+		if len(args) == 0 {
+			return
+		}
+
+		f.mu.Lock()
+		f.commands = append(f.commands, args[0])
+		var out string
+		drop := false
+		switch {
+		case args[0] == "AUTH":
+			if len(args) == 2 && args[1] == f.password {
+				authed = true
+				out = "+OK\r\n"
+			} else {
+				out = "-WRONGPASS invalid password\r\n"
+			}
+		case !authed:
+			out = "-NOAUTH Authentication required.\r\n"
+		case args[0] == "SELECT":
+			out = "+OK\r\n"
+		case args[0] == "EVAL" && len(args) == 5:
+			f.counts[args[3]]++
+			out = fmt.Sprintf("*2\r\n:%d\r\n:%s\r\n", f.counts[args[3]], args[4])
+			drop, f.dropNextEval = f.dropNextEval, false
+		default:
+			out = "-ERR unknown command\r\n"
+		}
+		f.mu.Unlock()
+
+		if drop {
+			return
+		}
+		if _, err := conn.Write([]byte(out)); err != nil {
+			return
+		}
+	}
+}
+
+func (f *fakeRedis) evals() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	n := 0
+	for _, c := range f.commands {
+		if c == "EVAL" {
+			n++
+		}
+	}
+	return n
+}
+
+func TestRedisLimiterAllow(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	limiter := NewRedisLimiter(server.addr(), "secret", 2)
+	policy := Policy{Name: "login", Limit: 2, Window: time.Minute}
+
+	want := []Result{
+		{Allowed: true, Limit: 2, Remaining: 1, ResetAfter: time.Minute},
+		{Allowed: true, Limit: 2, Remaining: 0, ResetAfter: time.Minute},
+		{Allowed: false, Limit: 2, Remaining: 0, ResetAfter: time.Minute},
+	}
+	for i, w := range want {
+		got, err := limiter.Allow(context.Background(), "1.2.3.4", policy)
+		if err != nil {
+			t.Fatalf("requisição %d: erro inesperado: %v", i+1, err)
+		}
+		if got != w {
+			t.Fatalf("requisição %d: %+v, esperado %+v", i+1, got, w)
+		}
+	}
+
+	// Outra chave e outra política têm janelas próprias.
+	if got, _ := limiter.Allow(context.Background(), "5.6.7.8", policy); !got.Allowed {
+		t.Fatalf("outra chave bloqueada: %+v", got)
+	}
+	if got, _ := limiter.Allow(context.Background(), "1.2.3.4", Policy{Name: "register", Limit: 1, Window: time.Minute}); !got.Allowed {
+		t.Fatalf("outra política bloqueada: %+v", got)
+	}
+
+	// A conexão é reaproveitada: AUTH e SELECT só na primeira.
+	server.mu.Lock()
+	commands := strings.Join(server.commands, ",")
+	server.mu.Unlock()
+	if commands != "AUTH,SELECT,EVAL,EVAL,EVAL,EVAL,EVAL" {
+		t.Fatalf("comandos = %s", commands)
+	}
+}
+
+func TestRedisLimiterWrongPassword(t *testing.T) {
+	server := newFakeRedis(t, "secret")
+	limiter := NewRedisLimiter(server.addr(), "wrong", 0)
+
+	_, err := limiter.Allow(context.Background(), "k", Policy{Name: "login", Limit: 1, Window: time.Minute})
+	var replyErr errRedisReply
+	if !errors.As(err, &replyErr) {
+		t.Fatalf("erro = %v, esperado erro do servidor", err)
+	}
+}
+
+func TestRedisLimiterDoesNotRetryAfterSend(t *testing.T) {
+	server := newFakeRedis(t, "")
+	limiter := NewRedisLimiter(server.addr(), "", 0)
+	policy := Policy{Name: "login", Limit: 5, Window: time.Minute}
+
+	if _, err := limiter.Allow(context.Background(), "k", policy); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+
+	// A conexão do pool cai depois que o servidor já executou o script: o
+	// comando não pode ser repetido, senão a requisição contaria duas vezes.
+	server.mu.Lock()
+	server.dropNextEval = true
+	server.mu.Unlock()
+	if _, err := limiter.Allow(context.Background(), "k", policy); err == nil {
+		t.Fatal("esperado erro quando a conexão cai sem resposta")
+	}
+	if n := server.evals(); n != 2 {
+		t.Fatalf("EVAL executado %d vezes, esperado 2", n)
+	}
+
+	// A próxima requisição abre uma conexão nova e vê as duas contagens.
+	got, err := limiter.Allow(context.Background(), "k", policy)
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if got.Remaining != 2 {
+		t.Fatalf("restantes = %d, esperado 2", got.Remaining)
+	}
+}
+
+func TestRedisLimiterUnreachable(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := listener.Addr().String()
+	listener.Close()
+
+	limiter := NewRedisLimiter(addr, "", 0)
+	if _, err := limiter.Allow(context.Background(), "k", Policy{Name: "login", Limit: 1, Window: time.Minute}); err == nil {
+		t.Fatal("esperado erro com o servidor fora do ar")
+	}
+}
@@ -0,0 +1,196 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"project_lab/internal/models"
+)
+
+var (
+	// ErrAttachmentNotFound indica que o comprovante não existe na despesa informada.
+	ErrAttachmentNotFound = errors.New("comprovante não encontrado")
+	// ErrAttachmentLimitReached indica que a despesa já tem o máximo de comprovantes.
+	ErrAttachmentLimitReached = errors.New("limite de comprovantes da despesa atingido")
+)
+
+// AttachmentRepository guarda os dados dos comprovantes das despesas; os
+// arquivos em si ficam no BlobStore.
+type AttachmentRepository interface {
+	CreateAttachment(ctx context.Context, groupID int, attachment *models.ExpenseAttachment, maxPerExpense int) error
+	ListAttachments(ctx context.Context, groupID int, expenseID int) ([]models.ExpenseAttachment, error)
+	ListGroupAttachments(ctx context.Context, groupID int) ([]models.ExpenseAttachment, error)
+	GetAttachment(ctx context.Context, groupID int, expenseID int, attachmentID int) (*models.ExpenseAttachment, error)
+	DeleteAttachment(ctx context.Context, groupID int, expenseID int, attachmentID int) error
+}
+
+type postgresAttachmentRepository struct {
+	db *sql.DB
+}
+
+func NewAttachmentRepository(db *sql.DB) AttachmentRepository {
+	return &postgresAttachmentRepository{db: db}
+}
+
+// attachmentColumns são as colunas lidas por scanAttachment. Todas as consultas
+// passam pela despesa para garantir que ela é do grupo informado.
+const attachmentColumns = `a.id, a.expense_id, a.file_name, a.content_type, a.size_bytes, a.uploaded_by, a.created_at, a.storage_key, a.thumbnail_key`
+
+func scanAttachment(row rowScanner) (models.ExpenseAttachment, error) {
+	var a models.ExpenseAttachment
+	var uploadedBy sql.NullInt32
+	var thumbnailKey sql.NullString
+	err := row.Scan(&a.ID, &a.ExpenseID, &a.FileName, &a.ContentType, &a.Size, &uploadedBy, &a.CreatedAt, &a.StorageKey, &thumbnailKey)
+	if err != nil {
+		return a, err
+	}
+	if uploadedBy.Valid {
+		id := int(uploadedBy.Int32)
+		a.UploadedBy = &id
+	}
+	if thumbnailKey.Valid {
+		a.ThumbnailKey = &thumbnailKey.String
+	}
+	return a, nil
+}
+
+// CreateAttachment grava o comprovante, desde que a despesa seja do grupo e
+// tenha menos de maxPerExpense comprovantes. A linha da despesa fica travada
+// durante a contagem, para que envios simultâneos não passem do limite.
+func (r *postgresAttachmentRepository) CreateAttachment(ctx context.Context, groupID int, attachment *models.ExpenseAttachment, maxPerExpense int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação para comprovante: %w", err)
+	}
+	defer tx.Rollback()
+
+	var expenseID int
+	err = tx.QueryRowContext(ctx, `SELECT id FROM expenses WHERE travel_group_id = $1 AND id = $2 FOR UPDATE;`, groupID, attachment.ExpenseID).Scan(&expenseID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrExpenseNotFound
+		}
+		return fmt.Errorf("erro ao buscar despesa do comprovante: %w", err)
+	}
+	var count int
+	err = tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM expense_attachments WHERE expense_id = $1;`, expenseID).Scan(&count)
+	if err != nil {
+		return fmt.Errorf("erro ao contar comprovantes: %w", err)
+	}
+	if count >= maxPerExpense {
+		return ErrAttachmentLimitReached
+	}
+
+	query := `
+        INSERT INTO expense_attachments
+        (expense_id, uploaded_by, file_name, content_type, size_bytes, storage_key, thumbnail_key, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, NOW())
+        RETURNING id, created_at;
+    `
+	err = tx.QueryRowContext(ctx, query,
+		attachment.ExpenseID,
+		attachment.UploadedBy,
+		attachment.FileName,
+		attachment.ContentType,
+		attachment.Size,
+		attachment.StorageKey,
+		attachment.ThumbnailKey,
+	).Scan(&attachment.ID, &attachment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir comprovante: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação do comprovante: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresAttachmentRepository) ListAttachments(ctx context.Context, groupID int, expenseID int) ([]models.ExpenseAttachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT ` + attachmentColumns + `
+        FROM expense_attachments a
+        JOIN expenses e ON e.id = a.expense_id
+        WHERE e.travel_group_id = $1 AND a.expense_id = $2
+        ORDER BY a.created_at, a.id;
+    `
+	return r.listAttachments(ctx, query, groupID, expenseID)
+}
+
+// ListGroupAttachments lista os comprovantes de todas as despesas do grupo.
+func (r *postgresAttachmentRepository) ListGroupAttachments(ctx context.Context, groupID int) ([]models.ExpenseAttachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT ` + attachmentColumns + `
+        FROM expense_attachments a
+        JOIN expenses e ON e.id = a.expense_id
+        WHERE e.travel_group_id = $1
+        ORDER BY a.expense_id, a.created_at, a.id;
+    `
+	return r.listAttachments(ctx, query, groupID)
+}
+
+func (r *postgresAttachmentRepository) listAttachments(ctx context.Context, query string, args ...any) ([]models.ExpenseAttachment, error) {
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar comprovantes: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []models.ExpenseAttachment{}
+	for rows.Next() {
+		a, err := scanAttachment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear comprovante: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração dos comprovantes: %w", err)
+	}
+	return attachments, nil
+}
+
+func (r *postgresAttachmentRepository) GetAttachment(ctx context.Context, groupID int, expenseID int, attachmentID int) (*models.ExpenseAttachment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT ` + attachmentColumns + `
+        FROM expense_attachments a
+        JOIN expenses e ON e.id = a.expense_id
+        WHERE e.travel_group_id = $1 AND a.expense_id = $2 AND a.id = $3;
+    `
+	a, err := scanAttachment(r.db.QueryRowContext(ctx, query, groupID, expenseID, attachmentID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrAttachmentNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar comprovante: %w", err)
+	}
+	return &a, nil
+}
+
+func (r *postgresAttachmentRepository) DeleteAttachment(ctx context.Context, groupID int, expenseID int, attachmentID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+        DELETE FROM expense_attachments a
+        USING expenses e
+        WHERE e.id = a.expense_id AND e.travel_group_id = $1 AND a.expense_id = $2 AND a.id = $3;
+    `, groupID, expenseID, attachmentID)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar comprovante: %w", err)
+	}
+	return checkRowsAffected(result, ErrAttachmentNotFound)
+}
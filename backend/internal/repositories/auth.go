@@ -1,8 +1,10 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"project_lab/internal/models"
 
 	"github.com/lib/pq"
@@ -11,12 +13,20 @@ import (
 // ErrEmailAlreadyExists é um erro customizado para duplicidade de e-mail.
 var ErrEmailAlreadyExists = errors.New("e-mail já está em uso")
 
+// ErrUserNotFound indica que o usuário não existe.
+var ErrUserNotFound = errors.New("usuário não encontrado")
+
 // UserRepository é a interface que define os métodos de acesso a dados para usuários.
 type UserRepository interface {
-	CreateUser(user *models.User) error
-	FindByEmail(email string) (*models.User, error)
-	GetUserProfile(userID int) (*models.UserProfileResponse, error)
-	UpdateUserName(userID int, newName string) error
+	CreateUser(ctx context.Context, user *models.User) error
+	FindByEmail(ctx context.Context, email string) (*models.User, error)
+	FindByID(ctx context.Context, userID int) (*models.User, error)
+	MarkEmailVerified(ctx context.Context, userID int) error
+	UpdatePassword(ctx context.Context, userID int, passwordHash string) error
+	GetUserProfile(ctx context.Context, userID int) (*models.UserProfileResponse, error)
+	UpdateUserName(ctx context.Context, userID int, newName string) error
+	UpdatePreferredLocale(ctx context.Context, userID int, locale string) error
+	GetPreferredLocale(ctx context.Context, userID int) (string, error)
 }
 
 // userRepository representa a implementação do repositório com o banco de dados.
@@ -31,73 +41,157 @@ func NewUserRepository(db *sql.DB) UserRepository {
 	}
 }
 
-// CreateUser insere um novo usuário no banco de dados.
-func (r *userRepository) CreateUser(user *models.User) error {
-	query := `INSERT INTO users (name, email, password_hash) VALUES ($1, $2, $3)`
-	_, err := r.db.Exec(query, user.Name, user.Email, user.PasswordHash)
+// CreateUser insere um novo usuário no banco de dados e preenche user.ID.
+func (r *userRepository) CreateUser(ctx context.Context, user *models.User) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `INSERT INTO users (name, email, password_hash, preferred_locale, created_at) VALUES ($1, $2, $3, NULLIF($4, ''), NOW()) RETURNING id`
+	err := r.db.QueryRowContext(ctx, query, user.Name, user.Email, user.PasswordHash, user.Locale).Scan(&user.ID)
 	if err != nil {
 		var pqErr *pq.Error
 		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
 			return ErrEmailAlreadyExists
 		}
-		return errors.New("erro ao criar usuário: " + err.Error())
+		return fmt.Errorf("erro ao criar usuário: %w", err)
 	}
 	return nil
 }
 
-// FindByEmail busca um usuário no banco de dados por e-mail.
-func (r *userRepository) FindByEmail(email string) (*models.User, error) {
-	query := `SELECT id, name, email, password_hash FROM users WHERE email = $1`
+// FindByEmail busca um usuário no banco de dados por e-mail, sem diferenciar maiúsculas.
+func (r *userRepository) FindByEmail(ctx context.Context, email string) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, name, email, password_hash, email_verified_at, COALESCE(preferred_locale, '') FROM users WHERE LOWER(email) = LOWER($1)`
 	user := &models.User{}
-	err := r.db.QueryRow(query, email).Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash)
+	err := r.db.QueryRowContext(ctx, query, email).Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.EmailVerifiedAt, &user.Locale)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, errors.New("usuário não encontrado")
+			return nil, ErrUserNotFound
 		}
 		return nil, err
 	}
 	return user, nil
 }
 
+// FindByID busca um usuário no banco de dados por ID.
+func (r *userRepository) FindByID(ctx context.Context, userID int) (*models.User, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `SELECT id, name, email, password_hash, email_verified_at, COALESCE(preferred_locale, '') FROM users WHERE id = $1`
+	user := &models.User{}
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&user.ID, &user.Name, &user.Email, &user.PasswordHash, &user.EmailVerifiedAt, &user.Locale)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrUserNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// MarkEmailVerified registra a confirmação do e-mail (mantém a primeira data).
+func (r *userRepository) MarkEmailVerified(ctx context.Context, userID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE users SET email_verified_at = COALESCE(email_verified_at, NOW()) WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao confirmar e-mail: %w", err)
+	}
+	return checkRowsAffected(result, ErrUserNotFound)
+}
+
+// UpdatePassword grava o novo hash de senha do usuário.
+func (r *userRepository) UpdatePassword(ctx context.Context, userID int, passwordHash string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE users SET password_hash = $2, updated_at = NOW() WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, userID, passwordHash)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar senha: %w", err)
+	}
+	return checkRowsAffected(result, ErrUserNotFound)
+}
+
 // GetUserProfile busca o nome e email do usuário pelo ID.
-func (r *userRepository) GetUserProfile(userID int) (*models.UserProfileResponse, error) {
+func (r *userRepository) GetUserProfile(ctx context.Context, userID int) (*models.UserProfileResponse, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	var profile models.UserProfileResponse
-	query := `SELECT name, email FROM users WHERE id = $1`
+	query := `SELECT name, email, email_verified_at IS NOT NULL, preferred_locale FROM users WHERE id = $1`
 
-	err := r.db.QueryRow(query, userID).Scan(&profile.Name, &profile.Email)
+	err := r.db.QueryRowContext(ctx, query, userID).Scan(&profile.Name, &profile.Email, &profile.EmailVerified, &profile.Locale)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
 			// Se o usuário não existir
-			return nil, errors.New("usuário não encontrado")
+			return nil, ErrUserNotFound
 		}
 		// Outros erros de banco de dados
-		return nil, errors.New("erro ao buscar perfil do usuário: " + err.Error())
+		return nil, fmt.Errorf("erro ao buscar perfil do usuário: %w", err)
 	}
 	return &profile, nil
 }
 
 // UpdateUserName atualiza o nome do usuário.
-func (r *userRepository) UpdateUserName(userID int, newName string) error {
+func (r *userRepository) UpdateUserName(ctx context.Context, userID int, newName string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		UPDATE users 
 		SET name = $2, updated_at = NOW()
 		WHERE id = $1
 	`
 	// Note: Eu corrigi o caractere inválido ' ' que estava no seu código original.
-	result, err := r.db.Exec(query, userID, newName)
+	result, err := r.db.ExecContext(ctx, query, userID, newName)
 	if err != nil {
-		return errors.New("erro ao atualizar nome do usuário: " + err.Error())
+		return fmt.Errorf("erro ao atualizar nome do usuário: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
-		return errors.New("erro ao verificar linhas afetadas: " + err.Error())
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return errors.New("usuário não encontrado para atualização")
+		return ErrUserNotFound
 	}
 
 	return nil
 }
+
+// UpdatePreferredLocale grava o idioma preferido do usuário; vazio remove a preferência.
+func (r *userRepository) UpdatePreferredLocale(ctx context.Context, userID int, locale string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE users SET preferred_locale = NULLIF($2, ''), updated_at = NOW() WHERE id = $1`
+	result, err := r.db.ExecContext(ctx, query, userID, locale)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar idioma do usuário: %w", err)
+	}
+	return checkRowsAffected(result, ErrUserNotFound)
+}
+
+// GetPreferredLocale devolve o idioma preferido do usuário, ou "" se não houver.
+func (r *userRepository) GetPreferredLocale(ctx context.Context, userID int) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var locale sql.NullString
+	err := r.db.QueryRowContext(ctx, `SELECT preferred_locale FROM users WHERE id = $1`, userID).Scan(&locale)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrUserNotFound
+		}
+		return "", fmt.Errorf("erro ao buscar idioma do usuário: %w", err)
+	}
+	return locale.String, nil
+}
@@ -0,0 +1,178 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"project_lab/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ErrBudgetNotFound indica que o grupo ainda não tem orçamento.
+var ErrBudgetNotFound = errors.New("orçamento do grupo não encontrado")
+
+// BudgetRepository guarda o orçamento de cada grupo: total, meta por pessoa,
+// valor planejado por categoria e os avisos de estouro já enviados.
+type BudgetRepository interface {
+	GetBudget(ctx context.Context, groupID int) (*models.GroupBudget, error)
+	SaveBudget(ctx context.Context, budget *models.GroupBudget) error
+	DeleteBudget(ctx context.Context, groupID int) error
+	MarkCategoryAlerted(ctx context.Context, groupID int, category string) (bool, error)
+	ClearCategoryAlert(ctx context.Context, groupID int, category string) error
+}
+
+type postgresBudgetRepository struct {
+	db *sql.DB
+}
+
+func NewBudgetRepository(db *sql.DB) BudgetRepository {
+	return &postgresBudgetRepository{db: db}
+}
+
+func (r *postgresBudgetRepository) GetBudget(ctx context.Context, groupID int) (*models.GroupBudget, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	budget := models.GroupBudget{TravelGroupID: groupID, Categories: []models.CategoryBudget{}}
+	err := r.db.QueryRowContext(ctx, `
+        SELECT total_amount, per_person_amount, alert_threshold
+        FROM group_budgets
+        WHERE travel_group_id = $1;
+    `, groupID).Scan(&budget.Total, &budget.PerPerson, &budget.AlertThreshold)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrBudgetNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar orçamento: %w", err)
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT category, planned_amount, alerted_at
+        FROM group_budget_categories
+        WHERE travel_group_id = $1;
+    `, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar categorias do orçamento: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var c models.CategoryBudget
+		if err := rows.Scan(&c.Category, &c.Planned, &c.AlertedAt); err != nil {
+			return nil, fmt.Errorf("erro ao escanear categoria do orçamento: %w", err)
+		}
+		budget.Categories = append(budget.Categories, c)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração das categorias do orçamento: %w", err)
+	}
+
+	return &budget, nil
+}
+
+// SaveBudget grava o orçamento, substituindo o anterior. Categorias com o mesmo
+// valor planejado mantêm o aviso já enviado; as demais voltam a ser avaliadas.
+func (r *postgresBudgetRepository) SaveBudget(ctx context.Context, budget *models.GroupBudget) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação do orçamento: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO group_budgets (travel_group_id, total_amount, per_person_amount, alert_threshold, updated_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (travel_group_id) DO UPDATE
+        SET total_amount = EXCLUDED.total_amount,
+            per_person_amount = EXCLUDED.per_person_amount,
+            alert_threshold = EXCLUDED.alert_threshold,
+            updated_at = NOW();
+    `, budget.TravelGroupID, budget.Total, budget.PerPerson, budget.AlertThreshold)
+	if err != nil {
+		return fmt.Errorf("erro ao gravar orçamento: %w", err)
+	}
+
+	categories := make([]string, len(budget.Categories))
+	for i, c := range budget.Categories {
+		categories[i] = c.Category
+	}
+	_, err = tx.ExecContext(ctx, `
+        DELETE FROM group_budget_categories
+        WHERE travel_group_id = $1 AND NOT (category = ANY($2));
+    `, budget.TravelGroupID, pq.Array(categories))
+	if err != nil {
+		return fmt.Errorf("erro ao remover categorias do orçamento: %w", err)
+	}
+
+	categoryQuery := `
+        INSERT INTO group_budget_categories (travel_group_id, category, planned_amount)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (travel_group_id, category) DO UPDATE
+        SET planned_amount = EXCLUDED.planned_amount,
+            alerted_at = CASE WHEN group_budget_categories.planned_amount = EXCLUDED.planned_amount
+                              THEN group_budget_categories.alerted_at END;
+    `
+	for _, c := range budget.Categories {
+		if _, err := tx.ExecContext(ctx, categoryQuery, budget.TravelGroupID, c.Category, c.Planned); err != nil {
+			return fmt.Errorf("erro ao gravar categoria %s do orçamento: %w", c.Category, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação do orçamento: %w", err)
+	}
+	return nil
+}
+
+// DeleteBudget apaga o orçamento; as categorias saem por ON DELETE CASCADE.
+func (r *postgresBudgetRepository) DeleteBudget(ctx context.Context, groupID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM group_budgets WHERE travel_group_id = $1;`, groupID)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar orçamento: %w", err)
+	}
+	return checkRowsAffected(result, ErrBudgetNotFound)
+}
+
+// MarkCategoryAlerted registra o aviso de estouro da categoria. Devolve false se
+// o aviso já tinha sido registrado, para que só uma requisição o envie.
+func (r *postgresBudgetRepository) MarkCategoryAlerted(ctx context.Context, groupID int, category string) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE group_budget_categories SET alerted_at = NOW()
+        WHERE travel_group_id = $1 AND category = $2 AND alerted_at IS NULL;
+    `, groupID, category)
+	if err != nil {
+		return false, fmt.Errorf("erro ao registrar aviso do orçamento: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	return affected > 0, nil
+}
+
+// ClearCategoryAlert libera um novo aviso quando o gasto da categoria volta a
+// ficar abaixo do limite.
+func (r *postgresBudgetRepository) ClearCategoryAlert(ctx context.Context, groupID int, category string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	_, err := r.db.ExecContext(ctx, `
+        UPDATE group_budget_categories SET alerted_at = NULL
+        WHERE travel_group_id = $1 AND category = $2 AND alerted_at IS NOT NULL;
+    `, groupID, category)
+	if err != nil {
+		return fmt.Errorf("erro ao limpar aviso do orçamento: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+)
+
+// ErrFeedTokenNotFound indica que o token do feed de calendário não existe ou foi revogado.
+var ErrFeedTokenNotFound = errors.New("token do feed de calendário não encontrado")
+
+// CalendarFeedRepository guarda o token do feed de calendário de cada usuário.
+// Apenas o hash SHA-256 é gravado; o token em si só é conhecido na emissão.
+type CalendarFeedRepository interface {
+	RotateFeedToken(ctx context.Context, userID int) (string, error)
+	RevokeFeedToken(ctx context.Context, userID int) error
+	FindUserByFeedToken(ctx context.Context, token string) (int, error)
+}
+
+type postgresCalendarFeedRepository struct {
+	db *sql.DB
+}
+
+func NewCalendarFeedRepository(db *sql.DB) CalendarFeedRepository {
+	return &postgresCalendarFeedRepository{db: db}
+}
+
+// newOpaqueToken gera um token aleatório de 256 bits em hexadecimal.
+func newOpaqueToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken é o que fica gravado no banco no lugar de tokens opacos.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// RotateFeedToken gera um novo token para o usuário, invalidando o anterior.
+func (r *postgresCalendarFeedRepository) RotateFeedToken(ctx context.Context, userID int) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar token do feed: %w", err)
+	}
+
+	query := `
+        INSERT INTO calendar_feed_tokens (user_id, token_hash, created_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (user_id)
+        DO UPDATE SET token_hash = EXCLUDED.token_hash, created_at = NOW();
+    `
+	if _, err := r.db.ExecContext(ctx, query, userID, hashToken(token)); err != nil {
+		return "", fmt.Errorf("erro ao gravar token do feed: %w", err)
+	}
+	return token, nil
+}
+
+func (r *postgresCalendarFeedRepository) RevokeFeedToken(ctx context.Context, userID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM calendar_feed_tokens WHERE user_id = $1;`, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar token do feed: %w", err)
+	}
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if affected == 0 {
+		return ErrFeedTokenNotFound
+	}
+	return nil
+}
+
+func (r *postgresCalendarFeedRepository) FindUserByFeedToken(ctx context.Context, token string) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var userID int
+	query := `SELECT user_id FROM calendar_feed_tokens WHERE token_hash = $1;`
+	if err := r.db.QueryRowContext(ctx, query, hashToken(token)).Scan(&userID); err != nil {
+		if err == sql.ErrNoRows {
+			return 0, ErrFeedTokenNotFound
+		}
+		return 0, fmt.Errorf("erro ao buscar token do feed: %w", err)
+	}
+	return userID, nil
+}
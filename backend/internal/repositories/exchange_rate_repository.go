@@ -0,0 +1,92 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"time"
+)
+
+// ErrRateNotFound indica que não há taxa cadastrada para o par de moedas.
+var ErrRateNotFound = errors.New("taxa de câmbio não encontrada")
+
+type ExchangeRateRepository interface {
+	UpsertRates(ctx context.Context, rates []models.ExchangeRate) error
+	FindRate(ctx context.Context, from string, to string, on time.Time) (*models.ExchangeRate, error)
+}
+
+type postgresExchangeRateRepository struct {
+	db *sql.DB
+}
+
+func NewExchangeRateRepository(db *sql.DB) ExchangeRateRepository {
+	return &postgresExchangeRateRepository{db: db}
+}
+
+// UpsertRates grava as taxas em uma única transação, substituindo as já
+// existentes para o mesmo par e data.
+func (r *postgresExchangeRateRepository) UpsertRates(ctx context.Context, rates []models.ExchangeRate) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO exchange_rates (base_currency, quote_currency, rate, valid_on, created_at)
+        VALUES ($1, $2, $3, $4, NOW())
+        ON CONFLICT (base_currency, quote_currency, valid_on)
+        DO UPDATE SET rate = EXCLUDED.rate, created_at = NOW();
+    `
+	for _, rate := range rates {
+		_, err := tx.ExecContext(ctx, query, rate.BaseCurrency, rate.QuoteCurrency, rate.Rate, rate.ValidOn)
+		if err != nil {
+			return fmt.Errorf("erro ao gravar taxa %s/%s: %w", rate.BaseCurrency, rate.QuoteCurrency, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+	return nil
+}
+
+// FindRate busca a taxa mais próxima da data informada para o par, aceitando
+// também o par invertido (o chamador usa BaseCurrency para saber a direção).
+// Taxas até a data têm prioridade sobre taxas posteriores.
+func (r *postgresExchangeRateRepository) FindRate(ctx context.Context, from string, to string, on time.Time) (*models.ExchangeRate, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT base_currency, quote_currency, rate::text, valid_on
+        FROM exchange_rates
+        WHERE (base_currency = $1 AND quote_currency = $2)
+           OR (base_currency = $2 AND quote_currency = $1)
+        ORDER BY
+            CASE WHEN valid_on <= $3::date THEN 0 ELSE 1 END,
+            ABS(valid_on - $3::date),
+            (base_currency = $1) DESC
+        LIMIT 1;
+    `
+	var rate models.ExchangeRate
+	err := r.db.QueryRowContext(ctx, query, from, to, on).Scan(
+		&rate.BaseCurrency,
+		&rate.QuoteCurrency,
+		&rate.Rate,
+		&rate.ValidOn,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, i18n.Errorf(ErrRateNotFound, "error.exchange_rate_not_found_pair", from, to)
+		}
+		return nil, fmt.Errorf("erro ao buscar taxa de câmbio: %w", err)
+	}
+	return &rate, nil
+}
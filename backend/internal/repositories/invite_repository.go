@@ -0,0 +1,400 @@
+package repositories
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"project_lab/internal/models"
+	"strings"
+	"time"
+)
+
+// Erros de domínio dos convites, usados pelos handlers para escolher o status HTTP.
+var (
+	ErrInviteNotFound      = errors.New("convite não encontrado")
+	ErrInviteNotPending    = errors.New("convite não está mais disponível")
+	ErrInviteExpired       = errors.New("convite expirado")
+	ErrInviteExhausted     = errors.New("convite atingiu o limite de usos")
+	ErrInviteEmailMismatch = errors.New("convite destinado a outro e-mail")
+	ErrAlreadyGroupMember  = errors.New("usuário já é membro do grupo")
+)
+
+type InviteRepository interface {
+	CreateInvite(ctx context.Context, invite *models.GroupInvite) error
+	ListGroupInvites(ctx context.Context, groupID int) ([]models.GroupInvite, error)
+	ListPendingInvitesForUser(ctx context.Context, userID int) ([]models.PendingInviteDTO, error)
+	RevokeInvite(ctx context.Context, groupID int, inviteID int) error
+	AcceptInvite(ctx context.Context, code string, userID int) (int, error)
+	DeclineInvite(ctx context.Context, code string, userID int) error
+}
+
+type postgresInviteRepository struct {
+	db *sql.DB
+}
+
+func NewInviteRepository(db *sql.DB) InviteRepository {
+	return &postgresInviteRepository{db: db}
+}
+
+// generateInviteCode gera um código aleatório e difícil de adivinhar para o convite.
+func generateInviteCode() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// CreateInvite gera o código e insere o convite, preenchendo ID, Code, Status e CreatedAt.
+func (r *postgresInviteRepository) CreateInvite(ctx context.Context, invite *models.GroupInvite) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	code, err := generateInviteCode()
+	if err != nil {
+		return fmt.Errorf("erro ao gerar código do convite: %w", err)
+	}
+	invite.Code = code
+	invite.Status = models.InviteStatusPending
+
+	query := `
+        INSERT INTO group_invites
+        (travel_group_id, code, email, max_uses, uses, status, expires_at, created_by, created_at)
+        VALUES
+        ($1, $2, $3, $4, 0, $5, $6, $7, NOW())
+        RETURNING id, created_at;
+    `
+	err = r.db.QueryRowContext(ctx, query,
+		invite.TravelGroupID,
+		invite.Code,
+		invite.Email,
+		invite.MaxUses,
+		invite.Status,
+		invite.ExpiresAt,
+		invite.CreatedBy,
+	).Scan(&invite.ID, &invite.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("erro ao inserir convite: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresInviteRepository) ListGroupInvites(ctx context.Context, groupID int) ([]models.GroupInvite, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT
+            id,
+            travel_group_id,
+            code,
+            email,
+            max_uses,
+            uses,
+            status,
+            expires_at,
+            created_by,
+            created_at
+        FROM
+            group_invites
+        WHERE
+            travel_group_id = $1
+        ORDER BY created_at DESC;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar convites do grupo: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []models.GroupInvite{}
+	for rows.Next() {
+		var inv models.GroupInvite
+		var email sql.NullString
+		var maxUses sql.NullInt32
+		var expiresAt sql.NullTime
+
+		err := rows.Scan(
+			&inv.ID,
+			&inv.TravelGroupID,
+			&inv.Code,
+			&email,
+			&maxUses,
+			&inv.Uses,
+			&inv.Status,
+			&expiresAt,
+			&inv.CreatedBy,
+			&inv.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear convite: %w", err)
+		}
+
+		if email.Valid {
+			inv.Email = &email.String
+		}
+		if maxUses.Valid {
+			m := int(maxUses.Int32)
+			inv.MaxUses = &m
+		}
+		if expiresAt.Valid {
+			inv.ExpiresAt = &expiresAt.Time
+		}
+
+		invites = append(invites, inv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração dos convites: %w", err)
+	}
+
+	return invites, nil
+}
+
+// ListPendingInvitesForUser lista os convites por e-mail ainda válidos endereçados ao usuário.
+func (r *postgresInviteRepository) ListPendingInvitesForUser(ctx context.Context, userID int) ([]models.PendingInviteDTO, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT
+            gi.code,
+            tg.id,
+            tg.name,
+            creator.name AS invited_by,
+            gi.expires_at,
+            gi.created_at
+        FROM
+            group_invites gi
+        JOIN
+            users u ON LOWER(u.email) = LOWER(gi.email)
+        JOIN
+            travel_groups tg ON gi.travel_group_id = tg.id
+        JOIN
+            users creator ON gi.created_by = creator.id
+        WHERE
+            u.id = $1
+            AND gi.status = 'pending'
+            AND (gi.expires_at IS NULL OR gi.expires_at > NOW())
+            AND NOT EXISTS (
+                SELECT 1 FROM group_members gm
+                WHERE gm.travel_group_id = gi.travel_group_id AND gm.user_id = u.id AND gm.left_at IS NULL
+            )
+        ORDER BY gi.created_at DESC;
+    `
+
+	rows, err := r.db.QueryContext(ctx, query, userID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar convites pendentes: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []models.PendingInviteDTO{}
+	for rows.Next() {
+		var inv models.PendingInviteDTO
+		var expiresAt sql.NullTime
+
+		err := rows.Scan(
+			&inv.Code,
+			&inv.GroupID,
+			&inv.GroupName,
+			&inv.InvitedBy,
+			&expiresAt,
+			&inv.CreatedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear convite pendente: %w", err)
+		}
+
+		if expiresAt.Valid {
+			inv.ExpiresAt = &expiresAt.Time
+		}
+
+		invites = append(invites, inv)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração dos convites pendentes: %w", err)
+	}
+
+	return invites, nil
+}
+
+// RevokeInvite invalida um convite pendente do grupo.
+func (r *postgresInviteRepository) RevokeInvite(ctx context.Context, groupID int, inviteID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        UPDATE group_invites
+        SET status = 'revoked'
+        WHERE id = $1 AND travel_group_id = $2 AND status = 'pending';
+    `
+	result, err := r.db.ExecContext(ctx, query, inviteID, groupID)
+	if err != nil {
+		return fmt.Errorf("erro ao revogar convite: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrInviteNotFound
+	}
+	return nil
+}
+
+// lockedInvite contém os campos do convite lidos com SELECT ... FOR UPDATE.
+type lockedInvite struct {
+	id        int
+	groupID   int
+	email     sql.NullString
+	maxUses   sql.NullInt32
+	uses      int
+	status    string
+	expiresAt sql.NullTime
+}
+
+// lockInviteForUser bloqueia a linha do convite dentro da transação e valida
+// se ele ainda pode ser usado pelo usuário informado.
+func lockInviteForUser(ctx context.Context, tx *sql.Tx, code string, userID int) (*lockedInvite, error) {
+	query := `
+        SELECT id, travel_group_id, email, max_uses, uses, status, expires_at
+        FROM group_invites
+        WHERE code = $1
+        FOR UPDATE;
+    `
+	var inv lockedInvite
+	err := tx.QueryRowContext(ctx, query, code).Scan(
+		&inv.id,
+		&inv.groupID,
+		&inv.email,
+		&inv.maxUses,
+		&inv.uses,
+		&inv.status,
+		&inv.expiresAt,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrInviteNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar convite: %w", err)
+	}
+
+	if inv.status != models.InviteStatusPending {
+		return nil, ErrInviteNotPending
+	}
+	if inv.expiresAt.Valid && inv.expiresAt.Time.Before(time.Now()) {
+		return nil, ErrInviteExpired
+	}
+	if inv.maxUses.Valid && inv.uses >= int(inv.maxUses.Int32) {
+		return nil, ErrInviteExhausted
+	}
+
+	if inv.email.Valid {
+		var userEmail string
+		err := tx.QueryRowContext(ctx, `SELECT email FROM users WHERE id = $1`, userID).Scan(&userEmail)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao buscar e-mail do usuário: %w", err)
+		}
+		if !strings.EqualFold(userEmail, inv.email.String) {
+			return nil, ErrInviteEmailMismatch
+		}
+	}
+
+	return &inv, nil
+}
+
+// AcceptInvite adiciona o usuário ao grupo do convite em uma única transação
+// e retorna o ID do grupo.
+func (r *postgresInviteRepository) AcceptInvite(ctx context.Context, code string, userID int) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	inv, err := lockInviteForUser(ctx, tx, code, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	// Um ex-membro que volta por convite reativa a linha antiga como participante.
+	memberQuery := `
+        INSERT INTO group_members (travel_group_id, user_id, created_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (travel_group_id, user_id) DO UPDATE
+        SET role = 'participant', left_at = NULL, created_at = NOW()
+        WHERE group_members.left_at IS NOT NULL;
+    `
+	result, err := tx.ExecContext(ctx, memberQuery, inv.groupID, userID)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao adicionar membro ao grupo: %w", err)
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if rowsAffected == 0 {
+		return 0, ErrAlreadyGroupMember
+	}
+
+	// Convites por e-mail são de uso único; códigos compartilháveis só
+	// deixam de estar pendentes quando atingem max_uses.
+	uses := inv.uses + 1
+	status := models.InviteStatusPending
+	if inv.email.Valid || (inv.maxUses.Valid && uses >= int(inv.maxUses.Int32)) {
+		status = models.InviteStatusAccepted
+	}
+
+	updateQuery := `UPDATE group_invites SET uses = $2, status = $3 WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, updateQuery, inv.id, uses, status); err != nil {
+		return 0, fmt.Errorf("erro ao atualizar uso do convite: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+
+	return inv.groupID, nil
+}
+
+// DeclineInvite recusa um convite por e-mail. Códigos compartilháveis não
+// podem ser recusados, pois não pertencem a um único convidado.
+func (r *postgresInviteRepository) DeclineInvite(ctx context.Context, code string, userID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	inv, err := lockInviteForUser(ctx, tx, code, userID)
+	if err != nil {
+		return err
+	}
+	if !inv.email.Valid {
+		return ErrInviteEmailMismatch
+	}
+
+	updateQuery := `UPDATE group_invites SET status = 'declined' WHERE id = $1`
+	if _, err := tx.ExecContext(ctx, updateQuery, inv.id); err != nil {
+		return fmt.Errorf("erro ao recusar convite: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,226 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"project_lab/internal/models"
+)
+
+// ErrItineraryItemNotFound indica que o item não existe no grupo informado.
+var ErrItineraryItemNotFound = errors.New("item do roteiro não encontrado")
+
+type ItineraryRepository interface {
+	CreateItem(ctx context.Context, item *models.ItineraryItem) error
+	UpdateItem(ctx context.Context, item *models.ItineraryItem) error
+	DeleteItem(ctx context.Context, groupID int, itemID int) error
+	GetItem(ctx context.Context, groupID int, itemID int) (*models.ItineraryItem, error)
+	ListItems(ctx context.Context, groupID int) ([]models.ItineraryItem, error)
+	GetDestinationName(ctx context.Context, groupID int, destinationID int) (string, error)
+}
+
+type postgresItineraryRepository struct {
+	db *sql.DB
+}
+
+func NewItineraryRepository(db *sql.DB) ItineraryRepository {
+	return &postgresItineraryRepository{db: db}
+}
+
+const itineraryItemColumns = `
+            i.id,
+            i.travel_group_id,
+            i.destination_id,
+            d.name,
+            i.activity,
+            i.starts_at,
+            i.ends_at,
+            COALESCE(i.notes, ''),
+            i.estimated_cost,
+            i.currency,
+            i.created_by,
+            i.created_at`
+
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanItineraryItem(row rowScanner) (*models.ItineraryItem, error) {
+	var item models.ItineraryItem
+	var destinationID sql.NullInt32
+	var destinationName sql.NullString
+	var estimatedCost sql.NullString
+
+	err := row.Scan(
+		&item.ID,
+		&item.TravelGroupID,
+		&destinationID,
+		&destinationName,
+		&item.Activity,
+		&item.StartsAt,
+		&item.EndsAt,
+		&item.Notes,
+		&estimatedCost,
+		&item.Currency,
+		&item.CreatedBy,
+		&item.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if destinationID.Valid {
+		id := int(destinationID.Int32)
+		item.DestinationID = &id
+	}
+	if destinationName.Valid {
+		item.DestinationName = &destinationName.String
+	}
+	if estimatedCost.Valid {
+		cost, err := models.ParseMoney(estimatedCost.String)
+		if err != nil {
+			return nil, fmt.Errorf("custo estimado inválido no item %d: %w", item.ID, err)
+		}
+		item.EstimatedCost = &cost
+	}
+	item.OverlapsWith = []int{}
+	return &item, nil
+}
+
+// CreateItem insere o item e preenche ID e CreatedAt no struct.
+func (r *postgresItineraryRepository) CreateItem(ctx context.Context, item *models.ItineraryItem) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        INSERT INTO itinerary_items
+        (travel_group_id, destination_id, activity, starts_at, ends_at, notes, estimated_cost, currency, created_by, created_at)
+        VALUES
+        ($1, $2, $3, $4, $5, $6, $7, $8, $9, NOW())
+        RETURNING id, created_at;
+    `
+	err := r.db.QueryRowContext(ctx, query,
+		item.TravelGroupID,
+		item.DestinationID,
+		item.Activity,
+		item.StartsAt,
+		item.EndsAt,
+		item.Notes,
+		item.EstimatedCost,
+		item.Currency,
+		item.CreatedBy,
+	).Scan(&item.ID, &item.CreatedAt)
+
+	if err != nil {
+		return fmt.Errorf("erro ao inserir item do roteiro: %w", err)
+	}
+	return nil
+}
+
+// UpdateItem substitui os dados editáveis do item.
+func (r *postgresItineraryRepository) UpdateItem(ctx context.Context, item *models.ItineraryItem) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        UPDATE itinerary_items
+        SET destination_id = $3, activity = $4, starts_at = $5, ends_at = $6,
+            notes = $7, estimated_cost = $8, currency = $9
+        WHERE travel_group_id = $1 AND id = $2;
+    `
+	result, err := r.db.ExecContext(ctx, query,
+		item.TravelGroupID,
+		item.ID,
+		item.DestinationID,
+		item.Activity,
+		item.StartsAt,
+		item.EndsAt,
+		item.Notes,
+		item.EstimatedCost,
+		item.Currency,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao alterar item do roteiro: %w", err)
+	}
+	return checkRowsAffected(result, ErrItineraryItemNotFound)
+}
+
+func (r *postgresItineraryRepository) DeleteItem(ctx context.Context, groupID int, itemID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM itinerary_items WHERE travel_group_id = $1 AND id = $2;`, groupID, itemID)
+	if err != nil {
+		return fmt.Errorf("erro ao remover item do roteiro: %w", err)
+	}
+	return checkRowsAffected(result, ErrItineraryItemNotFound)
+}
+
+func (r *postgresItineraryRepository) GetItem(ctx context.Context, groupID int, itemID int) (*models.ItineraryItem, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT` + itineraryItemColumns + `
+        FROM itinerary_items i
+        LEFT JOIN destinations d ON d.id = i.destination_id
+        WHERE i.travel_group_id = $1 AND i.id = $2;
+    `
+	item, err := scanItineraryItem(r.db.QueryRowContext(ctx, query, groupID, itemID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrItineraryItemNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar item do roteiro: %w", err)
+	}
+	return item, nil
+}
+
+// ListItems retorna os itens do roteiro do grupo em ordem cronológica.
+func (r *postgresItineraryRepository) ListItems(ctx context.Context, groupID int) ([]models.ItineraryItem, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT` + itineraryItemColumns + `
+        FROM itinerary_items i
+        LEFT JOIN destinations d ON d.id = i.destination_id
+        WHERE i.travel_group_id = $1
+        ORDER BY i.starts_at ASC, i.ends_at ASC, i.id ASC;
+    `
+	rows, err := r.db.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar roteiro do grupo: %w", err)
+	}
+	defer rows.Close()
+
+	items := []models.ItineraryItem{}
+	for rows.Next() {
+		item, err := scanItineraryItem(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear item do roteiro: %w", err)
+		}
+		items = append(items, *item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro durante a iteração do roteiro: %w", err)
+	}
+	return items, nil
+}
+
+// GetDestinationName retorna o nome do destino, garantindo que ele pertence ao grupo.
+func (r *postgresItineraryRepository) GetDestinationName(ctx context.Context, groupID int, destinationID int) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var name string
+	query := `SELECT name FROM destinations WHERE id = $1 AND travel_group_id = $2;`
+	if err := r.db.QueryRowContext(ctx, query, destinationID, groupID).Scan(&name); err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrDestinationNotFound
+		}
+		return "", fmt.Errorf("erro ao buscar destino: %w", err)
+	}
+	return name, nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"project_lab/internal/models"
+	"time"
+)
+
+// LoginAttemptRepository guarda o contador de falhas de login por e-mail (que
+// sobrevive a reinícios, ao contrário do limite por IP em memória) e a
+// auditoria das tentativas malsucedidas.
+type LoginAttemptRepository interface {
+	GetLockedUntil(ctx context.Context, email string) (*time.Time, error)
+	RecordFailedLogin(ctx context.Context, email string, window time.Duration) (failures int, err error)
+	LockUntil(ctx context.Context, email string, until time.Time) error
+	ResetFailedLogins(ctx context.Context, email string) error
+	AddAuditEntry(ctx context.Context, entry *models.LoginAuditEntry) error
+	ListAuditEntries(ctx context.Context, userID, limit int) ([]models.LoginAuditEntry, error)
+}
+
+type postgresLoginAttemptRepository struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRepository(db *sql.DB) LoginAttemptRepository {
+	return &postgresLoginAttemptRepository{db: db}
+}
+
+func (r *postgresLoginAttemptRepository) GetLockedUntil(ctx context.Context, email string) (*time.Time, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var lockedUntil sql.NullTime
+	err := r.db.QueryRowContext(ctx, `SELECT locked_until FROM login_throttles WHERE email = $1;`, email).Scan(&lockedUntil)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("erro ao consultar bloqueio de login: %w", err)
+	}
+	if !lockedUntil.Valid {
+		return nil, nil
+	}
+	return &lockedUntil.Time, nil
+}
+
+// RecordFailedLogin soma uma falha ao e-mail e devolve o total. Falhas mais
+// antigas que window não contam: o contador recomeça. O incremento é atômico,
+// então tentativas em paralelo não se perdem.
+func (r *postgresLoginAttemptRepository) RecordFailedLogin(ctx context.Context, email string, window time.Duration) (int, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        INSERT INTO login_throttles (email, failed_count, last_failed_at)
+        VALUES ($1, 1, NOW())
+        ON CONFLICT (email) DO UPDATE SET
+            failed_count = CASE
+                WHEN login_throttles.last_failed_at < NOW() - make_interval(secs => $2) THEN 1
+                ELSE login_throttles.failed_count + 1
+            END,
+            last_failed_at = NOW()
+        RETURNING failed_count;
+    `
+	var failures int
+	if err := r.db.QueryRowContext(ctx, query, email, window.Seconds()).Scan(&failures); err != nil {
+		return 0, fmt.Errorf("erro ao registrar falha de login: %w", err)
+	}
+	return failures, nil
+}
+
+// LockUntil bloqueia o e-mail até a data informada (nunca encurta um bloqueio vigente).
+func (r *postgresLoginAttemptRepository) LockUntil(ctx context.Context, email string, until time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        UPDATE login_throttles
+        SET locked_until = GREATEST(COALESCE(locked_until, $2), $2)
+        WHERE email = $1;
+    `
+	if _, err := r.db.ExecContext(ctx, query, email, until); err != nil {
+		return fmt.Errorf("erro ao bloquear login: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresLoginAttemptRepository) ResetFailedLogins(ctx context.Context, email string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM login_throttles WHERE email = $1;`, email); err != nil {
+		return fmt.Errorf("erro ao zerar falhas de login: %w", err)
+	}
+	return nil
+}
+
+func (r *postgresLoginAttemptRepository) AddAuditEntry(ctx context.Context, entry *models.LoginAuditEntry) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        INSERT INTO login_audit (user_id, email, ip, user_agent, reason, created_at)
+        VALUES ($1, $2, $3, $4, $5, NOW())
+        RETURNING id, created_at;
+    `
+	err := r.db.QueryRowContext(ctx, query, entry.UserID, entry.Email, entry.IP, entry.UserAgent, entry.Reason).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar auditoria de login: %w", err)
+	}
+	return nil
+}
+
+// ListAuditEntries devolve as tentativas malsucedidas mais recentes na conta do usuário.
+func (r *postgresLoginAttemptRepository) ListAuditEntries(ctx context.Context, userID, limit int) ([]models.LoginAuditEntry, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT id, user_id, email, ip, user_agent, reason, created_at
+        FROM login_audit
+        WHERE user_id = $1
+        ORDER BY created_at DESC, id DESC
+        LIMIT $2;
+    `
+	rows, err := r.db.QueryContext(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar auditoria de login: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.LoginAuditEntry{}
+	for rows.Next() {
+		var e models.LoginAuditEntry
+		if err := rows.Scan(&e.ID, &e.UserID, &e.Email, &e.IP, &e.UserAgent, &e.Reason, &e.CreatedAt); err != nil {
+			return nil, fmt.Errorf("erro ao ler auditoria de login: %w", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,166 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	// ErrRefreshTokenInvalid indica um refresh token inexistente, expirado ou revogado.
+	ErrRefreshTokenInvalid = errors.New("refresh token inválido ou expirado")
+	// ErrRefreshTokenReused indica que um refresh token já trocado foi apresentado
+	// de novo; a família inteira é revogada, pois o token pode ter vazado.
+	ErrRefreshTokenReused = errors.New("refresh token já utilizado")
+)
+
+// RefreshTokenRepository guarda os refresh tokens emitidos. Cada login inicia
+// uma família; cada troca em /auth/refresh consome o token atual e emite o
+// próximo da mesma família. Assim como no feed de calendário, só o hash é gravado.
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, userID int, expiresAt time.Time) (string, error)
+	RotateRefreshToken(ctx context.Context, token string, expiresAt time.Time) (userID int, next string, err error)
+	RevokeRefreshTokenFamily(ctx context.Context, token string) error
+	RevokeUserRefreshTokens(ctx context.Context, userID int) error
+}
+
+type postgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRepository(db *sql.DB) RefreshTokenRepository {
+	return &postgresRefreshTokenRepository{db: db}
+}
+
+// CreateRefreshToken emite o primeiro token de uma nova família.
+func (r *postgresRefreshTokenRepository) CreateRefreshToken(ctx context.Context, userID int, expiresAt time.Time) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	familyID, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar refresh token: %w", err)
+	}
+	token, err := newOpaqueToken()
+	if err != nil {
+		return "", fmt.Errorf("erro ao gerar refresh token: %w", err)
+	}
+
+	query := `
+        INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, NOW());
+    `
+	if _, err := r.db.ExecContext(ctx, query, userID, familyID, hashToken(token), expiresAt); err != nil {
+		return "", fmt.Errorf("erro ao gravar refresh token: %w", err)
+	}
+	return token, nil
+}
+
+// RotateRefreshToken consome o token e emite o próximo da família. A linha fica
+// bloqueada durante a troca, então duas trocas simultâneas do mesmo token são
+// tratadas como reuso.
+func (r *postgresRefreshTokenRepository) RotateRefreshToken(ctx context.Context, token string, expiresAt time.Time) (int, string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, "", err
+	}
+	defer tx.Rollback()
+
+	var id, userID int
+	var familyID string
+	var current time.Time
+	var usedAt, revokedAt sql.NullTime
+	query := `
+        SELECT id, user_id, family_id, expires_at, used_at, revoked_at
+        FROM refresh_tokens
+        WHERE token_hash = $1
+        FOR UPDATE;
+    `
+	err = tx.QueryRowContext(ctx, query, hashToken(token)).Scan(&id, &userID, &familyID, &current, &usedAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return 0, "", ErrRefreshTokenInvalid
+		}
+		return 0, "", fmt.Errorf("erro ao buscar refresh token: %w", err)
+	}
+
+	if revokedAt.Valid || time.Now().After(current) {
+		return 0, "", ErrRefreshTokenInvalid
+	}
+
+	if usedAt.Valid {
+		if err := revokeFamily(ctx, tx, familyID); err != nil {
+			return 0, "", err
+		}
+		if err := tx.Commit(); err != nil {
+			return 0, "", err
+		}
+		return 0, "", ErrRefreshTokenReused
+	}
+
+	next, err := newOpaqueToken()
+	if err != nil {
+		return 0, "", fmt.Errorf("erro ao gerar refresh token: %w", err)
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET used_at = NOW() WHERE id = $1;`, id); err != nil {
+		return 0, "", fmt.Errorf("erro ao consumir refresh token: %w", err)
+	}
+	insert := `
+        INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, created_at)
+        VALUES ($1, $2, $3, $4, NOW());
+    `
+	if _, err := tx.ExecContext(ctx, insert, userID, familyID, hashToken(next), expiresAt); err != nil {
+		return 0, "", fmt.Errorf("erro ao gravar refresh token: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, "", err
+	}
+	return userID, next, nil
+}
+
+// RevokeRefreshTokenFamily revoga o token e todos os outros da mesma família (logout).
+func (r *postgresRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, token string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var familyID string
+	err := r.db.QueryRowContext(ctx, `SELECT family_id FROM refresh_tokens WHERE token_hash = $1;`, hashToken(token)).Scan(&familyID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrRefreshTokenInvalid
+		}
+		return fmt.Errorf("erro ao buscar refresh token: %w", err)
+	}
+	return revokeFamily(ctx, r.db, familyID)
+}
+
+// RevokeUserRefreshTokens encerra todas as sessões do usuário (por exemplo, após trocar a senha).
+func (r *postgresRefreshTokenRepository) RevokeUserRefreshTokens(ctx context.Context, userID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE user_id = $1 AND revoked_at IS NULL;`
+	if _, err := r.db.ExecContext(ctx, query, userID); err != nil {
+		return fmt.Errorf("erro ao revogar refresh tokens: %w", err)
+	}
+	return nil
+}
+
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+func revokeFamily(ctx context.Context, db execer, familyID string) error {
+	query := `UPDATE refresh_tokens SET revoked_at = NOW() WHERE family_id = $1 AND revoked_at IS NULL;`
+	if _, err := db.ExecContext(ctx, query, familyID); err != nil {
+		return fmt.Errorf("erro ao revogar refresh tokens: %w", err)
+	}
+	return nil
+}
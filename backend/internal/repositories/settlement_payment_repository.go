@@ -0,0 +1,231 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"project_lab/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// ErrPaymentNotFound indica que o pagamento não existe no grupo informado.
+var ErrPaymentNotFound = errors.New("pagamento não encontrado")
+
+// SettlementPaymentRepository guarda os pagamentos de acerto feitos entre os
+// membros do grupo.
+type SettlementPaymentRepository interface {
+	CreatePayment(ctx context.Context, payment *models.SettlementPayment) error
+	ListPayments(ctx context.Context, groupID int) ([]models.SettlementPayment, error)
+	GetPayment(ctx context.Context, groupID int, paymentID int) (*models.SettlementPayment, error)
+	DeletePayment(ctx context.Context, groupID int, paymentID int) error
+}
+
+// GroupLedger reúne, lidos na mesma transação, os dados que entram nos saldos
+// do grupo: a moeda base, os membros (inclusive ex-membros), as despesas e os
+// pagamentos de acerto.
+type GroupLedger struct {
+	BaseCurrency string
+	Members      []models.GroupMemberDTO
+	Expenses     []models.ExpenseDTO
+	Payments     []models.SettlementPayment
+}
+
+type postgresSettlementPaymentRepository struct {
+	db *sql.DB
+}
+
+func NewSettlementPaymentRepository(db *sql.DB) SettlementPaymentRepository {
+	return &postgresSettlementPaymentRepository{db: db}
+}
+
+// paymentColumns são as colunas lidas por scanPayment.
+const paymentColumns = `p.id, p.travel_group_id, p.from_user_id, uf.name, p.to_user_id, ut.name, p.amount, p.currency, p.created_by, p.created_at`
+
+func scanPayment(row rowScanner) (models.SettlementPayment, error) {
+	var p models.SettlementPayment
+	var createdBy sql.NullInt32
+	err := row.Scan(&p.ID, &p.TravelGroupID, &p.FromUserID, &p.FromName, &p.ToUserID, &p.ToName, &p.Amount, &p.Currency, &createdBy, &p.CreatedAt)
+	if err != nil {
+		return p, err
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int32)
+		p.CreatedBy = &id
+	}
+	return p, nil
+}
+
+// CreatePayment grava o pagamento. As linhas dos dois membros ficam travadas
+// até o fim da transação, para que nenhum deles saia do grupo no meio do
+// registro; se algum já não for membro ativo, devolve ErrMemberNotFound.
+func (r *postgresSettlementPaymentRepository) CreatePayment(ctx context.Context, payment *models.SettlementPayment) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação para pagamento: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockActiveMembers(ctx, tx, payment.TravelGroupID, []int{payment.FromUserID, payment.ToUserID}); err != nil {
+		return err
+	}
+
+	query := `
+        INSERT INTO settlement_payments
+        (travel_group_id, from_user_id, to_user_id, amount, currency, created_by, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, NOW())
+        RETURNING id, created_at;
+    `
+	err = tx.QueryRowContext(ctx, query,
+		payment.TravelGroupID,
+		payment.FromUserID,
+		payment.ToUserID,
+		payment.Amount,
+		payment.Currency,
+		payment.CreatedBy,
+	).Scan(&payment.ID, &payment.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("erro ao inserir pagamento: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação do pagamento: %w", err)
+	}
+	return nil
+}
+
+// ListPayments lista os pagamentos do grupo, do mais recente ao mais antigo.
+func (r *postgresSettlementPaymentRepository) ListPayments(ctx context.Context, groupID int) ([]models.SettlementPayment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return listPayments(ctx, r.db, groupID)
+}
+
+func listPayments(ctx context.Context, q queryer, groupID int) ([]models.SettlementPayment, error) {
+	query := `
+        SELECT ` + paymentColumns + `
+        FROM settlement_payments p
+        JOIN users uf ON uf.id = p.from_user_id
+        JOIN users ut ON ut.id = p.to_user_id
+        WHERE p.travel_group_id = $1
+        ORDER BY p.created_at DESC, p.id DESC;
+    `
+	rows, err := q.QueryContext(ctx, query, groupID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar pagamentos: %w", err)
+	}
+	defer rows.Close()
+
+	payments := []models.SettlementPayment{}
+	for rows.Next() {
+		p, err := scanPayment(rows)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao escanear pagamento: %w", err)
+		}
+		payments = append(payments, p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração dos pagamentos: %w", err)
+	}
+	return payments, nil
+}
+
+// GetPayment busca um pagamento do grupo.
+func (r *postgresSettlementPaymentRepository) GetPayment(ctx context.Context, groupID int, paymentID int) (*models.SettlementPayment, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT ` + paymentColumns + `
+        FROM settlement_payments p
+        JOIN users uf ON uf.id = p.from_user_id
+        JOIN users ut ON ut.id = p.to_user_id
+        WHERE p.travel_group_id = $1 AND p.id = $2;
+    `
+	p, err := scanPayment(r.db.QueryRowContext(ctx, query, groupID, paymentID))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrPaymentNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar pagamento: %w", err)
+	}
+	return &p, nil
+}
+
+// DeletePayment apaga o pagamento. Como ele mexe no saldo dos dois membros, as
+// linhas deles ficam travadas como em CreatePayment (mesmo que um deles já
+// tenha saído do grupo).
+func (r *postgresSettlementPaymentRepository) DeletePayment(ctx context.Context, groupID int, paymentID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.ExecContext(ctx, `
+        SELECT 1 FROM group_members gm
+        JOIN settlement_payments p ON p.travel_group_id = gm.travel_group_id
+            AND gm.user_id IN (p.from_user_id, p.to_user_id)
+        WHERE p.travel_group_id = $1 AND p.id = $2
+        ORDER BY gm.user_id
+        FOR SHARE OF gm;
+    `, groupID, paymentID)
+	if err != nil {
+		return fmt.Errorf("erro ao travar membros do pagamento: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM settlement_payments WHERE travel_group_id = $1 AND id = $2;`, groupID, paymentID)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar pagamento: %w", err)
+	}
+	if err := checkRowsAffected(result, ErrPaymentNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+	return nil
+}
+
+// lockActiveMembers trava (FOR SHARE) as linhas dos membros cujo saldo a
+// transação vai alterar. A saída do grupo (RemoveMember) trava a linha do
+// membro FOR UPDATE, então espera essa transação terminar e confere o saldo já
+// com ela. Devolve ErrMemberNotFound se algum deles não for membro ativo.
+func lockActiveMembers(ctx context.Context, tx *sql.Tx, groupID int, userIDs []int) error {
+	distinct := map[int]bool{}
+	for _, id := range userIDs {
+		distinct[id] = true
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT user_id FROM group_members
+        WHERE travel_group_id = $1 AND user_id = ANY($2) AND left_at IS NULL
+        ORDER BY user_id
+        FOR SHARE;
+    `, groupID, pq.Array(userIDs))
+	if err != nil {
+		return fmt.Errorf("erro ao travar membros do grupo: %w", err)
+	}
+	defer rows.Close()
+
+	count := 0
+	for rows.Next() {
+		count++
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao travar membros do grupo: %w", err)
+	}
+	if count != len(distinct) {
+		return ErrMemberNotFound
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// DefaultQueryTimeout é o prazo padrão de cada operação no banco.
+const DefaultQueryTimeout = 10 * time.Second
+
+var queryTimeout = DefaultQueryTimeout
+
+// SetQueryTimeout define o prazo de cada operação dos repositórios; zero
+// desativa o limite e deixa valer apenas o contexto recebido.
+func SetQueryTimeout(d time.Duration) {
+	queryTimeout = d
+}
+
+// withTimeout aplica o prazo por operação sobre o contexto da requisição. Se o
+// cliente desistir antes, o cancelamento do contexto pai interrompe a consulta.
+func withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if queryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, queryTimeout)
+}
+
+// IsQueryCanceled indica se o erro veio de uma consulta interrompida no
+// servidor (query_canceled). O lib/pq devolve esse erro, e não ctx.Err(),
+// quando o contexto expira com a consulta em andamento.
+func IsQueryCanceled(err error) bool {
+	var pqErr *pq.Error
+	return errors.As(err, &pqErr) && pqErr.Code == "57014"
+}
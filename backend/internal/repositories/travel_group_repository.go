@@ -1,25 +1,54 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"project_lab/internal/models"
 	"strconv"
 	"strings"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// Erros de domínio dos recursos do grupo, usados pelos handlers para escolher o status HTTP.
+var (
+	ErrGroupNotFound       = errors.New("grupo não encontrado")
+	ErrDestinationNotFound = errors.New("destino não encontrado")
+	ErrExpenseNotFound     = errors.New("despesa não encontrada")
+	ErrMemberNotFound      = errors.New("membro não encontrado no grupo")
+	// ErrItineraryOutsideDates indica que a alteração das datas deixaria itens do roteiro fora da viagem.
+	ErrItineraryOutsideDates = errors.New("há itens do roteiro fora das novas datas da viagem")
 )
 
 type TravelGroupRepository interface {
-	ListGroupsByUserId(userID int) ([]models.TravelGroupListItem, error)
-	CreateTravelGroup(group *models.TravelGroup) error
-	GetGroupDetails(groupID int, userID int) (*models.TravelGroupDetails, error)
-	ListGroupMembers(groupID int) ([]models.GroupMemberDTO, error)
-	ListGroupDestinations(groupID int) ([]models.DestinationDTO, error)
-	ListGroupVotings(groupID int, userID int) ([]models.VotingDTO, error)
-	ListGroupExpenses(groupID int) ([]models.ExpenseDTO, error)
-	CreateDestination(destination *models.Destination) error
-	CreateVoting(groupID int, question string, optionsJSON string) (int, error)
-	CreateExpense(expense *models.Expense) error
+	ListGroupsByUserId(ctx context.Context, userID int) ([]models.TravelGroupListItem, error)
+	CreateTravelGroup(ctx context.Context, group *models.TravelGroup) error
+	GetGroupDetails(ctx context.Context, groupID int, userID int) (*models.TravelGroupDetails, error)
+	ListGroupMembers(ctx context.Context, groupID int, includeFormer bool) ([]models.GroupMemberDTO, error)
+	ListGroupDestinations(ctx context.Context, groupID int) ([]models.DestinationDTO, error)
+	ListGroupVotings(ctx context.Context, groupID int, userID int) ([]models.VotingDTO, error)
+	ListGroupExpenses(ctx context.Context, groupID int) ([]models.ExpenseDTO, error)
+	CreateDestination(ctx context.Context, destination *models.Destination) error
+	CreateVoting(ctx context.Context, voting *models.Voting) error
+	CreateExpense(ctx context.Context, expense *models.Expense) error
+	AreGroupMembers(ctx context.Context, groupID int, userIDs []int) (bool, error)
+	GetGroupBaseCurrency(ctx context.Context, groupID int) (string, error)
+	UpdateTravelGroup(ctx context.Context, group *models.TravelGroup) error
+	DeleteTravelGroup(ctx context.Context, groupID int) error
+	GetDestination(ctx context.Context, groupID int, destinationID int) (*models.Destination, error)
+	UpdateDestination(ctx context.Context, destination *models.Destination) error
+	DeleteDestination(ctx context.Context, groupID int, destinationID int) error
+	GetExpense(ctx context.Context, groupID int, expenseID int) (*models.Expense, error)
+	UpdateExpense(ctx context.Context, expense *models.Expense) error
+	DeleteExpense(ctx context.Context, groupID int, expenseID int) error
+	GetMemberRole(ctx context.Context, groupID int, userID int) (string, error)
+	UpdateMemberRole(ctx context.Context, groupID int, userID int, role string) error
+	TransferOwnership(ctx context.Context, groupID int, newOwnerID int) error
+	RemoveMember(ctx context.Context, groupID int, userID int, actorID int, check func(MemberRemoval) error) error
 }
 
 type postgresTravelGroupRepository struct {
@@ -31,10 +60,12 @@ func NewTravelGroupRepository(db *sql.DB) TravelGroupRepository {
 }
 
 // this method creates a travel group and sets its creator
-func (r *postgresTravelGroupRepository) CreateTravelGroup(group *models.TravelGroup) error {
+func (r *postgresTravelGroupRepository) CreateTravelGroup(ctx context.Context, group *models.TravelGroup) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
 	// start of the transaction
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("falha ao iniciar transação: %w", err)
 	}
@@ -43,26 +74,27 @@ func (r *postgresTravelGroupRepository) CreateTravelGroup(group *models.TravelGr
 
 	query := `
         INSERT INTO travel_groups 
-        (name,description , creator_id, start_date, end_date, created_at) 
+        (name,description , creator_id, start_date, end_date, base_currency, created_at) 
         VALUES 
-        ($1, $2, $3, $4, $5, NOW())
+        ($1, $2, $3, $4, $5, $6, NOW())
         RETURNING id
     `
-	err = tx.QueryRow(query,
+	err = tx.QueryRowContext(ctx, query,
 		group.Name,
 		group.Description,
 		group.CreatorID,
 		group.StartDate,
 		group.EndDate,
+		group.BaseCurrency,
 	).Scan(&group.ID)
 
 	if err != nil {
 		return fmt.Errorf("erro ao inserir grupo de viagem: %w", err)
 	}
 
-	memberQuery := `INSERT INTO group_members (travel_group_id, user_id, created_at) VALUES ($1, $2, NOW())`
+	memberQuery := `INSERT INTO group_members (travel_group_id, user_id, role, created_at) VALUES ($1, $2, 'organizer', NOW())`
 
-	_, err = tx.Exec(memberQuery, group.ID, group.CreatorID)
+	_, err = tx.ExecContext(ctx, memberQuery, group.ID, group.CreatorID)
 
 	if err != nil {
 		return fmt.Errorf("erro ao adicionar criador como membro: %w", err)
@@ -76,7 +108,10 @@ func (r *postgresTravelGroupRepository) CreateTravelGroup(group *models.TravelGr
 	return nil
 }
 
-func (r *postgresTravelGroupRepository) ListGroupsByUserId(userId int) ([]models.TravelGroupListItem, error) {
+func (r *postgresTravelGroupRepository) ListGroupsByUserId(ctx context.Context, userId int) ([]models.TravelGroupListItem, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
 		SELECT 
 			tg.id,
@@ -86,18 +121,18 @@ func (r *postgresTravelGroupRepository) ListGroupsByUserId(userId int) ([]models
 			tg.end_date,
 			tg.creator_id,
 			u.name AS creator_name,
-			(SELECT COUNT(*) FROM group_members gm WHERE gm.travel_group_id = tg.id) AS member_count
+			(SELECT COUNT(*) FROM group_members gm WHERE gm.travel_group_id = tg.id AND gm.left_at IS NULL) AS member_count
 		FROM 
 			travel_groups tg
 		JOIN 
 			users u ON tg.creator_id = u.id
 		WHERE
-			-- O usuário é o criador OU é um membro
-			tg.creator_id = $1 OR tg.id IN (SELECT travel_group_id FROM group_members WHERE user_id = $1)
+			-- O usuário é membro (o criador sempre é, e não pode sair sem transferir o grupo)
+			tg.id IN (SELECT travel_group_id FROM group_members WHERE user_id = $1 AND left_at IS NULL)
 		ORDER BY tg.start_date DESC;
 	`
 
-	rows, err := r.db.Query(query, userId)
+	rows, err := r.db.QueryContext(ctx, query, userId)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao executar query: %w", err)
 	}
@@ -139,10 +174,12 @@ func (r *postgresTravelGroupRepository) ListGroupsByUserId(userId int) ([]models
 	return groups, nil
 }
 
-func (r *postgresTravelGroupRepository) GetGroupDetails(groupID int, userID int) (*models.TravelGroupDetails, error) {
+func (r *postgresTravelGroupRepository) GetGroupDetails(ctx context.Context, groupID int, userID int) (*models.TravelGroupDetails, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
 	// Consulta SQL para obter detalhes básicos, nome do criador e contagem de membros.
-	// Também valida se o usuário (userID) é membro/criador.
+	// Também valida se o usuário (userID) é membro e traz o papel dele no grupo.
 	query := `
         SELECT 
             tg.id,
@@ -150,36 +187,41 @@ func (r *postgresTravelGroupRepository) GetGroupDetails(groupID int, userID int)
             tg.description,
             tg.start_date,
             tg.end_date,
+            tg.base_currency,
             tg.creator_id,
             u.name AS creator_name,
-            (SELECT COUNT(*) FROM group_members gm WHERE gm.travel_group_id = tg.id) AS member_count
+            (SELECT COUNT(*) FROM group_members m WHERE m.travel_group_id = tg.id AND m.left_at IS NULL) AS member_count,
+            gm.role
         FROM 
             travel_groups tg
         JOIN 
             users u ON tg.creator_id = u.id
+        -- Só retorna os detalhes se o usuário for membro
+        JOIN
+            group_members gm ON gm.travel_group_id = tg.id AND gm.user_id = $2 AND gm.left_at IS NULL
         WHERE
-            tg.id = $1
-            -- Verifica se o usuário é membro ou criador antes de retornar os detalhes
-            AND (tg.creator_id = $2 OR tg.id IN (SELECT travel_group_id FROM group_members WHERE user_id = $2));
+            tg.id = $1;
     `
 
 	var details models.TravelGroupDetails
 	var memberCount sql.NullInt32
 
-	err := r.db.QueryRow(query, groupID, userID).Scan(
+	err := r.db.QueryRowContext(ctx, query, groupID, userID).Scan(
 		&details.ID,
 		&details.Name,
 		&details.Description,
 		&details.StartDate,
 		&details.EndDate,
+		&details.BaseCurrency,
 		&details.CreatorID,
 		&details.CreatorName,
 		&memberCount,
+		&details.Role,
 	)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("grupo não encontrado ou usuário não autorizado a visualizá-lo")
+			return nil, ErrGroupNotFound
 		}
 		return nil, fmt.Errorf("erro ao buscar detalhes do grupo: %w", err)
 	}
@@ -194,28 +236,40 @@ func (r *postgresTravelGroupRepository) GetGroupDetails(groupID int, userID int)
 	return &details, nil
 }
 
-func (r *postgresTravelGroupRepository) ListGroupMembers(groupID int) ([]models.GroupMemberDTO, error) {
+// ListGroupMembers lista os membros ativos do grupo. Com includeFormer, inclui
+// também os ex-membros (left_at preenchido), necessários para o histórico de
+// despesas e para os saldos.
+func (r *postgresTravelGroupRepository) ListGroupMembers(ctx context.Context, groupID int, includeFormer bool) ([]models.GroupMemberDTO, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return listGroupMembers(ctx, r.db, groupID, includeFormer)
+}
+
+func listGroupMembers(ctx context.Context, q queryer, groupID int, includeFormer bool) ([]models.GroupMemberDTO, error) {
 	query := `
         SELECT 
             u.id AS user_id,
             u.name,
             u.email,
-            CASE 
-                WHEN tg.creator_id = u.id THEN 'Organizador'
-                ELSE 'Participante'
-            END AS role
+            gm.role,
+            gm.left_at
         FROM 
             group_members gm
         JOIN 
             users u ON gm.user_id = u.id
-        JOIN 
-            travel_groups tg ON gm.travel_group_id = tg.id
         WHERE 
-            gm.travel_group_id = $1
-        ORDER BY role DESC, u.name ASC; -- Organizador sempre primeiro
+            gm.travel_group_id = $1 AND ($2 OR gm.left_at IS NULL)
+        -- Ativos primeiro; entre eles, organizador, co-organizadores, participantes e leitores
+        ORDER BY gm.left_at IS NOT NULL, CASE gm.role
+                WHEN 'organizer' THEN 0
+                WHEN 'co_organizer' THEN 1
+                WHEN 'participant' THEN 2
+                ELSE 3
+            END, u.name ASC;
     `
 
-	rows, err := r.db.Query(query, groupID)
+	rows, err := q.QueryContext(ctx, query, groupID, includeFormer)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar membros do grupo: %w", err)
 	}
@@ -229,6 +283,7 @@ func (r *postgresTravelGroupRepository) ListGroupMembers(groupID int) ([]models.
 			&member.Name,
 			&member.Email,
 			&member.Role,
+			&member.LeftAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao escanear membro: %w", err)
@@ -243,13 +298,17 @@ func (r *postgresTravelGroupRepository) ListGroupMembers(groupID int) ([]models.
 	return members, nil
 }
 
-func (r *postgresTravelGroupRepository) ListGroupDestinations(groupID int) ([]models.DestinationDTO, error) {
+func (r *postgresTravelGroupRepository) ListGroupDestinations(ctx context.Context, groupID int) ([]models.DestinationDTO, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
         SELECT 
             id,
             name,
             location,
-            description
+            description,
+            created_by
         FROM 
             destinations
         WHERE 
@@ -257,7 +316,7 @@ func (r *postgresTravelGroupRepository) ListGroupDestinations(groupID int) ([]mo
         ORDER BY name ASC;
     `
 
-	rows, err := r.db.Query(query, groupID)
+	rows, err := r.db.QueryContext(ctx, query, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar destinos do grupo: %w", err)
 	}
@@ -266,15 +325,21 @@ func (r *postgresTravelGroupRepository) ListGroupDestinations(groupID int) ([]mo
 	destinations := []models.DestinationDTO{}
 	for rows.Next() {
 		var d models.DestinationDTO
+		var createdBy sql.NullInt32
 		err := rows.Scan(
 			&d.ID,
 			&d.Name,
 			&d.Location,
 			&d.Description,
+			&createdBy,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao escanear destino: %w", err)
 		}
+		if createdBy.Valid {
+			id := int(createdBy.Int32)
+			d.CreatedBy = &id
+		}
 		destinations = append(destinations, d)
 	}
 
@@ -285,15 +350,24 @@ func (r *postgresTravelGroupRepository) ListGroupDestinations(groupID int) ([]mo
 	return destinations, nil
 }
 
-func (r *postgresTravelGroupRepository) ListGroupVotings(groupID int, userID int) ([]models.VotingDTO, error) {
+func (r *postgresTravelGroupRepository) ListGroupVotings(ctx context.Context, groupID int, userID int) ([]models.VotingDTO, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	// Usamos left join para trazer a votação do usuário (se existir)
 	query := `
         SELECT 
             v.id,
             v.question,
             v.options,
+            v.voting_type,
+            v.max_selections,
             COUNT(vt.id) AS total_votes,
             uv.selected_option AS user_vote_option,
+            uv.selections AS user_vote_selections,
+            v.created_by,
+            v.closes_at,
+            v.closed_at,
             v.created_at
         FROM 
             votings v
@@ -303,11 +377,12 @@ func (r *postgresTravelGroupRepository) ListGroupVotings(groupID int, userID int
             votes uv ON v.id = uv.voting_id AND uv.user_id = $2 -- Voto do Usuário Logado
         WHERE 
             v.travel_group_id = $1
-        GROUP BY v.id, v.question, v.options, v.created_at, uv.selected_option
+        GROUP BY v.id, v.question, v.options, v.voting_type, v.max_selections, v.created_by,
+                 v.closes_at, v.closed_at, v.created_at, uv.selected_option, uv.selections
         ORDER BY v.created_at DESC;
     `
 
-	rows, err := r.db.Query(query, groupID, userID)
+	rows, err := r.db.QueryContext(ctx, query, groupID, userID)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar votações: %w", err)
 	}
@@ -318,14 +393,22 @@ func (r *postgresTravelGroupRepository) ListGroupVotings(groupID int, userID int
 		var v models.VotingDTO
 		var optionsJSON string
 		var totalVotes sql.NullInt64
-		var userVote sql.NullString
+		var userVote, userSelections sql.NullString
+		var maxSelections, createdBy sql.NullInt32
+		var closesAt, closedAt sql.NullTime
 
 		err := rows.Scan(
 			&v.ID,
 			&v.Question,
 			&optionsJSON, // String JSON
+			&v.Type,
+			&maxSelections,
 			&totalVotes,
 			&userVote,
+			&userSelections,
+			&createdBy,
+			&closesAt,
+			&closedAt,
 			&v.CreatedAt,
 		)
 		if err != nil {
@@ -342,28 +425,74 @@ func (r *postgresTravelGroupRepository) ListGroupVotings(groupID int, userID int
 		v.TotalVotes = int(totalVotes.Int64)
 
 		// 3. Setar voto do usuário (se houver)
+		v.UserVotes = []string{}
 		if userVote.Valid {
 			v.UserVote = &userVote.String
+			v.UserVotes = []string{userVote.String}
+		}
+		if userSelections.Valid {
+			if err := json.Unmarshal([]byte(userSelections.String), &v.UserVotes); err != nil {
+				fmt.Printf("Aviso: Falha ao deserializar voto do usuário na votação %d: %v\n", v.ID, err)
+			}
+		}
+		if maxSelections.Valid {
+			m := int(maxSelections.Int32)
+			v.MaxSelections = &m
 		}
 
+		// 4. Dados de encerramento e status calculado
+		voting := models.Voting{}
+		if createdBy.Valid {
+			id := int(createdBy.Int32)
+			v.CreatedBy = &id
+		}
+		if closesAt.Valid {
+			v.ClosesAt = &closesAt.Time
+			voting.ClosesAt = v.ClosesAt
+		}
+		if closedAt.Valid {
+			v.ClosedAt = &closedAt.Time
+			voting.ClosedAt = v.ClosedAt
+		}
+		v.Status = voting.Status(time.Now())
+
 		votings = append(votings, v)
 	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração das votações: %w", err)
+	}
 
 	return votings, nil
 }
 
-func (r *postgresTravelGroupRepository) ListGroupExpenses(groupID int) ([]models.ExpenseDTO, error) {
+func (r *postgresTravelGroupRepository) ListGroupExpenses(ctx context.Context, groupID int) ([]models.ExpenseDTO, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return listGroupExpenses(ctx, r.db, groupID)
+}
+
+func listGroupExpenses(ctx context.Context, q queryer, groupID int) ([]models.ExpenseDTO, error) {
 	// A consulta usa a agregação STRING_AGG para obter a lista de IDs de participantes
 	query := `
         SELECT 
             e.id,
             e.description,
             e.amount,
+            e.currency,
             e.payer_id,
             u.name AS payer_name,
+            e.created_by,
+            e.split_mode,
+            e.category,
             e.created_at,
             COUNT(ep.user_id) AS participants_count,
-            COALESCE(STRING_AGG(ep.user_id::text, ',' ORDER BY ep.user_id), '') AS participants_ids
+            COALESCE(STRING_AGG(ep.user_id::text, ',' ORDER BY ep.user_id), '') AS participants_ids,
+            COALESCE(
+                JSON_AGG(JSON_BUILD_OBJECT('userId', ep.user_id, 'amount', ep.share_amount) ORDER BY ep.user_id)
+                    FILTER (WHERE ep.user_id IS NOT NULL),
+                '[]'
+            ) AS shares
         FROM 
             expenses e
         JOIN 
@@ -376,7 +505,7 @@ func (r *postgresTravelGroupRepository) ListGroupExpenses(groupID int) ([]models
         ORDER BY e.created_at DESC;
     `
 
-	rows, err := r.db.Query(query, groupID)
+	rows, err := q.QueryContext(ctx, query, groupID)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar despesas: %w", err)
 	}
@@ -387,23 +516,34 @@ func (r *postgresTravelGroupRepository) ListGroupExpenses(groupID int) ([]models
 		var e models.ExpenseDTO
 		var participantsIDsStr sql.NullString
 		var participantsCount sql.NullInt64
+		var createdBy sql.NullInt32
+		var sharesJSON []byte
 
 		err := rows.Scan(
 			&e.ID,
 			&e.Description,
-			&e.Amount, // Mapeado diretamente para float64 (no Struct)
+			&e.Amount, // models.Money implementa sql.Scanner (decimal exato)
+			&e.Currency,
 			&e.PayerID,
 			&e.PayerName,
+			&createdBy,
+			&e.SplitMode,
+			&e.Category,
 			&e.CreatedAt,
 			&participantsCount,
 			&participantsIDsStr, // IDs separados por vírgula
+			&sharesJSON,         // Array JSON com o valor de cada participante
 		)
 		if err != nil {
 			return nil, fmt.Errorf("erro ao escanear despesa: %w", err)
 		}
 
-		// 1. Setar contagem de participantes
+		// 1. Setar contagem de participantes e autor
 		e.ParticipantsCount = int(participantsCount.Int64)
+		if createdBy.Valid {
+			id := int(createdBy.Int32)
+			e.CreatedBy = &id
+		}
 
 		// 2. Processar a lista de IDs de participantes
 		e.ParticipantsIDs = []int{}
@@ -416,25 +556,35 @@ func (r *postgresTravelGroupRepository) ListGroupExpenses(groupID int) ([]models
 			}
 		}
 
+		// 3. Deserializar o valor de cada participante
+		e.Shares = []models.ExpenseShare{}
+		if err := json.Unmarshal(sharesJSON, &e.Shares); err != nil {
+			return nil, fmt.Errorf("erro ao deserializar partes da despesa %d: %w", e.ID, err)
+		}
+
 		expenses = append(expenses, e)
 	}
 
 	return expenses, nil
 }
-func (r *postgresTravelGroupRepository) CreateDestination(destination *models.Destination) error {
+func (r *postgresTravelGroupRepository) CreateDestination(ctx context.Context, destination *models.Destination) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
 	query := `
         INSERT INTO destinations 
-        (travel_group_id, name, location, description, created_at) 
+        (travel_group_id, name, location, description, created_by, created_at) 
         VALUES 
-        ($1, $2, $3, $4, NOW())
+        ($1, $2, $3, $4, $5, NOW())
         RETURNING id;
     `
 	// O ID retornado é setado de volta no struct 'destination'
-	err := r.db.QueryRow(query,
+	err := r.db.QueryRowContext(ctx, query,
 		destination.TravelGroupID,
 		destination.Name,
 		destination.Location,
 		destination.Description,
+		destination.CreatedBy,
 	).Scan(&destination.ID)
 
 	if err != nil {
@@ -443,60 +593,82 @@ func (r *postgresTravelGroupRepository) CreateDestination(destination *models.De
 	return nil
 }
 
-func (r *postgresTravelGroupRepository) CreateVoting(groupID int, question string, optionsJSON string) (int, error) {
-	var newID int
+// CreateVoting insere a votação e preenche o ID gerado no struct.
+func (r *postgresTravelGroupRepository) CreateVoting(ctx context.Context, voting *models.Voting) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	optionsJSON, err := json.Marshal(voting.Options)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar opções da votação: %w", err)
+	}
+
 	query := `
         INSERT INTO votings 
-        (travel_group_id, question, options, created_at) 
+        (travel_group_id, created_by, question, options, voting_type, max_selections, closes_at, created_at) 
         VALUES 
-        ($1, $2, $3, NOW())
-        RETURNING id;
+        ($1, $2, $3, $4, $5, $6, $7, NOW())
+        RETURNING id, created_at;
     `
-	err := r.db.QueryRow(query,
-		groupID,
-		question,
-		optionsJSON,
-	).Scan(&newID)
+	err = r.db.QueryRowContext(ctx, query,
+		voting.TravelGroupID,
+		voting.CreatedBy,
+		voting.Question,
+		string(optionsJSON),
+		voting.Type,
+		voting.MaxSelections,
+		voting.ClosesAt,
+	).Scan(&voting.ID, &voting.CreatedAt)
 
 	if err != nil {
-		return 0, fmt.Errorf("erro ao inserir votação: %w", err)
+		return fmt.Errorf("erro ao inserir votação: %w", err)
 	}
-	return newID, nil
+	return nil
 }
 
-func (r *postgresTravelGroupRepository) CreateExpense(expense *models.Expense) error {
+func (r *postgresTravelGroupRepository) CreateExpense(ctx context.Context, expense *models.Expense) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
 
-	tx, err := r.db.Begin()
+	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return fmt.Errorf("falha ao iniciar transação para despesa: %w", err)
 	}
 	defer tx.Rollback()
 
+	if err := lockActiveMembers(ctx, tx, expense.TravelGroupID, expenseMemberIDs(expense)); err != nil {
+		return err
+	}
+
 	expenseQuery := `
         INSERT INTO expenses 
-        (travel_group_id, description, amount, payer_id, created_at) 
+        (travel_group_id, description, amount, currency, payer_id, created_by, split_mode, category, created_at) 
         VALUES 
-        ($1, $2, $3, $4, NOW())
+        ($1, $2, $3, $4, $5, $6, $7, $8, NOW())
         RETURNING id;
     `
-	err = tx.QueryRow(expenseQuery,
+	err = tx.QueryRowContext(ctx, expenseQuery,
 		expense.TravelGroupID,
 		expense.Description,
 		expense.Amount,
+		expense.Currency,
 		expense.PayerID,
+		expense.CreatedBy,
+		expense.SplitMode,
+		expense.Category,
 	).Scan(&expense.ID)
 
 	if err != nil {
 		return fmt.Errorf("erro ao inserir despesa: %w", err)
 	}
 
-	if len(expense.ParticipantIDs) > 0 {
-		participantQuery := `INSERT INTO expense_participants (expense_id, user_id) VALUES ($1, $2)`
+	if len(expense.Shares) > 0 {
+		participantQuery := `INSERT INTO expense_participants (expense_id, user_id, share_amount) VALUES ($1, $2, $3)`
 
-		for _, userID := range expense.ParticipantIDs {
-			_, err := tx.Exec(participantQuery, expense.ID, userID)
+		for _, share := range expense.Shares {
+			_, err := tx.ExecContext(ctx, participantQuery, expense.ID, share.UserID, share.Amount)
 			if err != nil {
-				return fmt.Errorf("erro ao inserir participante %d para despesa %d: %w", userID, expense.ID, err)
+				return fmt.Errorf("erro ao inserir participante %d para despesa %d: %w", share.UserID, expense.ID, err)
 			}
 		}
 	}
@@ -507,3 +679,564 @@ func (r *postgresTravelGroupRepository) CreateExpense(expense *models.Expense) e
 
 	return nil
 }
+
+// AreGroupMembers verifica se todos os usuários informados são membros do grupo.
+func (r *postgresTravelGroupRepository) AreGroupMembers(ctx context.Context, groupID int, userIDs []int) (bool, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	if len(userIDs) == 0 {
+		return true, nil
+	}
+
+	query := `
+        SELECT COUNT(DISTINCT user_id)
+        FROM group_members
+        WHERE travel_group_id = $1 AND user_id = ANY($2) AND left_at IS NULL;
+    `
+	var count int
+	err := r.db.QueryRowContext(ctx, query, groupID, pq.Array(userIDs)).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("erro ao verificar membros do grupo: %w", err)
+	}
+
+	distinct := map[int]bool{}
+	for _, id := range userIDs {
+		distinct[id] = true
+	}
+	return count == len(distinct), nil
+}
+
+// GetGroupBaseCurrency retorna a moeda base usada para totalizar as despesas do grupo.
+func (r *postgresTravelGroupRepository) GetGroupBaseCurrency(ctx context.Context, groupID int) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	return groupBaseCurrency(ctx, r.db, groupID)
+}
+
+func groupBaseCurrency(ctx context.Context, q queryer, groupID int) (string, error) {
+	var currency string
+	err := q.QueryRowContext(ctx, `SELECT base_currency FROM travel_groups WHERE id = $1`, groupID).Scan(&currency)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrGroupNotFound
+		}
+		return "", fmt.Errorf("erro ao buscar moeda base do grupo: %w", err)
+	}
+	return currency, nil
+}
+
+// queryer é o que *sql.DB e *sql.Tx têm em comum: as consultas de leitura que
+// também rodam dentro de uma transação recebem um queryer.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// checkRowsAffected devolve notFound quando o UPDATE/DELETE não encontrou a linha.
+func checkRowsAffected(result sql.Result, notFound error) error {
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar linhas afetadas: %w", err)
+	}
+	if affected == 0 {
+		return notFound
+	}
+	return nil
+}
+
+// UpdateTravelGroup grava nome, descrição, datas e moeda base do grupo. As datas
+// só mudam se todos os itens do roteiro continuarem dentro da viagem.
+func (r *postgresTravelGroupRepository) UpdateTravelGroup(ctx context.Context, group *models.TravelGroup) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE travel_groups
+        SET name = $2, description = $3, start_date = $4, end_date = $5, base_currency = $6
+        WHERE id = $1;
+    `,
+		group.ID,
+		group.Name,
+		group.Description,
+		group.StartDate,
+		group.EndDate,
+		group.BaseCurrency,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao alterar grupo de viagem: %w", err)
+	}
+	if err := checkRowsAffected(result, ErrGroupNotFound); err != nil {
+		return err
+	}
+
+	var outside int
+	err = tx.QueryRowContext(ctx, `
+        SELECT COUNT(*)
+        FROM itinerary_items
+        WHERE travel_group_id = $1
+          AND (starts_at < $2::date OR ends_at > $3::date + 1);
+    `, group.ID, group.StartDate, group.EndDate).Scan(&outside)
+	if err != nil {
+		return fmt.Errorf("erro ao validar roteiro do grupo: %w", err)
+	}
+	if outside > 0 {
+		return ErrItineraryOutsideDates
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+	return nil
+}
+
+// DeleteTravelGroup apaga o grupo; membros, destinos, votações, despesas,
+// convites e roteiro são removidos pelas regras ON DELETE CASCADE.
+func (r *postgresTravelGroupRepository) DeleteTravelGroup(ctx context.Context, groupID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM travel_groups WHERE id = $1;`, groupID)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar grupo de viagem: %w", err)
+	}
+	return checkRowsAffected(result, ErrGroupNotFound)
+}
+
+func (r *postgresTravelGroupRepository) GetDestination(ctx context.Context, groupID int, destinationID int) (*models.Destination, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT id, travel_group_id, name, COALESCE(location, ''), COALESCE(description, ''), created_by
+        FROM destinations
+        WHERE travel_group_id = $1 AND id = $2;
+    `
+	var d models.Destination
+	var createdBy sql.NullInt32
+	err := r.db.QueryRowContext(ctx, query, groupID, destinationID).Scan(
+		&d.ID,
+		&d.TravelGroupID,
+		&d.Name,
+		&d.Location,
+		&d.Description,
+		&createdBy,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrDestinationNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar destino: %w", err)
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int32)
+		d.CreatedBy = &id
+	}
+	return &d, nil
+}
+
+func (r *postgresTravelGroupRepository) UpdateDestination(ctx context.Context, destination *models.Destination) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `
+        UPDATE destinations
+        SET name = $3, location = $4, description = $5
+        WHERE travel_group_id = $1 AND id = $2;
+    `,
+		destination.TravelGroupID,
+		destination.ID,
+		destination.Name,
+		destination.Location,
+		destination.Description,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao alterar destino: %w", err)
+	}
+	return checkRowsAffected(result, ErrDestinationNotFound)
+}
+
+// DeleteDestination apaga o destino; itens do roteiro ligados a ele ficam sem destino (ON DELETE SET NULL).
+func (r *postgresTravelGroupRepository) DeleteDestination(ctx context.Context, groupID int, destinationID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM destinations WHERE travel_group_id = $1 AND id = $2;`, groupID, destinationID)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar destino: %w", err)
+	}
+	return checkRowsAffected(result, ErrDestinationNotFound)
+}
+
+// GetExpense busca a despesa com a parte de cada participante.
+func (r *postgresTravelGroupRepository) GetExpense(ctx context.Context, groupID int, expenseID int) (*models.Expense, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        SELECT id, travel_group_id, COALESCE(description, ''), amount, currency, payer_id, created_by, split_mode, category
+        FROM expenses
+        WHERE travel_group_id = $1 AND id = $2;
+    `
+	var e models.Expense
+	var createdBy sql.NullInt32
+	err := r.db.QueryRowContext(ctx, query, groupID, expenseID).Scan(
+		&e.ID,
+		&e.TravelGroupID,
+		&e.Description,
+		&e.Amount,
+		&e.Currency,
+		&e.PayerID,
+		&createdBy,
+		&e.SplitMode,
+		&e.Category,
+	)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, ErrExpenseNotFound
+		}
+		return nil, fmt.Errorf("erro ao buscar despesa: %w", err)
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int32)
+		e.CreatedBy = &id
+	}
+
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT user_id, COALESCE(share_amount, 0)
+        FROM expense_participants
+        WHERE expense_id = $1
+        ORDER BY user_id;
+    `, expenseID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar participantes da despesa: %w", err)
+	}
+	defer rows.Close()
+
+	e.ParticipantIDs = []int{}
+	e.Shares = []models.ExpenseShare{}
+	for rows.Next() {
+		var share models.ExpenseShare
+		if err := rows.Scan(&share.UserID, &share.Amount); err != nil {
+			return nil, fmt.Errorf("erro ao escanear participante da despesa: %w", err)
+		}
+		e.ParticipantIDs = append(e.ParticipantIDs, share.UserID)
+		e.Shares = append(e.Shares, share)
+	}
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração dos participantes: %w", err)
+	}
+
+	return &e, nil
+}
+
+// UpdateExpense grava os novos dados da despesa e substitui as partes dos
+// participantes na mesma transação. Pagador, autor e data não mudam.
+func (r *postgresTravelGroupRepository) UpdateExpense(ctx context.Context, expense *models.Expense) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação para despesa: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Os membros da versão atual e da nova têm o saldo alterado; os da atual
+	// podem já ter saído do grupo, os da nova precisam ser membros ativos.
+	if err := lockExpenseMembers(ctx, tx, expense.TravelGroupID, expense.ID); err != nil {
+		return err
+	}
+	if err := lockActiveMembers(ctx, tx, expense.TravelGroupID, shareMemberIDs(expense)); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE expenses
+        SET description = $3, amount = $4, currency = $5, split_mode = $6, category = $7
+        WHERE travel_group_id = $1 AND id = $2;
+    `,
+		expense.TravelGroupID,
+		expense.ID,
+		expense.Description,
+		expense.Amount,
+		expense.Currency,
+		expense.SplitMode,
+		expense.Category,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao alterar despesa: %w", err)
+	}
+	if err := checkRowsAffected(result, ErrExpenseNotFound); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM expense_participants WHERE expense_id = $1;`, expense.ID); err != nil {
+		return fmt.Errorf("erro ao remover participantes da despesa %d: %w", expense.ID, err)
+	}
+
+	participantQuery := `INSERT INTO expense_participants (expense_id, user_id, share_amount) VALUES ($1, $2, $3)`
+	for _, share := range expense.Shares {
+		_, err := tx.ExecContext(ctx, participantQuery, expense.ID, share.UserID, share.Amount)
+		if err != nil {
+			return fmt.Errorf("erro ao inserir participante %d para despesa %d: %w", share.UserID, expense.ID, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação da despesa: %w", err)
+	}
+	return nil
+}
+
+// DeleteExpense apaga a despesa; as partes dos participantes saem por ON DELETE CASCADE.
+func (r *postgresTravelGroupRepository) DeleteExpense(ctx context.Context, groupID int, expenseID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação para despesa: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := lockExpenseMembers(ctx, tx, groupID, expenseID); err != nil {
+		return err
+	}
+
+	result, err := tx.ExecContext(ctx, `DELETE FROM expenses WHERE travel_group_id = $1 AND id = $2;`, groupID, expenseID)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar despesa: %w", err)
+	}
+	if err := checkRowsAffected(result, ErrExpenseNotFound); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação da despesa: %w", err)
+	}
+	return nil
+}
+
+// lockExpenseMembers trava (FOR SHARE) as linhas do pagador e dos
+// participantes atuais da despesa, ativos ou não; ver lockActiveMembers.
+func lockExpenseMembers(ctx context.Context, tx *sql.Tx, groupID int, expenseID int) error {
+	_, err := tx.ExecContext(ctx, `
+        SELECT 1 FROM group_members
+        WHERE travel_group_id = $1 AND user_id IN (
+            SELECT payer_id FROM expenses WHERE travel_group_id = $1 AND id = $2
+            UNION
+            SELECT user_id FROM expense_participants WHERE expense_id = $2
+        )
+        ORDER BY user_id
+        FOR SHARE;
+    `, groupID, expenseID)
+	if err != nil {
+		return fmt.Errorf("erro ao travar membros da despesa: %w", err)
+	}
+	return nil
+}
+
+// shareMemberIDs devolve os participantes da despesa.
+func shareMemberIDs(expense *models.Expense) []int {
+	ids := make([]int, 0, len(expense.Shares))
+	for _, share := range expense.Shares {
+		ids = append(ids, share.UserID)
+	}
+	return ids
+}
+
+// expenseMemberIDs devolve o pagador e os participantes da despesa.
+func expenseMemberIDs(expense *models.Expense) []int {
+	return append(shareMemberIDs(expense), expense.PayerID)
+}
+
+// GetMemberRole devolve o papel do usuário no grupo, ou ErrMemberNotFound se
+// ele não for membro.
+func (r *postgresTravelGroupRepository) GetMemberRole(ctx context.Context, groupID int, userID int) (string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	var role string
+	query := `SELECT role FROM group_members WHERE travel_group_id = $1 AND user_id = $2 AND left_at IS NULL;`
+	err := r.db.QueryRowContext(ctx, query, groupID, userID).Scan(&role)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return "", ErrMemberNotFound
+		}
+		return "", fmt.Errorf("erro ao buscar papel do membro: %w", err)
+	}
+	return role, nil
+}
+
+// UpdateMemberRole altera o papel de um membro. O organizador só muda de papel
+// por TransferOwnership, por isso a linha dele nunca é alterada aqui.
+func (r *postgresTravelGroupRepository) UpdateMemberRole(ctx context.Context, groupID int, userID int, role string) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+        UPDATE group_members SET role = $3
+        WHERE travel_group_id = $1 AND user_id = $2 AND role <> 'organizer' AND left_at IS NULL;
+    `
+	result, err := r.db.ExecContext(ctx, query, groupID, userID, role)
+	if err != nil {
+		return fmt.Errorf("erro ao alterar papel do membro: %w", err)
+	}
+	return checkRowsAffected(result, ErrMemberNotFound)
+}
+
+// TransferOwnership passa o grupo para outro membro: ele vira o organizador (e
+// travel_groups.creator_id), e o organizador anterior fica como co-organizador.
+func (r *postgresTravelGroupRepository) TransferOwnership(ctx context.Context, groupID int, newOwnerID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Trava o grupo para que duas transferências simultâneas não deixem dois organizadores.
+	var ownerID int
+	err = tx.QueryRowContext(ctx, `SELECT creator_id FROM travel_groups WHERE id = $1 FOR UPDATE;`, groupID).Scan(&ownerID)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return ErrGroupNotFound
+		}
+		return fmt.Errorf("erro ao buscar organizador do grupo: %w", err)
+	}
+
+	// O índice único de organizador exige rebaixar o atual antes de promover o novo.
+	if _, err := tx.ExecContext(ctx, `
+        UPDATE group_members SET role = 'co_organizer'
+        WHERE travel_group_id = $1 AND user_id = $2;
+    `, groupID, ownerID); err != nil {
+		return fmt.Errorf("erro ao rebaixar organizador: %w", err)
+	}
+
+	result, err := tx.ExecContext(ctx, `
+        UPDATE group_members SET role = 'organizer'
+        WHERE travel_group_id = $1 AND user_id = $2 AND left_at IS NULL;
+    `, groupID, newOwnerID)
+	if err != nil {
+		return fmt.Errorf("erro ao promover novo organizador: %w", err)
+	}
+	if err := checkRowsAffected(result, ErrMemberNotFound); err != nil {
+		return err
+	}
+
+	if _, err := tx.ExecContext(ctx, `UPDATE travel_groups SET creator_id = $2 WHERE id = $1;`, groupID, newOwnerID); err != nil {
+		return fmt.Errorf("erro ao transferir grupo: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+	return nil
+}
+
+// MemberRemoval é o que check recebe em RemoveMember: os papéis de quem sai e
+// de quem remove (iguais quando o membro sai por conta própria) e os dados dos
+// saldos, tudo lido na transação da saída.
+type MemberRemoval struct {
+	Role      string
+	ActorRole string
+	Ledger    GroupLedger
+}
+
+// RemoveMember registra a saída de um membro, pedida por actorID (o próprio
+// membro, quando ele sai do grupo). A linha continua em group_members (com
+// left_at) para que despesas e votos antigos ainda apontem para um ex-membro.
+//
+// Tudo roda numa transação que trava o grupo (FOR SHARE, contra uma
+// transferência em andamento) e as linhas de quem sai e de quem remove (FOR
+// UPDATE, contra uma mudança de papel): check recebe os papéis e os saldos
+// lidos nela e pode vetar a saída devolvendo um erro. Despesas e pagamentos
+// travam as linhas dos membros envolvidos FOR SHARE, então nenhum saldo do
+// membro muda entre a conferência e a saída. Devolve ErrGroupNotFound se
+// actorID não for membro ativo e ErrMemberNotFound se userID não for.
+func (r *postgresTravelGroupRepository) RemoveMember(ctx context.Context, groupID int, userID int, actorID int, check func(MemberRemoval) error) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `SELECT 1 FROM travel_groups WHERE id = $1 FOR SHARE;`, groupID); err != nil {
+		return fmt.Errorf("erro ao travar grupo: %w", err)
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT user_id, role FROM group_members
+        WHERE travel_group_id = $1 AND user_id IN ($2, $3) AND left_at IS NULL
+        ORDER BY user_id
+        FOR UPDATE;
+    `, groupID, userID, actorID)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar membro: %w", err)
+	}
+	defer rows.Close()
+
+	roles := map[int]string{}
+	for rows.Next() {
+		var id int
+		var role string
+		if err := rows.Scan(&id, &role); err != nil {
+			return fmt.Errorf("erro ao escanear membro: %w", err)
+		}
+		roles[id] = role
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro na iteração dos membros: %w", err)
+	}
+
+	removal := MemberRemoval{Role: roles[userID], ActorRole: roles[actorID]}
+	if removal.ActorRole == "" {
+		return ErrGroupNotFound
+	}
+	if removal.Role == "" {
+		return ErrMemberNotFound
+	}
+
+	ledger := &removal.Ledger
+	if ledger.BaseCurrency, err = groupBaseCurrency(ctx, tx, groupID); err != nil {
+		return err
+	}
+	if ledger.Members, err = listGroupMembers(ctx, tx, groupID, true); err != nil {
+		return err
+	}
+	if ledger.Expenses, err = listGroupExpenses(ctx, tx, groupID); err != nil {
+		return err
+	}
+	if ledger.Payments, err = listPayments(ctx, tx, groupID); err != nil {
+		return err
+	}
+	if err := check(removal); err != nil {
+		return err
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        UPDATE group_members SET left_at = NOW()
+        WHERE travel_group_id = $1 AND user_id = $2;
+    `, groupID, userID)
+	if err != nil {
+		return fmt.Errorf("erro ao remover membro: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação: %w", err)
+	}
+	return nil
+}
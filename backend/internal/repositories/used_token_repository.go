@@ -0,0 +1,49 @@
+package repositories
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrTokenAlreadyUsed indica que um token de uso único (verificação de e-mail,
+// redefinição de senha) já foi consumido.
+var ErrTokenAlreadyUsed = errors.New("token já utilizado")
+
+// UsedTokenRepository registra o jti dos tokens assinados de uso único. O token
+// em si é validado pela assinatura; o banco só guarda quais já foram usados,
+// até a data em que expirariam.
+type UsedTokenRepository interface {
+	ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) error
+}
+
+type postgresUsedTokenRepository struct {
+	db *sql.DB
+}
+
+func NewUsedTokenRepository(db *sql.DB) UsedTokenRepository {
+	return &postgresUsedTokenRepository{db: db}
+}
+
+func (r *postgresUsedTokenRepository) ConsumeToken(ctx context.Context, jti string, expiresAt time.Time) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	// Registros de tokens expirados não impedem mais nada: aproveita para limpá-los.
+	if _, err := r.db.ExecContext(ctx, `DELETE FROM used_tokens WHERE expires_at < NOW();`); err != nil {
+		return fmt.Errorf("erro ao limpar tokens usados: %w", err)
+	}
+
+	query := `
+        INSERT INTO used_tokens (jti, expires_at, used_at)
+        VALUES ($1, $2, NOW())
+        ON CONFLICT (jti) DO NOTHING;
+    `
+	result, err := r.db.ExecContext(ctx, query, jti, expiresAt)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar token usado: %w", err)
+	}
+	return checkRowsAffected(result, ErrTokenAlreadyUsed)
+}
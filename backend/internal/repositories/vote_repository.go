@@ -1,16 +1,35 @@
 package repositories
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"project_lab/internal/models"
+
+	"github.com/lib/pq"
+)
+
+// Erros de domínio das votações.
+var (
+	ErrVotingNotFound = errors.New("votação não encontrada")
+	ErrAlreadyVoted   = errors.New("usuário já votou nesta votação")
+	ErrVoteNotFound   = errors.New("voto não encontrado")
+	// ErrVotingLocked indica uma mudança de opções, tipo ou limite de escolhas
+	// numa votação que já recebeu votos.
+	ErrVotingLocked = errors.New("votação já recebeu votos")
 )
 
 type VoteRepository interface {
-	CastVote(vote *models.Vote) error
-	CheckUserVote(votingID int, userID int) (bool, error)
-	GetVotingOptions(votingID int) ([]string, error)
+	CastVote(ctx context.Context, vote *models.Vote) error
+	UpdateVote(ctx context.Context, vote *models.Vote) error
+	DeleteVote(ctx context.Context, votingID int, userID int) error
+	GetVoting(ctx context.Context, votingID int) (*models.Voting, error)
+	CloseVoting(ctx context.Context, votingID int) error
+	UpdateVoting(ctx context.Context, voting *models.Voting, structural bool) error
+	DeleteVoting(ctx context.Context, votingID int) error
+	ListBallots(ctx context.Context, votingID int) ([][]string, error)
 }
 
 type postgresVoteRepository struct {
@@ -21,49 +40,242 @@ func NewVoteRepository(db *sql.DB) VoteRepository {
 	return &postgresVoteRepository{db: db}
 }
 
-// CheckUserVote verifica se o usuário já votou nesta votação.
-func (r *postgresVoteRepository) CheckUserVote(votingID int, userID int) (bool, error) {
-	query := `SELECT COUNT(*) FROM votes WHERE voting_id = $1 AND user_id = $2;`
-	var count int
-	err := r.db.QueryRow(query, votingID, userID).Scan(&count)
+// CastVote insere o voto do usuário. O índice único (voting_id, user_id)
+// transforma um segundo voto em ErrAlreadyVoted, sem janela de corrida.
+func (r *postgresVoteRepository) CastVote(ctx context.Context, vote *models.Vote) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	selectionsJSON, err := json.Marshal(vote.SelectedOptions)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar opções do voto: %w", err)
+	}
+
+	query := `
+		INSERT INTO votes 
+		(voting_id, user_id, selected_option, selections, created_at) 
+		VALUES 
+		($1, $2, $3, $4, NOW());
+	`
+	_, err = r.db.ExecContext(ctx, query, vote.VotingID, vote.UserID, vote.SelectedOption, string(selectionsJSON))
+	if err != nil {
+		var pqErr *pq.Error
+		if errors.As(err, &pqErr) && pqErr.Code == "23505" {
+			return ErrAlreadyVoted
+		}
+		return fmt.Errorf("erro ao registrar voto: %w", err)
+	}
+	return nil
+}
+
+// UpdateVote troca a opção escolhida em um voto já existente.
+func (r *postgresVoteRepository) UpdateVote(ctx context.Context, vote *models.Vote) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	selectionsJSON, err := json.Marshal(vote.SelectedOptions)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar opções do voto: %w", err)
+	}
+
+	query := `
+		UPDATE votes
+		SET selected_option = $3, selections = $4, created_at = NOW()
+		WHERE voting_id = $1 AND user_id = $2;
+	`
+	result, err := r.db.ExecContext(ctx, query, vote.VotingID, vote.UserID, vote.SelectedOption, string(selectionsJSON))
+	if err != nil {
+		return fmt.Errorf("erro ao alterar voto: %w", err)
+	}
+	return checkRowsAffected(result, ErrVoteNotFound)
+}
+
+// DeleteVote remove o voto do usuário na votação.
+func (r *postgresVoteRepository) DeleteVote(ctx context.Context, votingID int, userID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `DELETE FROM votes WHERE voting_id = $1 AND user_id = $2;`
+	result, err := r.db.ExecContext(ctx, query, votingID, userID)
 	if err != nil {
-		return false, fmt.Errorf("erro ao checar voto existente: %w", err)
+		return fmt.Errorf("erro ao retirar voto: %w", err)
 	}
-	return count > 0, nil
+	return checkRowsAffected(result, ErrVoteNotFound)
 }
 
-// GetVotingOptions busca as opções válidas para uma votação (requer o parse JSON)
-func (r *postgresVoteRepository) GetVotingOptions(votingID int) ([]string, error) {
-	query := `SELECT options FROM votings WHERE id = $1;`
+// GetVoting busca a votação com o grupo, o autor e os dados de encerramento.
+func (r *postgresVoteRepository) GetVoting(ctx context.Context, votingID int) (*models.Voting, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT id, travel_group_id, question, options, voting_type, max_selections, created_by, closes_at, closed_at, created_at
+		FROM votings
+		WHERE id = $1;
+	`
+	var v models.Voting
 	var optionsJSON string
-	err := r.db.QueryRow(query, votingID).Scan(&optionsJSON)
+	var maxSelections, createdBy sql.NullInt32
+	var closesAt, closedAt sql.NullTime
 
+	err := r.db.QueryRowContext(ctx, query, votingID).Scan(
+		&v.ID,
+		&v.TravelGroupID,
+		&v.Question,
+		&optionsJSON,
+		&v.Type,
+		&maxSelections,
+		&createdBy,
+		&closesAt,
+		&closedAt,
+		&v.CreatedAt,
+	)
 	if err != nil {
 		if err == sql.ErrNoRows {
-			return nil, fmt.Errorf("votação não encontrada")
+			return nil, ErrVotingNotFound
 		}
-		return nil, fmt.Errorf("erro ao buscar opções da votação: %w", err)
+		return nil, fmt.Errorf("erro ao buscar votação: %w", err)
 	}
 
-	var options []string
-	if err := json.Unmarshal([]byte(optionsJSON), &options); err != nil {
+	if err := json.Unmarshal([]byte(optionsJSON), &v.Options); err != nil {
 		return nil, fmt.Errorf("erro ao deserializar opções JSON: %w", err)
 	}
+	if maxSelections.Valid {
+		m := int(maxSelections.Int32)
+		v.MaxSelections = &m
+	}
+	if createdBy.Valid {
+		id := int(createdBy.Int32)
+		v.CreatedBy = &id
+	}
+	if closesAt.Valid {
+		v.ClosesAt = &closesAt.Time
+	}
+	if closedAt.Valid {
+		v.ClosedAt = &closedAt.Time
+	}
+
+	return &v, nil
+}
+
+// CloseVoting encerra a votação manualmente. Não altera votações já encerradas.
+func (r *postgresVoteRepository) CloseVoting(ctx context.Context, votingID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `UPDATE votings SET closed_at = NOW() WHERE id = $1 AND closed_at IS NULL;`
+	if _, err := r.db.ExecContext(ctx, query, votingID); err != nil {
+		return fmt.Errorf("erro ao encerrar votação: %w", err)
+	}
+	return nil
+}
+
+// ListBallots retorna a cédula (opções escolhidas, em ordem) de cada eleitor.
+func (r *postgresVoteRepository) ListBallots(ctx context.Context, votingID int) ([][]string, error) {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	query := `
+		SELECT COALESCE(selections, json_build_array(selected_option)::text)
+		FROM votes
+		WHERE voting_id = $1
+		ORDER BY id;
+	`
+	rows, err := r.db.QueryContext(ctx, query, votingID)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar votos: %w", err)
+	}
+	defer rows.Close()
+
+	ballots := [][]string{}
+	for rows.Next() {
+		var selectionsJSON string
+		if err := rows.Scan(&selectionsJSON); err != nil {
+			return nil, fmt.Errorf("erro ao escanear voto: %w", err)
+		}
+		var ballot []string
+		if err := json.Unmarshal([]byte(selectionsJSON), &ballot); err != nil {
+			return nil, fmt.Errorf("erro ao deserializar voto: %w", err)
+		}
+		ballots = append(ballots, ballot)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro na iteração dos votos: %w", err)
+	}
 
-	return options, nil
+	return ballots, nil
 }
 
-// CastVote insere ou atualiza o voto do usuário (dependendo da sua regra de negócio, aqui faremos INSERIR)
-func (r *postgresVoteRepository) CastVote(vote *models.Vote) error {
+// UpdateVoting grava pergunta, opções, tipo e prazo da votação. Com structural
+// (opções, tipo ou limite de escolhas mudaram), devolve ErrVotingLocked se a
+// votação já tiver votos. A linha da votação fica travada (FOR UPDATE) durante
+// a conferência: um voto novo precisa dela (FOR KEY SHARE, pela chave
+// estrangeira de votes), então não entra entre a contagem e a alteração.
+func (r *postgresVoteRepository) UpdateVoting(ctx context.Context, voting *models.Voting, structural bool) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	optionsJSON, err := json.Marshal(voting.Options)
+	if err != nil {
+		return fmt.Errorf("erro ao serializar opções da votação: %w", err)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("falha ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	var exists int
+	if err := tx.QueryRowContext(ctx, `SELECT 1 FROM votings WHERE id = $1 FOR UPDATE;`, voting.ID).Scan(&exists); err != nil {
+		if err == sql.ErrNoRows {
+			return ErrVotingNotFound
+		}
+		return fmt.Errorf("erro ao travar votação: %w", err)
+	}
+
+	if structural {
+		var voted bool
+		if err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM votes WHERE voting_id = $1);`, voting.ID).Scan(&voted); err != nil {
+			return fmt.Errorf("erro ao contar votos: %w", err)
+		}
+		if voted {
+			return ErrVotingLocked
+		}
+	}
+
 	query := `
-		INSERT INTO votes 
-		(voting_id, user_id, selected_option, created_at) 
-		VALUES 
-		($1, $2, $3, NOW());
+		UPDATE votings
+		SET question = $2, options = $3, voting_type = $4, max_selections = $5, closes_at = $6
+		WHERE id = $1;
 	`
-	_, err := r.db.Exec(query, vote.VotingID, vote.UserID, vote.SelectedOption)
+	_, err = tx.ExecContext(ctx, query,
+		voting.ID,
+		voting.Question,
+		string(optionsJSON),
+		voting.Type,
+		voting.MaxSelections,
+		voting.ClosesAt,
+	)
 	if err != nil {
-		return fmt.Errorf("erro ao registrar voto: %w", err)
+		return fmt.Errorf("erro ao alterar votação: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("falha ao commitar transação: %w", err)
 	}
 	return nil
 }
+
+// DeleteVoting apaga a votação; os votos saem por ON DELETE CASCADE.
+func (r *postgresVoteRepository) DeleteVoting(ctx context.Context, votingID int) error {
+	ctx, cancel := withTimeout(ctx)
+	defer cancel()
+
+	result, err := r.db.ExecContext(ctx, `DELETE FROM votings WHERE id = $1;`, votingID)
+	if err != nil {
+		return fmt.Errorf("erro ao apagar votação: %w", err)
+	}
+	return checkRowsAffected(result, ErrVotingNotFound)
+}
@@ -0,0 +1,191 @@
+// Package router registra as rotas da API em um http.ServeMux a partir de uma
+// tabela única (veja Route), que também serve de fonte para a documentação.
+// Rotas inexistentes respondem 404 e métodos não atendidos respondem 405 com o
+// cabeçalho Allow, ambos no formato de problem.
+package router
+
+import (
+	"net/http"
+	"project_lab/internal/problem"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Route descreve um endpoint da API.
+type Route struct {
+	Method string
+	// Pattern segue a sintaxe do http.ServeMux, com parâmetros como {groupId}.
+	Pattern string
+	Handler http.Handler
+
+	// Auth exige o token de acesso (AuthMiddleware).
+	Auth bool
+	// RateLimit é o nome da política de limite de requisições, se houver.
+	RateLimit string
+
+	// Tag e Summary agrupam e descrevem a rota na documentação.
+	Tag     string
+	Summary string
+
+	// Request e Response são valores de exemplo dos tipos do corpo JSON da
+	// requisição e da resposta (ex.: models.UserLogin{}); nil quando não há corpo.
+	Request  any
+	Response any
+	// RequestContentType é o tipo do corpo da requisição quando não for JSON
+	// (ex.: multipart/form-data).
+	RequestContentType string
+	// Status é o status de sucesso; zero significa 200.
+	Status int
+	// ContentType é o tipo da resposta de sucesso quando não for JSON.
+	ContentType string
+}
+
+// Router é um http.Handler que atende as rotas registradas.
+type Router struct {
+	mux       *http.ServeMux
+	routes    []Route
+	methods   []string
+	auth      func(http.Handler) http.Handler
+	rateLimit func(policy string) func(http.Handler) http.Handler
+}
+
+// New cria um Router. auth é aplicado às rotas com Auth e rateLimit devolve o
+// middleware da política informada em Route.RateLimit.
+func New(auth func(http.Handler) http.Handler, rateLimit func(policy string) func(http.Handler) http.Handler) *Router {
+	return &Router{
+		mux:       http.NewServeMux(),
+		auth:      auth,
+		rateLimit: rateLimit,
+	}
+}
+
+// Handle registra as rotas. Como no http.ServeMux, um padrão repetido ou
+// inválido causa panic na inicialização.
+func (rt *Router) Handle(routes ...Route) {
+	for _, route := range routes {
+		handler := route.Handler
+		if route.RateLimit != "" {
+			handler = rt.rateLimit(route.RateLimit)(handler)
+		}
+		if route.Auth {
+			handler = rt.auth(handler)
+		}
+		rt.mux.Handle(route.Method+" "+route.Pattern, handler)
+
+		rt.routes = append(rt.routes, route)
+		if !slices.Contains(rt.methods, route.Method) {
+			rt.methods = append(rt.methods, route.Method)
+		}
+	}
+}
+
+// Routes devolve as rotas registradas, na ordem de registro.
+func (rt *Router) Routes() []Route {
+	return slices.Clone(rt.routes)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if _, pattern := rt.mux.Handler(r); pattern != "" {
+		rt.mux.ServeHTTP(w, r)
+		return
+	}
+
+	allowed := rt.allowedMethods(r)
+	if len(allowed) == 0 {
+		problem.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Allow", strings.Join(allowed, ", "))
+	problem.Error(w, r, http.StatusMethodNotAllowed, "request.method_not_allowed")
+}
+
+// allowedMethods lista os métodos registrados para o caminho da requisição.
+func (rt *Router) allowedMethods(r *http.Request) []string {
+	var allowed []string
+	for _, method := range rt.methods {
+		probe := &http.Request{Method: method, URL: r.URL, Host: r.Host}
+		if _, pattern := rt.mux.Handler(probe); pattern != "" {
+			allowed = append(allowed, method)
+		}
+	}
+	// O http.ServeMux atende HEAD com as rotas GET.
+	if slices.Contains(allowed, http.MethodGet) {
+		allowed = append(allowed, http.MethodHead)
+	}
+	slices.Sort(allowed)
+	return allowed
+}
+
+// invalidIDMessages traduz o nome do parâmetro de rota na mensagem de ID inválido.
+var invalidIDMessages = map[string]string{
+	"groupId":       "group.invalid_id",
+	"destinationId": "destination.invalid_id",
+	"expenseId":     "expense.invalid_id",
+	"itemId":        "itinerary.invalid_id",
+	"inviteId":      "invite.invalid_id",
+	"votingId":      "voting.invalid_id",
+	"userId":        "member.invalid_id",
+	"attachmentId":  "attachment.invalid_id",
+	"paymentId":     "payment.invalid_id",
+}
+
+// pathID lê o parâmetro de rota como ID numérico positivo. Se o valor for
+// inválido, responde 400 e devolve false.
+func pathID(w http.ResponseWriter, r *http.Request, name string) (int, bool) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		if message, ok := invalidIDMessages[name]; ok {
+			problem.Error(w, r, http.StatusBadRequest, message)
+		} else {
+			problem.Error(w, r, http.StatusBadRequest, "request.invalid_id", name)
+		}
+		return 0, false
+	}
+	return id, true
+}
+
+// ID adapta um handler que recebe o ID do parâmetro de rota name.
+func ID(name string, h func(http.ResponseWriter, *http.Request, int)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id, ok := pathID(w, r, name)
+		if !ok {
+			return
+		}
+		h(w, r, id)
+	})
+}
+
+// IDs adapta um handler que recebe dois IDs, como /groups/{groupId}/expenses/{expenseId}.
+func IDs(first, second string, h func(http.ResponseWriter, *http.Request, int, int)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		firstID, ok := pathID(w, r, first)
+		if !ok {
+			return
+		}
+		secondID, ok := pathID(w, r, second)
+		if !ok {
+			return
+		}
+		h(w, r, firstID, secondID)
+	})
+}
+
+// IDs3 adapta um handler que recebe três IDs, como
+// /groups/{groupId}/expenses/{expenseId}/attachments/{attachmentId}.
+func IDs3(first, second, third string, h func(http.ResponseWriter, *http.Request, int, int, int)) http.Handler {
+	return IDs(first, second, func(w http.ResponseWriter, r *http.Request, firstID, secondID int) {
+		thirdID, ok := pathID(w, r, third)
+		if !ok {
+			return
+		}
+		h(w, r, firstID, secondID, thirdID)
+	})
+}
+
+// Param adapta um handler que recebe o parâmetro de rota name como texto.
+func Param(name string, h func(http.ResponseWriter, *http.Request, string)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		h(w, r, r.PathValue(name))
+	})
+}
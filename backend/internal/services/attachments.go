@@ -0,0 +1,226 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+	"project_lab/internal/storage"
+	"slices"
+	"strings"
+	"unicode/utf8"
+)
+
+var (
+	// ErrInvalidAttachment indica um arquivo vazio, de tipo não aceito ou corrompido.
+	ErrInvalidAttachment = errors.New("comprovante inválido")
+	// ErrAttachmentTooLarge indica um arquivo acima do tamanho máximo.
+	ErrAttachmentTooLarge = errors.New("comprovante acima do tamanho máximo")
+	// ErrTooManyAttachments indica que a despesa já tem o máximo de comprovantes.
+	ErrTooManyAttachments = errors.New("despesa com comprovantes demais")
+)
+
+const (
+	// DefaultAttachmentMaxSize é o tamanho máximo de um comprovante quando
+	// ATTACHMENT_MAX_SIZE_MB não é definido.
+	DefaultAttachmentMaxSize = 10 << 20
+	maxAttachmentsPerExpense = 10
+	maxFileNameLength        = 255
+)
+
+// attachmentTypes são os tipos aceitos, detectados no conteúdo do arquivo (o
+// Content-Type enviado pelo cliente é ignorado).
+var attachmentTypes = []string{"image/jpeg", "image/png", "image/gif", "image/webp", "application/pdf"}
+
+// AttachmentService guarda os comprovantes das despesas no BlobStore, com uma
+// miniatura para as imagens.
+type AttachmentService interface {
+	Upload(ctx context.Context, groupID int, attachment *models.ExpenseAttachment, body io.Reader) error
+	List(ctx context.Context, groupID int, expenseID int) ([]models.ExpenseAttachment, error)
+	ListByGroup(ctx context.Context, groupID int) ([]models.ExpenseAttachment, error)
+	Get(ctx context.Context, groupID int, expenseID int, attachmentID int) (*models.ExpenseAttachment, error)
+	Open(ctx context.Context, attachment *models.ExpenseAttachment, thumbnail bool) (io.ReadCloser, error)
+	Delete(ctx context.Context, groupID int, attachment *models.ExpenseAttachment) error
+	DeleteFiles(ctx context.Context, attachments []models.ExpenseAttachment) error
+	MaxSize() int64
+}
+
+type attachmentService struct {
+	repo    repositories.AttachmentRepository
+	blobs   storage.BlobStore
+	maxSize int64
+}
+
+// NewAttachmentService cria uma nova instância de AttachmentService. maxSize é o
+// tamanho máximo de cada arquivo, em bytes (zero usa o padrão).
+func NewAttachmentService(repo repositories.AttachmentRepository, blobs storage.BlobStore, maxSize int64) AttachmentService {
+	if maxSize <= 0 {
+		maxSize = DefaultAttachmentMaxSize
+	}
+	return &attachmentService{repo: repo, blobs: blobs, maxSize: maxSize}
+}
+
+func (s *attachmentService) MaxSize() int64 {
+	return s.maxSize
+}
+
+// Upload confere o arquivo, grava-o (e a miniatura, se for uma imagem) no
+// BlobStore e registra o comprovante. Se o registro falhar (inclusive pelo
+// limite de comprovantes da despesa, conferido pelo repositório), os arquivos
+// são apagados.
+func (s *attachmentService) Upload(ctx context.Context, groupID int, attachment *models.ExpenseAttachment, body io.Reader) error {
+	data, err := io.ReadAll(io.LimitReader(body, s.maxSize+1))
+	if err != nil {
+		return err
+	}
+	if int64(len(data)) > s.maxSize {
+		return i18n.Errorf(ErrAttachmentTooLarge, "attachment.too_large", s.maxSize>>20)
+	}
+	if len(data) == 0 {
+		return i18n.Errorf(ErrInvalidAttachment, "attachment.empty")
+	}
+
+	contentType := http.DetectContentType(data)
+	if !slices.Contains(attachmentTypes, contentType) {
+		return i18n.Errorf(ErrInvalidAttachment, "attachment.unsupported_type", "JPEG, PNG, GIF, WebP, PDF")
+	}
+
+	thumbnail, err := makeThumbnail(contentType, data)
+	if err != nil {
+		return i18n.Errorf(ErrInvalidAttachment, "attachment.corrupted_image")
+	}
+
+	key, err := newBlobKey(attachment.ExpenseID)
+	if err != nil {
+		return err
+	}
+	if err := s.blobs.Put(ctx, key, bytes.NewReader(data), int64(len(data)), contentType); err != nil {
+		return err
+	}
+	keys := []string{key}
+
+	var thumbnailKey *string
+	if thumbnail != nil {
+		tk := key + "_thumb.jpg"
+		if err := s.blobs.Put(ctx, tk, bytes.NewReader(thumbnail), int64(len(thumbnail)), "image/jpeg"); err != nil {
+			s.deleteKeys(keys)
+			return err
+		}
+		thumbnailKey = &tk
+		keys = append(keys, tk)
+	}
+
+	attachment.FileName = cleanFileName(attachment.FileName)
+	attachment.ContentType = contentType
+	attachment.Size = int64(len(data))
+	attachment.StorageKey = key
+	attachment.ThumbnailKey = thumbnailKey
+	if err := s.repo.CreateAttachment(ctx, groupID, attachment, maxAttachmentsPerExpense); err != nil {
+		s.deleteKeys(keys)
+		if errors.Is(err, repositories.ErrAttachmentLimitReached) {
+			return i18n.Errorf(ErrTooManyAttachments, "attachment.too_many", maxAttachmentsPerExpense)
+		}
+		return err
+	}
+	return nil
+}
+
+func (s *attachmentService) List(ctx context.Context, groupID int, expenseID int) ([]models.ExpenseAttachment, error) {
+	return s.repo.ListAttachments(ctx, groupID, expenseID)
+}
+
+// ListByGroup lista os comprovantes de todas as despesas do grupo.
+func (s *attachmentService) ListByGroup(ctx context.Context, groupID int) ([]models.ExpenseAttachment, error) {
+	return s.repo.ListGroupAttachments(ctx, groupID)
+}
+
+func (s *attachmentService) Get(ctx context.Context, groupID int, expenseID int, attachmentID int) (*models.ExpenseAttachment, error) {
+	return s.repo.GetAttachment(ctx, groupID, expenseID, attachmentID)
+}
+
+// Open abre o arquivo do comprovante ou a miniatura dele. Comprovantes sem
+// miniatura (PDF, WebP) respondem ErrAttachmentNotFound para a miniatura.
+func (s *attachmentService) Open(ctx context.Context, attachment *models.ExpenseAttachment, thumbnail bool) (io.ReadCloser, error) {
+	key := attachment.StorageKey
+	if thumbnail {
+		if attachment.ThumbnailKey == nil {
+			return nil, repositories.ErrAttachmentNotFound
+		}
+		key = *attachment.ThumbnailKey
+	}
+	return s.blobs.Get(ctx, key)
+}
+
+// Delete apaga o registro e depois os arquivos, para que um comprovante
+// listado nunca aponte para um arquivo já apagado.
+func (s *attachmentService) Delete(ctx context.Context, groupID int, attachment *models.ExpenseAttachment) error {
+	if err := s.repo.DeleteAttachment(ctx, groupID, attachment.ExpenseID, attachment.ID); err != nil {
+		return err
+	}
+	return s.DeleteFiles(ctx, []models.ExpenseAttachment{*attachment})
+}
+
+// DeleteFiles apaga do BlobStore os arquivos de comprovantes cujos registros já
+// foram removidos (por exemplo, junto com a despesa).
+func (s *attachmentService) DeleteFiles(ctx context.Context, attachments []models.ExpenseAttachment) error {
+	var errs []error
+	for _, a := range attachments {
+		if err := s.blobs.Delete(ctx, a.StorageKey); err != nil {
+			errs = append(errs, err)
+		}
+		if a.ThumbnailKey != nil {
+			if err := s.blobs.Delete(ctx, *a.ThumbnailKey); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// deleteKeys desfaz um upload incompleto. Usa um contexto próprio: o da
+// requisição pode já ter sido cancelado.
+func (s *attachmentService) deleteKeys(keys []string) {
+	for _, key := range keys {
+		if err := s.blobs.Delete(context.Background(), key); err != nil {
+			fmt.Printf("Erro ao apagar arquivo órfão %s: %v\n", key, err)
+		}
+	}
+}
+
+// newBlobKey gera uma chave aleatória, que não revela o nome do arquivo.
+func newBlobKey(expenseID int) (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("erro ao gerar chave do arquivo: %w", err)
+	}
+	return fmt.Sprintf("expenses/%d/%s", expenseID, hex.EncodeToString(b)), nil
+}
+
+// cleanFileName guarda só o nome do arquivo (sem diretórios nem caracteres de
+// controle), com até 255 caracteres.
+func cleanFileName(name string) string {
+	name = path.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f || r == '"' {
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.TrimSpace(name)
+	if name == "" || name == "." || name == ".." || name == "/" {
+		return "comprovante"
+	}
+	for utf8.RuneCountInString(name) > maxFileNameLength {
+		_, size := utf8.DecodeLastRuneInString(name)
+		name = name[:len(name)-size]
+	}
+	return name
+}
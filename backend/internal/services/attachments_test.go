@@ -0,0 +1,161 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+	"project_lab/internal/storage"
+)
+
+func TestCleanFileName(t *testing.T) {
+	long := strings.Repeat("á", 300)
+
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"nome simples", "recibo.pdf", "recibo.pdf"},
+		{"caminho Unix", "/home/ana/recibo.pdf", "recibo.pdf"},
+		{"caminho Windows", `C:\Users\Ana\recibo.pdf`, "recibo.pdf"},
+		{"subida de diretório", "../../etc/passwd", "passwd"},
+		{"subida de diretório no Windows", `..\..\boot.ini`, "boot.ini"},
+		{"caracteres de controle e aspas", "re\x00ci\nbo\x7f\".pdf", "recibo.pdf"},
+		{"espaços nas pontas", "  recibo.pdf  ", "recibo.pdf"},
+		{"vazio", "", "comprovante"},
+		{"só diretório", "../", "comprovante"},
+		{"só ponto", ".", "comprovante"},
+		{"dois pontos", "..", "comprovante"},
+		{"só barras", `\\`, "comprovante"},
+		{"longo demais, cortado sem quebrar caracteres", long, strings.Repeat("á", maxFileNameLength)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := cleanFileName(tt.in); got != tt.want {
+				t.Fatalf("cleanFileName(%q) = %q, esperado %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeAttachmentRepo registra os comprovantes até o limite, como o repositório.
+type fakeAttachmentRepo struct {
+	repositories.AttachmentRepository
+	created []models.ExpenseAttachment
+}
+
+func (f *fakeAttachmentRepo) CreateAttachment(_ context.Context, _ int, attachment *models.ExpenseAttachment, maxPerExpense int) error {
+	if len(f.created) >= maxPerExpense {
+		return repositories.ErrAttachmentLimitReached
+	}
+	attachment.ID = len(f.created) + 1
+	f.created = append(f.created, *attachment)
+	return nil
+}
+
+// storedFiles lista as chaves gravadas no diretório do localStore.
+func storedFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var keys []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			keys = append(keys, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	if err != nil {
+		t.Fatalf("erro ao listar arquivos: %v", err)
+	}
+	return keys
+}
+
+func TestUploadAttachment(t *testing.T) {
+	pngData := encodePNG(t, 640, 320)
+
+	tests := []struct {
+		name      string
+		fileName  string
+		stored    string // nome gravado
+		data      []byte
+		err       error
+		files     int // arquivos gravados no BlobStore
+		thumbnail bool
+	}{
+		{"PNG com miniatura", `C:\fotos\nota.png`, "nota.png", pngData, nil, 2, true},
+		{"PDF sem miniatura", "../nota.pdf", "nota.pdf", []byte("%PDF-1.4\n%âãÏÓ\n"), nil, 1, false},
+		{"vazio", "nada.pdf", "", nil, ErrInvalidAttachment, 0, false},
+		{"tipo não aceito", "nota.txt", "", []byte("só texto"), ErrInvalidAttachment, 0, false},
+		{"imagem corrompida", "nota.png", "", pngData[:60], ErrInvalidAttachment, 0, false},
+		{"imagem com pixels demais", "nota.png", "", pngHeader(t, 50_001, 1_000), ErrInvalidAttachment, 0, false},
+		{"acima do tamanho máximo", "grande.pdf", "", append([]byte("%PDF-1.4\n"), make([]byte, 4096)...), ErrAttachmentTooLarge, 0, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			blobs, err := storage.NewLocalStore(dir)
+			if err != nil {
+				t.Fatalf("erro ao criar LocalStore: %v", err)
+			}
+			repo := &fakeAttachmentRepo{}
+			service := NewAttachmentService(repo, blobs, 4096)
+
+			attachment := models.ExpenseAttachment{ExpenseID: 12, FileName: tt.fileName}
+			err = service.Upload(context.Background(), 1, &attachment, bytes.NewReader(tt.data))
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("erro = %v, esperado %v", err, tt.err)
+			}
+			if files := storedFiles(t, dir); len(files) != tt.files {
+				t.Fatalf("arquivos gravados = %v, esperado %d", files, tt.files)
+			}
+			if tt.err != nil {
+				return
+			}
+			if attachment.FileName != tt.stored {
+				t.Fatalf("nome gravado = %q, esperado %q", attachment.FileName, tt.stored)
+			}
+			if (attachment.ThumbnailKey != nil) != tt.thumbnail {
+				t.Fatalf("miniatura = %v, esperado %v", attachment.ThumbnailKey, tt.thumbnail)
+			}
+		})
+	}
+}
+
+// Quando o repositório recusa o comprovante pelo limite da despesa, o erro é o
+// do serviço e os arquivos já gravados são apagados.
+func TestUploadAttachmentLimit(t *testing.T) {
+	dir := t.TempDir()
+	blobs, err := storage.NewLocalStore(dir)
+	if err != nil {
+		t.Fatalf("erro ao criar LocalStore: %v", err)
+	}
+	repo := &fakeAttachmentRepo{}
+	service := NewAttachmentService(repo, blobs, 0)
+	pngData := encodePNG(t, 20, 20)
+
+	for i := 0; i < maxAttachmentsPerExpense; i++ {
+		attachment := models.ExpenseAttachment{ExpenseID: 12, FileName: "nota.png"}
+		if err := service.Upload(context.Background(), 1, &attachment, bytes.NewReader(pngData)); err != nil {
+			t.Fatalf("erro no comprovante %d: %v", i+1, err)
+		}
+	}
+	before := storedFiles(t, dir)
+
+	attachment := models.ExpenseAttachment{ExpenseID: 12, FileName: "nota.png"}
+	err = service.Upload(context.Background(), 1, &attachment, bytes.NewReader(pngData))
+	if !errors.Is(err, ErrTooManyAttachments) {
+		t.Fatalf("erro = %v, esperado ErrTooManyAttachments", err)
+	}
+	if after := storedFiles(t, dir); len(after) != len(before) {
+		t.Fatalf("arquivos gravados = %d, esperado %d (os do comprovante recusado deveriam ser apagados)", len(after), len(before))
+	}
+}
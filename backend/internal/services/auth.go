@@ -1,90 +1,281 @@
 package services
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"net/url"
+	"project_lab/internal/i18n"
 	"project_lab/internal/models"
 	"project_lab/internal/repositories"
+	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
+const (
+	emailVerificationTTL = 48 * time.Hour
+	passwordResetTTL     = time.Hour
+)
+
+// dummyPasswordHash é comparado com a senha quando o e-mail não tem conta, para
+// que o login de um e-mail desconhecido leve o mesmo tempo (o custo do bcrypt)
+// que o de um existente e a resposta não revele quais e-mails estão cadastrados.
+// Usa o mesmo custo dos hashes gerados no cadastro.
+const dummyPasswordHash = "$2a$10$NTjVY1SLbOiEl7eP4L/ZNegKhLN/4n0sWFgNRHBLuN5jfPE20tya6"
+
 // AuthService é a interface que define a lógica de negócio de autenticação.
 type AuthService interface {
-	RegisterUser(user *models.User) error
-	Authenticate(email, password string) (string, error)
+	RegisterUser(ctx context.Context, user *models.User) error
+	Authenticate(ctx context.Context, email, password string, client models.ClientInfo) (*models.AuthTokens, error)
+	SendVerificationEmail(ctx context.Context, userID int) error
+	VerifyEmail(ctx context.Context, token string) error
+	RequestPasswordReset(ctx context.Context, email string) error
+	ResetPassword(ctx context.Context, token, newPassword string) error
 }
 
 // authService implementa a interface AuthService.
 type authService struct {
-	userRepo repositories.UserRepository
+	userRepo     repositories.UserRepository
+	tokenService TokenService
+	mailer       Mailer
+	frontendURL  string
+	passwords    *PasswordPolicy
+	attempts     repositories.LoginAttemptRepository
+	throttle     LoginThrottlePolicy
 }
 
-// NewAuthService cria uma nova instância de AuthService.
-func NewAuthService(userRepo repositories.UserRepository) AuthService {
+// NewAuthService cria uma nova instância de AuthService. frontendURL é a base
+// dos links enviados por e-mail (/verify-email e /reset-password do frontend).
+func NewAuthService(userRepo repositories.UserRepository, tokenService TokenService, mailer Mailer, frontendURL string, passwords *PasswordPolicy, attempts repositories.LoginAttemptRepository) AuthService {
 	return &authService{
-		userRepo: userRepo,
+		userRepo:     userRepo,
+		tokenService: tokenService,
+		mailer:       mailer,
+		frontendURL:  strings.TrimRight(frontendURL, "/"),
+		passwords:    passwords,
+		attempts:     attempts,
+		throttle:     DefaultLoginThrottle,
 	}
 }
 
-// RegisterUser lida com a lógica de negócio do cadastro.
-func (s *authService) RegisterUser(user *models.User) error {
+// RegisterUser lida com a lógica de negócio do cadastro. Nome e e-mail são
+// normalizados em user; problemas nos campos voltam como *ValidationError.
+func (s *authService) RegisterUser(ctx context.Context, user *models.User) error {
+	user.Name = NormalizeName(user.Name)
+	user.Email = NormalizeEmail(user.Email)
+
+	invalid := &ValidationError{}
+	if msg := ValidateName(user.Name); msg != nil {
+		invalid.Add("name", *msg)
+	}
+	if msg := ValidateEmail(user.Email); msg != nil {
+		invalid.Add("email", *msg)
+	}
+	if msg := s.passwords.Check(user.Password, user.Email); msg != nil {
+		invalid.Add("password", *msg)
+	}
+	if err := invalid.OrNil(); err != nil {
+		return err
+	}
 
 	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
 	if err != nil {
-		return errors.New("erro ao gerar hash da senha")
+		return fmt.Errorf("erro ao gerar hash da senha: %w", err)
 	}
 	user.PasswordHash = string(hashedPassword)
 
-	if err := s.userRepo.CreateUser(user); err != nil {
+	if err := s.userRepo.CreateUser(ctx, user); err != nil {
 		return err
 	}
 
+	// O cadastro não depende do envio: o usuário pode pedir outro e-mail depois.
+	if err := s.sendVerification(ctx, user); err != nil {
+		fmt.Printf("Erro ao enviar e-mail de verificação para o usuário %d: %v\n", user.ID, err)
+	}
+
 	return nil
 }
 
-// Authenticate autentica um usuário.
-func (s *authService) Authenticate(email, password string) (string, error) {
+// Authenticate autentica um usuário e inicia uma sessão. As falhas são
+// contadas por e-mail (exista a conta ou não, para não revelar quais existem):
+// depois de algumas, o e-mail fica bloqueado por um tempo que dobra a cada nova
+// falha, e enquanto isso nem a senha é conferida.
+func (s *authService) Authenticate(ctx context.Context, email, password string, client models.ClientInfo) (*models.AuthTokens, error) {
+	email = NormalizeEmail(email)
+
+	lockedUntil, err := s.attempts.GetLockedUntil(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	if lockedUntil != nil && time.Now().Before(*lockedUntil) {
+		user, _ := s.userRepo.FindByEmail(ctx, email)
+		s.audit(ctx, email, user, client, models.LoginFailureLocked)
+		return nil, &AccountLockedError{Until: *lockedUntil}
+	}
+
 	//Busca o usuário pelo e-mail
-	user, err := s.userRepo.FindByEmail(email)
+	user, err := s.userRepo.FindByEmail(ctx, email)
 	if err != nil {
-		return "", errors.New("usuário ou senha incorretos")
+		if !errors.Is(err, repositories.ErrUserNotFound) {
+			return nil, err
+		}
+		bcrypt.CompareHashAndPassword([]byte(dummyPasswordHash), []byte(password))
+		return nil, s.loginFailed(ctx, email, nil, client, models.LoginFailureUnknownUser)
 	}
 
 	//Compara a senha com o hash no banco
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash), []byte(password)); err != nil {
-		return "", errors.New("usuário ou senha incorretos")
+		return nil, s.loginFailed(ctx, email, user, client, models.LoginFailureWrongPassword)
+	}
+
+	if err := s.attempts.ResetFailedLogins(ctx, email); err != nil {
+		return nil, err
+	}
+
+	//  Gerar e retornar os tokens
+	tokens, err := s.tokenService.IssueTokens(ctx, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("falha ao gerar token de autenticação: %w", err)
+	}
+	return tokens, nil
+}
+
+// loginFailed registra a auditoria, conta a falha e bloqueia o e-mail se o
+// limite foi atingido. Devolve o erro que Authenticate deve retornar.
+func (s *authService) loginFailed(ctx context.Context, email string, user *models.User, client models.ClientInfo, reason string) error {
+	s.audit(ctx, email, user, client, reason)
+
+	failures, err := s.attempts.RecordFailedLogin(ctx, email, s.throttle.Window)
+	if err != nil {
+		return err
+	}
+	if delay := s.throttle.Delay(failures); delay > 0 {
+		until := time.Now().Add(delay)
+		if err := s.attempts.LockUntil(ctx, email, until); err != nil {
+			return err
+		}
+		return &AccountLockedError{Until: until}
+	}
+	return ErrInvalidCredentials
+}
+
+// audit registra a tentativa malsucedida; uma falha aqui não muda o resultado do login.
+func (s *authService) audit(ctx context.Context, email string, user *models.User, client models.ClientInfo, reason string) {
+	entry := &models.LoginAuditEntry{Email: email, IP: client.IP, UserAgent: client.UserAgent, Reason: reason}
+	if user != nil {
+		entry.UserID = &user.ID
+	}
+	if err := s.attempts.AddAuditEntry(ctx, entry); err != nil {
+		fmt.Printf("Erro ao registrar auditoria de login: %v\n", err)
+	}
+}
+
+// SendVerificationEmail reenvia o link de verificação para um usuário ainda não verificado.
+func (s *authService) SendVerificationEmail(ctx context.Context, userID int) error {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return err
 	}
+	if user.EmailVerifiedAt != nil {
+		return nil
+	}
+	return s.sendVerification(ctx, user)
+}
 
-	//  Gerar e retornar o JWT
-	token, err := s.generateToken(user.ID)
+func (s *authService) sendVerification(ctx context.Context, user *models.User) error {
+	token, err := s.tokenService.IssueActionToken(user.ID, PurposeVerifyEmail, emailVerificationTTL)
 	if err != nil {
-		return "", errors.New("falha ao gerar token de autenticação")
+		return err
 	}
-	return token, nil
+	locale := userLocale(user)
+	return s.mailer.Send(Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, "email.verify.subject"),
+		Body:    i18n.T(locale, "email.verify.body", user.Name, s.link("/verify-email", token)),
+	})
 }
 
-func (s *authService) generateToken(userID int) (string, error) {
-	// Define o tempo de expiração do token (Nesse casos 24 horas)
-	expirationTime := time.Now().Add(24 * time.Hour)
+// VerifyEmail confirma o e-mail do usuário do token.
+func (s *authService) VerifyEmail(ctx context.Context, token string) error {
+	claims, err := s.tokenService.ConsumeActionToken(ctx, token, PurposeVerifyEmail)
+	if err != nil {
+		return err
+	}
+	if err := s.userRepo.MarkEmailVerified(ctx, claims.UserID); err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return ErrInvalidActionToken
+		}
+		return err
+	}
+	return nil
+}
+
+// RequestPasswordReset envia o link de redefinição se o e-mail estiver
+// cadastrado. Não informa se o e-mail existe, para não permitir enumeração.
+func (s *authService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.FindByEmail(ctx, NormalizeEmail(email))
+	if err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return nil
+		}
+		return err
+	}
 
-	// Cria os dados do token
-	claims := &UserClaims{
-		UserID: userID,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-		},
+	token, err := s.tokenService.IssueActionToken(user.ID, PurposeResetPassword, passwordResetTTL)
+	if err != nil {
+		return err
 	}
+	locale := userLocale(user)
+	return s.mailer.Send(Message{
+		To:      user.Email,
+		Subject: i18n.T(locale, "email.reset.subject"),
+		Body:    i18n.T(locale, "email.reset.body", user.Name, s.link("/reset-password", token)),
+	})
+}
 
-	// Cria o token usando o algoritmo de assinatura (HS256)
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+// ResetPassword troca a senha do usuário do token e encerra todas as sessões dele.
+// Quem recebeu o link por e-mail provou ser dono do endereço, então o e-mail
+// também passa a constar como verificado.
+func (s *authService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	// A senha é conferida antes de consumir o token, para que o usuário possa
+	// tentar outra com o mesmo link. A comparação com o e-mail fica de fora:
+	// ele só é conhecido depois de validar o token.
+	if msg := s.passwords.Check(newPassword, ""); msg != nil {
+		return &ValidationError{Fields: []FieldError{{Field: "password", Message: *msg}}}
+	}
 
-	// Assina o token com a chave secreta
-	tokenString, err := token.SignedString([]byte(JwtSecret))
+	claims, err := s.tokenService.ConsumeActionToken(ctx, token, PurposeResetPassword)
+	if err != nil {
+		return err
+	}
 
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(newPassword), bcrypt.DefaultCost)
 	if err != nil {
-		return "", err
+		return fmt.Errorf("erro ao gerar hash da senha: %w", err)
+	}
+	if err := s.userRepo.UpdatePassword(ctx, claims.UserID, string(hashedPassword)); err != nil {
+		if errors.Is(err, repositories.ErrUserNotFound) {
+			return ErrInvalidActionToken
+		}
+		return err
 	}
-	return tokenString, nil
+
+	if err := s.tokenService.RevokeAll(ctx, claims.UserID); err != nil {
+		return err
+	}
+	return s.userRepo.MarkEmailVerified(ctx, claims.UserID)
+}
+
+// userLocale é o idioma dos e-mails: o preferido do usuário ou o padrão.
+func userLocale(user *models.User) i18n.Locale {
+	if locale, ok := i18n.Parse(user.Locale); ok {
+		return locale
+	}
+	return i18n.Default
+}
+
+func (s *authService) link(path, token string) string {
+	return s.frontendURL + path + "?token=" + url.QueryEscape(token)
 }
@@ -0,0 +1,19 @@
+package services
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// O hash usado para e-mails desconhecidos precisa ter o mesmo custo dos hashes
+// do cadastro; senão o tempo de resposta volta a revelar quais e-mails existem.
+func TestDummyPasswordHashMatchesRegistrationCost(t *testing.T) {
+	cost, err := bcrypt.Cost([]byte(dummyPasswordHash))
+	if err != nil {
+		t.Fatalf("hash inválido: %v", err)
+	}
+	if cost != bcrypt.DefaultCost {
+		t.Fatalf("custo = %d, esperado %d", cost, bcrypt.DefaultCost)
+	}
+}
@@ -0,0 +1,295 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+	"strings"
+)
+
+// maxAlertThreshold limita o percentual de aviso (10x o planejado).
+const maxAlertThreshold = 1000
+
+// BudgetService compara o orçamento do grupo com as despesas e avisa os
+// organizadores quando uma categoria atinge o limite.
+type BudgetService interface {
+	GetReport(ctx context.Context, groupID int) (*models.BudgetReport, error)
+	SaveBudget(ctx context.Context, groupID int, req models.BudgetUpdateRequest) error
+	DeleteBudget(ctx context.Context, groupID int) error
+	CheckAlerts(ctx context.Context, groupID int) error
+}
+
+type budgetService struct {
+	budgets   repositories.BudgetRepository
+	groupRepo repositories.TravelGroupRepository
+	users     repositories.UserRepository
+	expenses  *settlementService
+	mailer    Mailer
+}
+
+// NewBudgetService cria uma nova instância de BudgetService. As despesas são
+// convertidas para a moeda base como nos saldos.
+func NewBudgetService(budgets repositories.BudgetRepository, groupRepo repositories.TravelGroupRepository, users repositories.UserRepository, rates ExchangeRateService, mailer Mailer) BudgetService {
+	return &budgetService{
+		budgets:   budgets,
+		groupRepo: groupRepo,
+		users:     users,
+		expenses:  &settlementService{groupRepo: groupRepo, rates: rates},
+		mailer:    mailer,
+	}
+}
+
+// loadBudget devolve o orçamento gravado ou, se o grupo ainda não tem um, um
+// orçamento vazio com o limite de aviso padrão.
+func (s *budgetService) loadBudget(ctx context.Context, groupID int) (*models.GroupBudget, error) {
+	budget, err := s.budgets.GetBudget(ctx, groupID)
+	if errors.Is(err, repositories.ErrBudgetNotFound) {
+		return &models.GroupBudget{TravelGroupID: groupID, AlertThreshold: models.DefaultAlertThreshold}, nil
+	}
+	return budget, err
+}
+
+// GetReport calcula o planejado x realizado do grupo, por categoria e por membro.
+func (s *budgetService) GetReport(ctx context.Context, groupID int) (*models.BudgetReport, error) {
+	budget, err := s.loadBudget(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	expenses, currency, err := s.expenses.loadExpenses(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := s.groupRepo.ListGroupMembers(ctx, groupID, true)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildBudgetReport(budget, expenses, members, currency), nil
+}
+
+// buildBudgetReport soma as despesas (já na moeda base) por categoria e o que
+// cabe a cada membro, e compara com o orçamento.
+func buildBudgetReport(budget *models.GroupBudget, expenses []models.ExpenseDTO, members []models.GroupMemberDTO, currency string) *models.BudgetReport {
+	spentByCategory := map[string]int64{}
+	var total int64
+	for _, exp := range expenses {
+		category := exp.Category
+		if !models.IsValidExpenseCategory(category) {
+			category = models.CategoryOther
+		}
+		spentByCategory[category] += int64(exp.Amount)
+		total += int64(exp.Amount)
+	}
+
+	planned := map[string]models.Money{}
+	for _, c := range budget.Categories {
+		planned[c.Category] = c.Planned
+	}
+
+	report := &models.BudgetReport{
+		Currency:       currency,
+		AlertThreshold: budget.AlertThreshold,
+		Planned:        budget.Total,
+		Spent:          models.Money(total),
+		PerPerson:      budget.PerPerson,
+		Categories:     make([]models.CategoryBudgetReport, 0, len(models.ExpenseCategories)),
+		Members:        []models.MemberBudgetReport{},
+	}
+	if budget.Total != nil {
+		remaining := *budget.Total - report.Spent
+		report.Remaining = &remaining
+		report.OverBudget = report.Spent > *budget.Total
+	}
+
+	for _, category := range models.ExpenseCategories {
+		line := models.CategoryBudgetReport{Category: category, Spent: models.Money(spentByCategory[category])}
+		if p, ok := planned[category]; ok {
+			remaining := p - line.Spent
+			percent := int(int64(line.Spent) * 100 / int64(p))
+			line.Planned = &p
+			line.Remaining = &remaining
+			line.PercentUsed = &percent
+			line.OverBudget = line.Spent > p
+			line.Alert = reachedThreshold(line.Spent, p, budget.AlertThreshold)
+		}
+		report.Categories = append(report.Categories, line)
+	}
+
+	for _, e := range buildLedger(members, expenses, nil) {
+		member := models.MemberBudgetReport{
+			UserID:       e.userID,
+			Name:         e.name,
+			Spent:        models.Money(e.owed),
+			Target:       budget.PerPerson,
+			FormerMember: e.former,
+		}
+		if budget.PerPerson != nil {
+			member.OverBudget = member.Spent > *budget.PerPerson
+		}
+		report.Members = append(report.Members, member)
+	}
+
+	return report
+}
+
+// reachedThreshold indica se o gasto chegou ao percentual de aviso do planejado.
+func reachedThreshold(spent, planned models.Money, threshold int) bool {
+	return int64(spent)*100 >= int64(planned)*int64(threshold)
+}
+
+// validateBudgetAmount confere um valor planejado: positivo e dentro do que
+// cabe no banco (models.MaxMoney).
+func validateBudgetAmount(invalid *ValidationError, field string, amount models.Money) {
+	switch {
+	case amount <= 0:
+		invalid.Add(field, i18n.M("validation.budget_amount_positive"))
+	case amount > models.MaxMoney:
+		invalid.Add(field, i18n.M("validation.amount_too_large", models.MaxMoney.String()))
+	}
+}
+
+// SaveBudget valida e grava o orçamento do grupo, substituindo o anterior.
+func (s *budgetService) SaveBudget(ctx context.Context, groupID int, req models.BudgetUpdateRequest) error {
+	invalid := &ValidationError{}
+
+	if req.Total != nil {
+		validateBudgetAmount(invalid, "total", *req.Total)
+	}
+	if req.PerPerson != nil {
+		validateBudgetAmount(invalid, "perPerson", *req.PerPerson)
+	}
+
+	threshold := req.AlertThreshold
+	if threshold == 0 {
+		threshold = models.DefaultAlertThreshold
+	}
+	if threshold < 1 || threshold > maxAlertThreshold {
+		invalid.Add("alertThreshold", i18n.M("validation.budget_threshold_range", maxAlertThreshold))
+	}
+
+	budget := &models.GroupBudget{
+		TravelGroupID:  groupID,
+		Total:          req.Total,
+		PerPerson:      req.PerPerson,
+		AlertThreshold: threshold,
+		Categories:     make([]models.CategoryBudget, 0, len(req.Categories)),
+	}
+	seen := map[string]bool{}
+	for i, c := range req.Categories {
+		field := fmt.Sprintf("categories[%d]", i)
+		category := strings.TrimSpace(c.Category)
+		switch {
+		case !models.IsValidExpenseCategory(category):
+			invalid.Add(field+".category", i18n.M("validation.expense_category_invalid", strings.Join(models.ExpenseCategories, ", ")))
+		case seen[category]:
+			invalid.Add(field+".category", i18n.M("validation.budget_category_duplicate"))
+		}
+		validateBudgetAmount(invalid, field+".planned", c.Planned)
+		seen[category] = true
+		budget.Categories = append(budget.Categories, models.CategoryBudget{Category: category, Planned: c.Planned})
+	}
+
+	if err := invalid.OrNil(); err != nil {
+		return err
+	}
+	return s.budgets.SaveBudget(ctx, budget)
+}
+
+func (s *budgetService) DeleteBudget(ctx context.Context, groupID int) error {
+	return s.budgets.DeleteBudget(ctx, groupID)
+}
+
+// CheckAlerts avisa por e-mail o organizador e os co-organizadores sobre as
+// categorias que atingiram o limite de aviso. Cada categoria gera um único
+// aviso até voltar a ficar abaixo do limite.
+func (s *budgetService) CheckAlerts(ctx context.Context, groupID int) error {
+	budget, err := s.budgets.GetBudget(ctx, groupID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrBudgetNotFound) {
+			return nil
+		}
+		return err
+	}
+	if len(budget.Categories) == 0 {
+		return nil
+	}
+
+	report, err := s.GetReport(ctx, groupID)
+	if err != nil {
+		return err
+	}
+
+	var reached []models.CategoryBudgetReport
+	for _, line := range report.Categories {
+		if line.Planned == nil {
+			continue
+		}
+		if !line.Alert {
+			if err := s.budgets.ClearCategoryAlert(ctx, groupID, line.Category); err != nil {
+				return err
+			}
+			continue
+		}
+		claimed, err := s.budgets.MarkCategoryAlerted(ctx, groupID, line.Category)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			reached = append(reached, line)
+		}
+	}
+	if len(reached) == 0 {
+		return nil
+	}
+
+	return s.notifyOrganizers(ctx, groupID, report.Currency, reached)
+}
+
+// notifyOrganizers envia um e-mail por categoria a quem pode alterar o grupo,
+// no idioma preferido de cada um.
+func (s *budgetService) notifyOrganizers(ctx context.Context, groupID int, currency string, reached []models.CategoryBudgetReport) error {
+	members, err := s.groupRepo.ListGroupMembers(ctx, groupID, false)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	groupName := ""
+	for _, m := range members {
+		if !RoleCan(m.Role, PermEditGroup) {
+			continue
+		}
+		if groupName == "" {
+			group, err := s.groupRepo.GetGroupDetails(ctx, groupID, m.UserID)
+			if err != nil {
+				return err
+			}
+			groupName = group.Name
+		}
+
+		user, err := s.users.FindByID(ctx, m.UserID)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		locale := userLocale(user)
+		for _, line := range reached {
+			category := i18n.T(locale, "budget.category."+line.Category)
+			err := s.mailer.Send(Message{
+				To:      user.Email,
+				Subject: i18n.T(locale, "email.budget_alert.subject", groupName, category),
+				Body: i18n.T(locale, "email.budget_alert.body", user.Name, groupName, line.Spent.String(), currency,
+					category, *line.PercentUsed, line.Planned.String(), currency),
+			})
+			if err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,129 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"project_lab/internal/i18n"
+	"project_lab/internal/repositories"
+	"time"
+)
+
+// CalendarService reúne os eventos de calendário dos grupos: datas da viagem,
+// itens do roteiro e prazos de votações.
+type CalendarService interface {
+	GroupEvents(ctx context.Context, groupID int, userID int) (string, []CalendarEvent, error)
+	UserEvents(ctx context.Context, userID int) (string, []CalendarEvent, error)
+}
+
+type calendarService struct {
+	groupRepo     repositories.TravelGroupRepository
+	itineraryRepo repositories.ItineraryRepository
+	users         repositories.UserRepository
+}
+
+// NewCalendarService cria uma nova instância de CalendarService.
+func NewCalendarService(groupRepo repositories.TravelGroupRepository, itineraryRepo repositories.ItineraryRepository, users repositories.UserRepository) CalendarService {
+	return &calendarService{groupRepo: groupRepo, itineraryRepo: itineraryRepo, users: users}
+}
+
+// GroupEvents retorna o nome e os eventos de um grupo do qual userID é membro,
+// com os textos no idioma da requisição.
+func (s *calendarService) GroupEvents(ctx context.Context, groupID int, userID int) (string, []CalendarEvent, error) {
+	group, err := s.groupRepo.GetGroupDetails(ctx, groupID, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	locale := i18n.FromContext(ctx)
+	events, err := s.groupEvents(ctx, locale, group.ID, group.Name, group.Description, group.StartDate, group.EndDate, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	return group.Name, events, nil
+}
+
+// UserEvents retorna o nome do calendário e os eventos de todos os grupos do
+// usuário (feed de calendário). Quem busca o feed é o aplicativo de
+// calendário, então os textos seguem o idioma preferido do dono do feed; sem
+// preferência, o da requisição.
+func (s *calendarService) UserEvents(ctx context.Context, userID int) (string, []CalendarEvent, error) {
+	user, err := s.users.FindByID(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+	locale, ok := i18n.Parse(user.Locale)
+	if !ok {
+		locale = i18n.FromContext(ctx)
+	}
+
+	groups, err := s.groupRepo.ListGroupsByUserId(ctx, userID)
+	if err != nil {
+		return "", nil, err
+	}
+
+	events := []CalendarEvent{}
+	for _, g := range groups {
+		groupEvents, err := s.groupEvents(ctx, locale, g.ID, g.Name, g.Description, g.StartDate, g.EndDate, userID)
+		if err != nil {
+			return "", nil, err
+		}
+		events = append(events, groupEvents...)
+	}
+	return i18n.T(locale, "calendar.feed_name"), events, nil
+}
+
+func (s *calendarService) groupEvents(ctx context.Context, locale i18n.Locale, groupID int, name string, description string, startDate time.Time, endDate time.Time, userID int) ([]CalendarEvent, error) {
+	// A viagem é um evento de dia inteiro; no iCalendar o DTEND é exclusivo.
+	events := []CalendarEvent{{
+		UID:         fmt.Sprintf("group-%d@project-lab", groupID),
+		Summary:     i18n.T(locale, "calendar.trip", name),
+		Description: description,
+		Start:       startDate,
+		End:         endDate.AddDate(0, 0, 1),
+		AllDay:      true,
+	}}
+
+	items, err := s.itineraryRepo.ListItems(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	for _, item := range items {
+		event := CalendarEvent{
+			UID:         fmt.Sprintf("itinerary-%d@project-lab", item.ID),
+			Summary:     fmt.Sprintf("%s (%s)", item.Activity, name),
+			Description: item.Notes,
+			Start:       item.StartsAt.Time,
+			End:         item.EndsAt.Time,
+			Floating:    true,
+		}
+		if item.DestinationName != nil {
+			event.Location = *item.DestinationName
+		}
+		if item.EstimatedCost != nil {
+			cost := i18n.T(locale, "calendar.estimated_cost", item.EstimatedCost, item.Currency)
+			if event.Description != "" {
+				cost = event.Description + "\n" + cost
+			}
+			event.Description = cost
+		}
+		events = append(events, event)
+	}
+
+	votings, err := s.groupRepo.ListGroupVotings(ctx, groupID, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, v := range votings {
+		if v.ClosesAt == nil {
+			continue
+		}
+		events = append(events, CalendarEvent{
+			UID:         fmt.Sprintf("voting-%d-deadline@project-lab", v.ID),
+			Summary:     i18n.T(locale, "calendar.voting_deadline", v.Question, name),
+			Description: i18n.T(locale, "calendar.voting_deadline_description", name),
+			Start:       *v.ClosesAt,
+		})
+	}
+
+	return events, nil
+}
@@ -0,0 +1,94 @@
+package services
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+)
+
+type fakeCalendarGroups struct {
+	repositories.TravelGroupRepository
+}
+
+func (fakeCalendarGroups) ListGroupsByUserId(context.Context, int) ([]models.TravelGroupListItem, error) {
+	return []models.TravelGroupListItem{{
+		ID:        7,
+		Name:      "Lisboa",
+		StartDate: time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+		EndDate:   time.Date(2025, 5, 5, 0, 0, 0, 0, time.UTC),
+	}}, nil
+}
+
+func (fakeCalendarGroups) ListGroupVotings(context.Context, int, int) ([]models.VotingDTO, error) {
+	closesAt := time.Date(2025, 4, 20, 12, 0, 0, 0, time.UTC)
+	return []models.VotingDTO{{ID: 3, Question: "Hotel?", ClosesAt: &closesAt}}, nil
+}
+
+type fakeCalendarItinerary struct {
+	repositories.ItineraryRepository
+}
+
+func (fakeCalendarItinerary) ListItems(context.Context, int) ([]models.ItineraryItem, error) {
+	cost := models.Money(2500)
+	return []models.ItineraryItem{{ID: 1, Activity: "Museu", EstimatedCost: &cost, Currency: "EUR"}}, nil
+}
+
+type fakeCalendarUsers struct {
+	repositories.UserRepository
+	locale string
+}
+
+func (f fakeCalendarUsers) FindByID(_ context.Context, userID int) (*models.User, error) {
+	return &models.User{ID: userID, Locale: f.locale}, nil
+}
+
+// O feed é buscado pelo aplicativo de calendário: vale o idioma preferido do
+// dono e, sem preferência, o da requisição.
+func TestUserEventsLocale(t *testing.T) {
+	tests := []struct {
+		name       string
+		preferred  string
+		request    i18n.Locale
+		calendar   string
+		trip       string
+		cost       string
+		deadline   string
+		deadlineTo string
+	}{
+		{"preferido do dono", "en", i18n.PtBR, "My trips", "Trip: Lisboa", "Estimated cost: 25.00 EUR",
+			"Voting deadline: Hotel? (Lisboa)", "Voting in group Lisboa closes automatically."},
+		{"sem preferência, idioma da requisição", "", i18n.English, "My trips", "Trip: Lisboa", "Estimated cost: 25.00 EUR",
+			"Voting deadline: Hotel? (Lisboa)", "Voting in group Lisboa closes automatically."},
+		{"preferido vence a requisição", "pt-BR", i18n.English, "Minhas viagens", "Viagem: Lisboa", "Custo estimado: 25.00 EUR",
+			"Prazo da votação: Hotel? (Lisboa)", "Encerramento automático da votação do grupo Lisboa."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service := NewCalendarService(fakeCalendarGroups{}, fakeCalendarItinerary{}, fakeCalendarUsers{locale: tt.preferred})
+			name, events, err := service.UserEvents(i18n.WithLocale(context.Background(), tt.request), 1)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if name != tt.calendar {
+				t.Fatalf("nome do calendário = %q, esperado %q", name, tt.calendar)
+			}
+			if len(events) != 3 {
+				t.Fatalf("eventos = %+v, esperado 3", events)
+			}
+			if events[0].Summary != tt.trip {
+				t.Fatalf("viagem = %q, esperado %q", events[0].Summary, tt.trip)
+			}
+			if events[1].Description != tt.cost {
+				t.Fatalf("custo = %q, esperado %q", events[1].Description, tt.cost)
+			}
+			if events[2].Summary != tt.deadline || events[2].Description != tt.deadlineTo {
+				t.Fatalf("prazo = %q / %q, esperado %q / %q", events[2].Summary, events[2].Description, tt.deadline, tt.deadlineTo)
+			}
+		})
+	}
+}
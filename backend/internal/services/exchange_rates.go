@@ -0,0 +1,159 @@
+package services
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// ExchangeRateService converte valores entre moedas usando a tabela local
+// exchange_rates (sem consulta a serviços externos).
+type ExchangeRateService interface {
+	ImportCSV(ctx context.Context, r io.Reader) (int, error)
+	Convert(ctx context.Context, amount models.Money, from string, to string, on time.Time) (models.Money, error)
+	ConvertExpenses(ctx context.Context, expenses []models.ExpenseDTO, baseCurrency string) error
+}
+
+type exchangeRateService struct {
+	rateRepo repositories.ExchangeRateRepository
+}
+
+// NewExchangeRateService cria uma nova instância de ExchangeRateService.
+func NewExchangeRateService(rateRepo repositories.ExchangeRateRepository) ExchangeRateService {
+	return &exchangeRateService{rateRepo: rateRepo}
+}
+
+// ratePattern aceita só decimais simples que cabem na coluna
+// exchange_rates.rate (decimal(18,8)): sem sinal, expoente, fração ("1/3") ou
+// base ("0x10"), que big.Rat aceitaria mas o banco não.
+var ratePattern = regexp.MustCompile(`^\d{1,10}(\.\d{1,8})?$`)
+
+// ImportCSV lê taxas no formato "date,base,quote,rate" (com cabeçalho opcional),
+// por exemplo "2025-01-10,EUR,BRL,6.2345", e grava todas em uma transação.
+func (s *exchangeRateService) ImportCSV(ctx context.Context, r io.Reader) (int, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 4
+	reader.TrimLeadingSpace = true
+
+	var rates []models.ExchangeRate
+	line := 0
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		line++
+		if err != nil {
+			return 0, fmt.Errorf("linha %d: %w", line, err)
+		}
+
+		if line == 1 && strings.EqualFold(record[0], "date") {
+			continue
+		}
+
+		validOn, err := time.Parse("2006-01-02", record[0])
+		if err != nil {
+			return 0, fmt.Errorf("linha %d: data inválida %q (use YYYY-MM-DD)", line, record[0])
+		}
+
+		base := strings.ToUpper(record[1])
+		quote := strings.ToUpper(record[2])
+		if !models.IsValidCurrency(base) || !models.IsValidCurrency(quote) || base == quote {
+			return 0, fmt.Errorf("linha %d: par de moedas inválido %s/%s", line, record[1], record[2])
+		}
+
+		if !ratePattern.MatchString(record[3]) {
+			return 0, fmt.Errorf("linha %d: taxa inválida %q (use um decimal positivo com até 10 dígitos inteiros e 8 casas, ex.: 6.2345)", line, record[3])
+		}
+		if rate, _ := new(big.Rat).SetString(record[3]); rate.Sign() <= 0 {
+			return 0, fmt.Errorf("linha %d: taxa %q precisa ser maior que zero", line, record[3])
+		}
+
+		rates = append(rates, models.ExchangeRate{
+			BaseCurrency:  base,
+			QuoteCurrency: quote,
+			Rate:          record[3],
+			ValidOn:       validOn,
+		})
+	}
+
+	if len(rates) == 0 {
+		return 0, nil
+	}
+
+	if err := s.rateRepo.UpsertRates(ctx, rates); err != nil {
+		return 0, err
+	}
+	return len(rates), nil
+}
+
+// Convert converte amount de from para to usando a taxa mais próxima da data.
+// O cálculo é feito com frações exatas e arredondado para o centavo mais próximo.
+func (s *exchangeRateService) Convert(ctx context.Context, amount models.Money, from string, to string, on time.Time) (models.Money, error) {
+	if from == to {
+		return amount, nil
+	}
+
+	rate, err := s.rateRepo.FindRate(ctx, from, to, on)
+	if err != nil {
+		return 0, err
+	}
+
+	factor, ok := new(big.Rat).SetString(rate.Rate)
+	if !ok || factor.Sign() <= 0 {
+		return 0, fmt.Errorf("taxa de câmbio inválida no banco: %s/%s = %q", rate.BaseCurrency, rate.QuoteCurrency, rate.Rate)
+	}
+	if rate.BaseCurrency != from {
+		factor.Inv(factor)
+	}
+
+	converted := new(big.Rat).Mul(new(big.Rat).SetInt64(int64(amount)), factor)
+	return models.Money(roundRat(converted)), nil
+}
+
+// ConvertExpenses preenche ConvertedAmount e BaseCurrency de cada despesa.
+// Despesas sem taxa disponível ficam com ConvertedAmount nil.
+func (s *exchangeRateService) ConvertExpenses(ctx context.Context, expenses []models.ExpenseDTO, baseCurrency string) error {
+	for i := range expenses {
+		e := &expenses[i]
+		e.BaseCurrency = baseCurrency
+
+		converted, err := s.Convert(ctx, e.Amount, e.Currency, baseCurrency, e.CreatedAt)
+		if err != nil {
+			if errors.Is(err, repositories.ErrRateNotFound) {
+				e.ConvertedAmount = nil
+				continue
+			}
+			return err
+		}
+		e.ConvertedAmount = &converted
+	}
+	return nil
+}
+
+// roundRat arredonda para o inteiro mais próximo (meio centavo se afasta do zero).
+func roundRat(r *big.Rat) int64 {
+	num := new(big.Int).Set(r.Num())
+	den := r.Denom()
+
+	negative := num.Sign() < 0
+	num.Abs(num)
+
+	// (2*num + den) / (2*den) == floor(num/den + 1/2)
+	num.Mul(num, big.NewInt(2))
+	num.Add(num, den)
+	q := new(big.Int).Quo(num, new(big.Int).Mul(den, big.NewInt(2)))
+
+	if negative {
+		q.Neg(q)
+	}
+	return q.Int64()
+}
@@ -0,0 +1,145 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+)
+
+// fakeRateRepo guarda as taxas por par, ignorando a data.
+type fakeRateRepo struct {
+	rates    map[string]string
+	upserted []models.ExchangeRate
+}
+
+func (f *fakeRateRepo) UpsertRates(_ context.Context, rates []models.ExchangeRate) error {
+	f.upserted = append(f.upserted, rates...)
+	return nil
+}
+
+func (f *fakeRateRepo) FindRate(_ context.Context, from string, to string, _ time.Time) (*models.ExchangeRate, error) {
+	if rate, ok := f.rates[from+to]; ok {
+		return &models.ExchangeRate{BaseCurrency: from, QuoteCurrency: to, Rate: rate}, nil
+	}
+	if rate, ok := f.rates[to+from]; ok {
+		return &models.ExchangeRate{BaseCurrency: to, QuoteCurrency: from, Rate: rate}, nil
+	}
+	return nil, repositories.ErrRateNotFound
+}
+
+func TestConvert(t *testing.T) {
+	service := NewExchangeRateService(&fakeRateRepo{rates: map[string]string{
+		"EURBRL": "6.2345",
+		"USDBRL": "3",
+		"JPYBRL": "0.0351",
+	}})
+
+	tests := []struct {
+		name     string
+		amount   models.Money
+		from, to string
+		want     models.Money
+	}{
+		{name: "mesma moeda", amount: 1234, from: "BRL", to: "BRL", want: 1234},
+		{name: "taxa direta", amount: 10000, from: "EUR", to: "BRL", want: 62345},
+		{name: "arredonda meio centavo para cima", amount: 10, from: "EUR", to: "BRL", want: 62}, // 62.345
+		{name: "arredonda para baixo", amount: 1, from: "EUR", to: "BRL", want: 6},               // 6.2345
+		{name: "taxa invertida", amount: 100, from: "BRL", to: "USD", want: 33},                  // 33.33...
+		{name: "taxa invertida com meio centavo", amount: 5, from: "BRL", to: "USD", want: 2},    // 1.666...
+		{name: "inversa exata", amount: 62345, from: "BRL", to: "EUR", want: 10000},              // 62345/6.2345
+		{name: "valor negativo é simétrico", amount: -10, from: "EUR", to: "BRL", want: -62},     // -62.345
+		{name: "moeda sem centavos", amount: 1000000, from: "JPY", to: "BRL", want: 35100},       // 10000 * 0.0351
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := service.Convert(context.Background(), tt.amount, tt.from, tt.to, time.Now())
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if got != tt.want {
+				t.Fatalf("Convert(%s) = %s, esperado %s", tt.amount, got, tt.want)
+			}
+		})
+	}
+
+	if _, err := service.Convert(context.Background(), 100, "GBP", "BRL", time.Now()); !errors.Is(err, repositories.ErrRateNotFound) {
+		t.Fatalf("erro = %v, esperado ErrRateNotFound", err)
+	}
+}
+
+func TestRoundRat(t *testing.T) {
+	tests := []struct {
+		num, den int64
+		want     int64
+	}{
+		{5, 2, 3},
+		{-5, 2, -3},
+		{7, 3, 2},
+		{-7, 3, -2},
+		{8, 3, 3},
+		{1, 2, 1},
+		{1, 3, 0},
+		{0, 1, 0},
+		{10, 1, 10},
+	}
+	for _, tt := range tests {
+		if got := roundRat(big.NewRat(tt.num, tt.den)); got != tt.want {
+			t.Errorf("roundRat(%d/%d) = %d, esperado %d", tt.num, tt.den, got, tt.want)
+		}
+	}
+}
+
+func TestImportCSV(t *testing.T) {
+	repo := &fakeRateRepo{}
+	service := NewExchangeRateService(repo)
+
+	n, err := service.ImportCSV(context.Background(), strings.NewReader("date,base,quote,rate\n2025-01-10,eur,BRL,6.2345\n2025-01-10, USD, BRL, 5.1\n"))
+	if err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	if n != 2 || len(repo.upserted) != 2 {
+		t.Fatalf("importadas %d (%d gravadas), esperado 2", n, len(repo.upserted))
+	}
+	if got := repo.upserted[0]; got.BaseCurrency != "EUR" || got.QuoteCurrency != "BRL" || got.Rate != "6.2345" {
+		t.Fatalf("primeira taxa = %+v", got)
+	}
+
+	invalid := []struct {
+		input string
+		line  int
+	}{
+		{"2025-13-01,EUR,BRL,6\n", 1},
+		{"2025-01-10,EUR,EUR,1\n", 1},
+		{"2025-01-10,EURO,BRL,6\n", 1},
+		{"2025-01-10,EUR,BRL,0\n", 1},
+		{"2025-01-10,EUR,BRL,0.00\n", 1},
+		{"2025-01-10,EUR,BRL,-1\n", 1},
+		{"2025-01-10,EUR,BRL,+6.2\n", 1},
+		{"2025-01-10,EUR,BRL,abc\n", 1},
+		{"2025-01-10,EUR,BRL,1/3\n", 1},
+		{"2025-01-10,EUR,BRL,6e2\n", 1},
+		{"2025-01-10,EUR,BRL,0x10\n", 1},
+		{"2025-01-10,EUR,BRL,6.\n", 1},
+		{"2025-01-10,EUR,BRL,12345678901\n", 1},
+		{"2025-01-10,EUR,BRL,6.123456789\n", 1},
+		{"2025-01-10,EUR,BRL\n", 1},
+		{"date,base,quote,rate\n2025-01-10,EUR,BRL,6.2\n2025-01-11,EUR,BRL,6,2\n", 3},
+	}
+	for _, tt := range invalid {
+		_, err := service.ImportCSV(context.Background(), strings.NewReader(tt.input))
+		if err == nil {
+			t.Errorf("ImportCSV(%q): esperado erro", tt.input)
+			continue
+		}
+		if want := fmt.Sprintf("linha %d", tt.line); !strings.Contains(err.Error(), want) {
+			t.Errorf("ImportCSV(%q) = %v, esperado erro na %s", tt.input, err, want)
+		}
+	}
+}
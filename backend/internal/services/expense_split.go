@@ -0,0 +1,196 @@
+package services
+
+import (
+	"errors"
+	"math/big"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"sort"
+	"strings"
+)
+
+// ErrInvalidSplit indica que a divisão informada não fecha com o valor da despesa.
+var ErrInvalidSplit = errors.New("divisão da despesa inválida")
+
+// SplitExpense calcula o valor de cada participante conforme o modo de divisão.
+// Todas as contas são feitas em centavos e a soma das partes é sempre igual a amount.
+func SplitExpense(amount models.Money, mode string, participantIDs []int, splits []models.ExpenseSplitInput) ([]models.ExpenseShare, error) {
+	total := int64(amount)
+	if total <= 0 {
+		return nil, i18n.Errorf(ErrInvalidSplit, "split.amount_positive")
+	}
+
+	if mode == "" {
+		mode = models.SplitModeEqual
+	}
+
+	if mode == models.SplitModeEqual {
+		if len(participantIDs) == 0 {
+			for _, s := range splits {
+				participantIDs = append(participantIDs, s.UserID)
+			}
+		}
+		ids, err := uniqueIDs(participantIDs)
+		if err != nil {
+			return nil, err
+		}
+		return buildShares(ids, splitEqually(total, len(ids))), nil
+	}
+
+	ids := make([]int, len(splits))
+	for i, s := range splits {
+		ids[i] = s.UserID
+	}
+	if _, err := uniqueIDs(ids); err != nil {
+		return nil, err
+	}
+
+	var parts []int64
+	switch mode {
+	case models.SplitModeExact:
+		parts = make([]int64, len(splits))
+		var sum int64
+		for i, s := range splits {
+			value, err := models.ParseMoney(s.Value.String())
+			if err != nil {
+				return nil, i18n.Errorf(ErrInvalidSplit, "split.invalid_value", s.Value.String())
+			}
+			parts[i] = int64(value)
+			if parts[i] < 0 {
+				return nil, i18n.Errorf(ErrInvalidSplit, "split.negative_value")
+			}
+			sum += parts[i]
+		}
+		if sum != total {
+			return nil, i18n.Errorf(ErrInvalidSplit, "split.sum_mismatch", models.Money(sum), models.Money(total))
+		}
+
+	case models.SplitModePercentage:
+		weights, sum, err := splitWeights(splits)
+		if err != nil {
+			return nil, err
+		}
+		if sum.Cmp(big.NewRat(100, 1)) != 0 {
+			percent, _ := sum.Float64()
+			return nil, i18n.Errorf(ErrInvalidSplit, "split.percentages_sum", percent)
+		}
+		parts = splitProportionally(total, weights)
+
+	case models.SplitModeShares:
+		weights, sum, err := splitWeights(splits)
+		if err != nil {
+			return nil, err
+		}
+		if sum.Sign() <= 0 {
+			return nil, i18n.Errorf(ErrInvalidSplit, "split.shares_positive")
+		}
+		parts = splitProportionally(total, weights)
+
+	default:
+		return nil, i18n.Errorf(ErrInvalidSplit, "split.unknown_mode", mode)
+	}
+
+	return buildShares(ids, parts), nil
+}
+
+// splitWeights lê os percentuais ou cotas informados como frações exatas (sem
+// float64, para que 33.33+33.33+33.34 some exatamente 100), rejeitando valores
+// negativos e a notação com expoente.
+func splitWeights(splits []models.ExpenseSplitInput) ([]*big.Rat, *big.Rat, error) {
+	weights := make([]*big.Rat, len(splits))
+	sum := new(big.Rat)
+	for i, s := range splits {
+		w, ok := parseWeight(s.Value.String())
+		if !ok {
+			return nil, nil, i18n.Errorf(ErrInvalidSplit, "split.invalid_participant_value", s.UserID)
+		}
+		weights[i] = w
+		sum.Add(sum, w)
+	}
+	return weights, sum, nil
+}
+
+// maxWeightLength limita o tamanho do número aceito como percentual ou cota.
+const maxWeightLength = 32
+
+// parseWeight aceita um decimal não negativo ("50", "33.33").
+func parseWeight(text string) (*big.Rat, bool) {
+	if text == "" || len(text) > maxWeightLength || strings.ContainsAny(text, "eE/") {
+		return nil, false
+	}
+	w, ok := new(big.Rat).SetString(text)
+	if !ok || w.Sign() < 0 {
+		return nil, false
+	}
+	return w, true
+}
+
+// uniqueIDs valida que a lista de participantes não é vazia nem tem repetições.
+func uniqueIDs(ids []int) ([]int, error) {
+	if len(ids) == 0 {
+		return nil, i18n.Errorf(ErrInvalidSplit, "split.participants_required")
+	}
+	seen := make(map[int]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, i18n.Errorf(ErrInvalidSplit, "split.duplicate_participant", id)
+		}
+		seen[id] = true
+	}
+	return ids, nil
+}
+
+// splitEqually divide total centavos em n partes; os primeiros recebem o centavo que sobra.
+func splitEqually(total int64, n int) []int64 {
+	parts := make([]int64, n)
+	share, remainder := total/int64(n), total%int64(n)
+	for i := range parts {
+		parts[i] = share
+		if int64(i) < remainder {
+			parts[i]++
+		}
+	}
+	return parts
+}
+
+// splitProportionally distribui total centavos pelo método dos maiores restos,
+// garantindo que a soma das partes seja exatamente total. As contas são feitas
+// com frações exatas; empates no resto favorecem quem vem primeiro.
+func splitProportionally(total int64, weights []*big.Rat) []int64 {
+	sum := new(big.Rat)
+	for _, w := range weights {
+		sum.Add(sum, w)
+	}
+
+	parts := make([]int64, len(weights))
+	remainders := make([]*big.Rat, len(weights))
+	var allocated int64
+	for i, w := range weights {
+		exact := new(big.Rat).Mul(big.NewRat(total, 1), w)
+		exact.Quo(exact, sum)
+		floor := new(big.Int).Quo(exact.Num(), exact.Denom())
+		parts[i] = floor.Int64()
+		remainders[i] = exact.Sub(exact, new(big.Rat).SetInt(floor))
+		allocated += parts[i]
+	}
+
+	order := make([]int, len(weights))
+	for i := range order {
+		order[i] = i
+	}
+	sort.SliceStable(order, func(a, b int) bool { return remainders[order[a]].Cmp(remainders[order[b]]) > 0 })
+
+	for i := 0; allocated < total; i++ {
+		parts[order[i%len(order)]]++
+		allocated++
+	}
+	return parts
+}
+
+func buildShares(ids []int, parts []int64) []models.ExpenseShare {
+	shares := make([]models.ExpenseShare, len(ids))
+	for i, id := range ids {
+		shares[i] = models.ExpenseShare{UserID: id, Amount: models.Money(parts[i])}
+	}
+	return shares
+}
@@ -0,0 +1,199 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"project_lab/internal/models"
+)
+
+func splitInputs(values map[int]string, order ...int) []models.ExpenseSplitInput {
+	splits := make([]models.ExpenseSplitInput, len(order))
+	for i, id := range order {
+		splits[i] = models.ExpenseSplitInput{UserID: id, Value: json.Number(values[id])}
+	}
+	return splits
+}
+
+func TestSplitExpense(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       models.Money
+		mode         string
+		participants []int
+		splits       []models.ExpenseSplitInput
+		want         map[int]models.Money
+	}{
+		{
+			name:         "igual com resto para os primeiros",
+			amount:       1000,
+			mode:         models.SplitModeEqual,
+			participants: []int{1, 2, 3},
+			want:         map[int]models.Money{1: 334, 2: 333, 3: 333},
+		},
+		{
+			name:         "modo vazio é igual",
+			amount:       101,
+			participants: []int{7, 8},
+			want:         map[int]models.Money{7: 51, 8: 50},
+		},
+		{
+			name:   "igual usa os participantes de splits",
+			amount: 300,
+			mode:   models.SplitModeEqual,
+			splits: splitInputs(map[int]string{1: "0", 2: "0"}, 1, 2),
+			want:   map[int]models.Money{1: 150, 2: 150},
+		},
+		{
+			name:   "um centavo para três",
+			amount: 1,
+			mode:   models.SplitModeEqual,
+			splits: splitInputs(map[int]string{1: "0", 2: "0", 3: "0"}, 1, 2, 3),
+			want:   map[int]models.Money{1: 1, 2: 0, 3: 0},
+		},
+		{
+			name:   "exato",
+			amount: 10000,
+			mode:   models.SplitModeExact,
+			splits: splitInputs(map[int]string{1: "60.5", 2: "39.50"}, 1, 2),
+			want:   map[int]models.Money{1: 6050, 2: 3950},
+		},
+		{
+			name:   "percentuais que só somam 100 sem float",
+			amount: 10000,
+			mode:   models.SplitModePercentage,
+			splits: splitInputs(map[int]string{1: "33.33", 2: "33.33", 3: "33.34"}, 1, 2, 3),
+			want:   map[int]models.Money{1: 3333, 2: 3333, 3: 3334},
+		},
+		{
+			name:   "percentuais com maiores restos",
+			amount: 100,
+			mode:   models.SplitModePercentage,
+			splits: splitInputs(map[int]string{1: "10.1", 2: "20.1", 3: "69.8"}, 1, 2, 3),
+			// 10.1, 20.1 e 69.8 centavos: o centavo que sobra vai para o maior resto (69.8).
+			want: map[int]models.Money{1: 10, 2: 20, 3: 70},
+		},
+		{
+			name:   "cotas",
+			amount: 1000,
+			mode:   models.SplitModeShares,
+			splits: splitInputs(map[int]string{1: "2", 2: "1"}, 1, 2),
+			want:   map[int]models.Money{1: 667, 2: 333},
+		},
+		{
+			name:   "cotas com zero",
+			amount: 999,
+			mode:   models.SplitModeShares,
+			splits: splitInputs(map[int]string{1: "1", 2: "0", 3: "2"}, 1, 2, 3),
+			want:   map[int]models.Money{1: 333, 2: 0, 3: 666},
+		},
+		{
+			name:   "cotas iguais com empate no resto",
+			amount: 100,
+			mode:   models.SplitModeShares,
+			splits: splitInputs(map[int]string{1: "1", 2: "1", 3: "1"}, 1, 2, 3),
+			want:   map[int]models.Money{1: 34, 2: 33, 3: 33},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			shares, err := SplitExpense(tt.amount, tt.mode, tt.participants, tt.splits)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			got := make(map[int]models.Money, len(shares))
+			var sum models.Money
+			for _, s := range shares {
+				got[s.UserID] = s.Amount
+				sum += s.Amount
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("partes = %v, esperado %v", got, tt.want)
+			}
+			if sum != tt.amount {
+				t.Fatalf("soma = %v, esperado %v", sum, tt.amount)
+			}
+		})
+	}
+}
+
+func TestSplitExpenseRejects(t *testing.T) {
+	tests := []struct {
+		name         string
+		amount       models.Money
+		mode         string
+		participants []int
+		splits       []models.ExpenseSplitInput
+	}{
+		{name: "valor zero", amount: 0, participants: []int{1}},
+		{name: "valor negativo", amount: -100, participants: []int{1}},
+		{name: "sem participantes", amount: 100, mode: models.SplitModeEqual},
+		{name: "participante repetido", amount: 100, participants: []int{1, 1}},
+		{name: "exato com soma diferente", amount: 100, mode: models.SplitModeExact,
+			splits: splitInputs(map[int]string{1: "0.5", 2: "0.49"}, 1, 2)},
+		{name: "exato com três casas", amount: 100, mode: models.SplitModeExact,
+			splits: splitInputs(map[int]string{1: "0.505", 2: "0.495"}, 1, 2)},
+		{name: "exato negativo", amount: 100, mode: models.SplitModeExact,
+			splits: splitInputs(map[int]string{1: "2", 2: "-1"}, 1, 2)},
+		{name: "exato repetido", amount: 100, mode: models.SplitModeExact,
+			splits: splitInputs(map[int]string{1: "1"}, 1, 1)},
+		{name: "percentuais quase 100", amount: 100, mode: models.SplitModePercentage,
+			splits: splitInputs(map[int]string{1: "33.33", 2: "33.33", 3: "33.33"}, 1, 2, 3)},
+		{name: "percentuais 100.0000001", amount: 100, mode: models.SplitModePercentage,
+			splits: splitInputs(map[int]string{1: "50", 2: "50.0000001"}, 1, 2)},
+		{name: "percentual negativo", amount: 100, mode: models.SplitModePercentage,
+			splits: splitInputs(map[int]string{1: "110", 2: "-10"}, 1, 2)},
+		{name: "percentual com expoente", amount: 100, mode: models.SplitModePercentage,
+			splits: splitInputs(map[int]string{1: "1e2", 2: "0"}, 1, 2)},
+		{name: "cotas somando zero", amount: 100, mode: models.SplitModeShares,
+			splits: splitInputs(map[int]string{1: "0", 2: "0"}, 1, 2)},
+		{name: "modo desconhecido", amount: 100, mode: "lottery",
+			splits: splitInputs(map[int]string{1: "1"}, 1)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := SplitExpense(tt.amount, tt.mode, tt.participants, tt.splits)
+			if !errors.Is(err, ErrInvalidSplit) {
+				t.Fatalf("erro = %v, esperado ErrInvalidSplit", err)
+			}
+		})
+	}
+}
+
+func TestSplitProportionally(t *testing.T) {
+	rats := func(values ...int64) []*big.Rat {
+		weights := make([]*big.Rat, len(values))
+		for i, v := range values {
+			weights[i] = big.NewRat(v, 1)
+		}
+		return weights
+	}
+
+	tests := []struct {
+		name    string
+		total   int64
+		weights []*big.Rat
+		want    []int64
+	}{
+		{name: "exato", total: 900, weights: rats(1, 2), want: []int64{300, 600}},
+		{name: "resto para o maior resto", total: 10, weights: rats(1, 1, 1), want: []int64{4, 3, 3}},
+		{name: "dois centavos de resto", total: 11, weights: rats(3, 3, 3), want: []int64{4, 4, 3}},
+		{name: "total zero", total: 0, weights: rats(1, 2), want: []int64{0, 0}},
+		{name: "peso zero nunca recebe resto", total: 5, weights: rats(0, 1, 1), want: []int64{0, 3, 2}},
+		{name: "reescala de moeda", total: 5417, weights: rats(3334, 3333, 3333), want: []int64{1806, 1806, 1805}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitProportionally(tt.total, tt.weights)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("partes = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package services
+
+import (
+	"errors"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"slices"
+)
+
+var (
+	// ErrOwnershipTransferRequired indica que o organizador tentou sair, ou ser
+	// removido, sem transferir o grupo antes.
+	ErrOwnershipTransferRequired = errors.New("o organizador precisa transferir o grupo")
+	// ErrMemberRemovalForbidden indica que o papel de quem remove não permite
+	// remover aquele membro.
+	ErrMemberRemovalForbidden = errors.New("sem permissão para remover o membro")
+)
+
+// Permission é uma ação sobre um grupo que depende do papel do membro.
+type Permission string
+
+const (
+	// PermView: ver o grupo e tudo o que pertence a ele.
+	PermView Permission = "view"
+	// PermContribute: sugerir destinos, criar votações, despesas e itens do
+	// roteiro, votar e alterar o que o próprio membro criou.
+	PermContribute Permission = "contribute"
+	// PermManageContent: alterar, apagar ou encerrar o que outros membros criaram.
+	PermManageContent Permission = "manage_content"
+	// PermEditGroup: alterar nome, descrição, datas e moeda base do grupo.
+	PermEditGroup Permission = "edit_group"
+	// PermInviteMembers: criar, listar e revogar convites.
+	PermInviteMembers Permission = "invite_members"
+	// PermRemoveMembers: remover participantes e leitores do grupo.
+	PermRemoveMembers Permission = "remove_members"
+	// PermChangeRoles: promover e rebaixar membros.
+	PermChangeRoles Permission = "change_roles"
+	// PermTransferOwnership: passar o grupo para outro membro.
+	PermTransferOwnership Permission = "transfer_ownership"
+	// PermDeleteGroup: apagar o grupo.
+	PermDeleteGroup Permission = "delete_group"
+)
+
+// rolePermissions é a matriz de permissões: cada papel tem as do papel abaixo
+// dele e mais algumas.
+var rolePermissions = map[string][]Permission{
+	models.RoleViewer:      {PermView},
+	models.RoleParticipant: {PermView, PermContribute},
+	models.RoleCoOrganizer: {PermView, PermContribute, PermManageContent, PermEditGroup, PermInviteMembers, PermRemoveMembers},
+	models.RoleOrganizer: {PermView, PermContribute, PermManageContent, PermEditGroup, PermInviteMembers, PermRemoveMembers,
+		PermChangeRoles, PermTransferOwnership, PermDeleteGroup},
+}
+
+// RoleCan indica se o papel tem a permissão. Papéis desconhecidos não têm nenhuma.
+func RoleCan(role string, permission Permission) bool {
+	return slices.Contains(rolePermissions[role], permission)
+}
+
+// ValidateAssignableRole confere o papel pedido em uma promoção ou rebaixamento.
+// O papel de organizador não é atribuído assim: ele muda com a transferência do grupo.
+func ValidateAssignableRole(role string) *i18n.Message {
+	switch role {
+	case models.RoleCoOrganizer, models.RoleParticipant, models.RoleViewer:
+		return nil
+	case "":
+		return message("validation.role_required")
+	case models.RoleOrganizer:
+		return message("validation.role_organizer_transfer")
+	default:
+		return message("validation.role_invalid", "co_organizer, participant, viewer")
+	}
+}
+
+// CheckMemberRemoval aplica as regras de saída do grupo aos papéis de quem
+// remove e de quem sai (self quando são a mesma pessoa). Ninguém remove o
+// organizador, nem ele sai, sem transferir o grupo antes; remover outro membro
+// exige PermRemoveMembers, e remover um co-organizador, PermChangeRoles.
+func CheckMemberRemoval(actorRole string, memberRole string, self bool) error {
+	switch {
+	case memberRole == models.RoleOrganizer && self:
+		return i18n.Errorf(ErrOwnershipTransferRequired, "member.organizer_must_transfer")
+	case memberRole == models.RoleOrganizer:
+		return i18n.Errorf(ErrOwnershipTransferRequired, "member.organizer_cannot_be_removed")
+	case self:
+		return nil
+	case !RoleCan(actorRole, PermRemoveMembers):
+		return i18n.Errorf(ErrMemberRemovalForbidden, "member.organizer_only_remove")
+	case memberRole == models.RoleCoOrganizer && !RoleCan(actorRole, PermChangeRoles):
+		return i18n.Errorf(ErrMemberRemovalForbidden, "member.co_organizer_remove_forbidden")
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"errors"
+	"testing"
+
+	"project_lab/internal/models"
+)
+
+// Cada papel tem as permissões do papel abaixo dele e mais algumas:
+// viewer < participant < co_organizer < organizer.
+func TestRoleCan(t *testing.T) {
+	roles := []string{models.RoleViewer, models.RoleParticipant, models.RoleCoOrganizer, models.RoleOrganizer}
+	// minRole é o índice, em roles, do papel mais baixo que tem a permissão.
+	tests := []struct {
+		permission Permission
+		minRole    int
+	}{
+		{PermView, 0},
+		{PermContribute, 1},
+		{PermManageContent, 2},
+		{PermEditGroup, 2},
+		{PermInviteMembers, 2},
+		{PermRemoveMembers, 2},
+		{PermChangeRoles, 3},
+		{PermTransferOwnership, 3},
+		{PermDeleteGroup, 3},
+	}
+
+	for _, tt := range tests {
+		for i, role := range roles {
+			if got, want := RoleCan(role, tt.permission), i >= tt.minRole; got != want {
+				t.Errorf("RoleCan(%s, %s) = %v, esperado %v", role, tt.permission, got, want)
+			}
+		}
+		for _, role := range []string{"", "admin", "Organizer"} {
+			if RoleCan(role, tt.permission) {
+				t.Errorf("RoleCan(%q, %s) = true; papéis desconhecidos não têm permissões", role, tt.permission)
+			}
+		}
+	}
+}
+
+func TestValidateAssignableRole(t *testing.T) {
+	tests := []struct {
+		role  string
+		valid bool
+	}{
+		{models.RoleCoOrganizer, true},
+		{models.RoleParticipant, true},
+		{models.RoleViewer, true},
+		{"", false},
+		{models.RoleOrganizer, false},
+		{"admin", false},
+	}
+
+	for _, tt := range tests {
+		if msg := ValidateAssignableRole(tt.role); (msg == nil) != tt.valid {
+			t.Errorf("ValidateAssignableRole(%q) = %v, esperado válido = %v", tt.role, msg, tt.valid)
+		}
+	}
+}
+
+func TestCheckMemberRemoval(t *testing.T) {
+	tests := []struct {
+		name   string
+		actor  string
+		member string
+		self   bool
+		want   error
+	}{
+		{"organizador sai", models.RoleOrganizer, models.RoleOrganizer, true, ErrOwnershipTransferRequired},
+		{"co-organizador remove o organizador", models.RoleCoOrganizer, models.RoleOrganizer, false, ErrOwnershipTransferRequired},
+		{"co-organizador sai", models.RoleCoOrganizer, models.RoleCoOrganizer, true, nil},
+		{"leitor sai", models.RoleViewer, models.RoleViewer, true, nil},
+		{"organizador remove co-organizador", models.RoleOrganizer, models.RoleCoOrganizer, false, nil},
+		{"co-organizador remove co-organizador", models.RoleCoOrganizer, models.RoleCoOrganizer, false, ErrMemberRemovalForbidden},
+		{"co-organizador remove participante", models.RoleCoOrganizer, models.RoleParticipant, false, nil},
+		{"co-organizador remove leitor", models.RoleCoOrganizer, models.RoleViewer, false, nil},
+		{"participante remove leitor", models.RoleParticipant, models.RoleViewer, false, ErrMemberRemovalForbidden},
+		{"leitor remove participante", models.RoleViewer, models.RoleParticipant, false, ErrMemberRemovalForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := CheckMemberRemoval(tt.actor, tt.member, tt.self); !errors.Is(err, tt.want) {
+				t.Fatalf("erro = %v, esperado %v", err, tt.want)
+			}
+		})
+	}
+}
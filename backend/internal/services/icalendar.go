@@ -0,0 +1,118 @@
+package services
+
+import (
+	"bufio"
+	"io"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// CalendarEvent é um VEVENT do iCalendar (RFC 5545).
+// AllDay usa apenas as datas de Start/End (End exclusivo). Floating gera
+// horários sem fuso ("09:00 no local da viagem"); caso contrário os horários
+// são escritos em UTC. End zero omite DTEND (evento pontual, como um prazo).
+type CalendarEvent struct {
+	UID         string
+	Summary     string
+	Description string
+	Location    string
+	Start       time.Time
+	End         time.Time
+	AllDay      bool
+	Floating    bool
+}
+
+const (
+	icalDateLayout     = "20060102"
+	icalFloatingLayout = "20060102T150405"
+	icalUTCLayout      = "20060102T150405Z"
+	// icalMaxLineOctets é o limite de octetos por linha antes da dobra (RFC 5545, 3.1).
+	icalMaxLineOctets = 75
+)
+
+// WriteICalendar escreve um VCALENDAR com os eventos informados, com quebras
+// CRLF, dobra de linhas longas e escape de texto conforme a RFC 5545.
+func WriteICalendar(w io.Writer, name string, events []CalendarEvent, now time.Time) error {
+	bw := bufio.NewWriter(w)
+	stamp := now.UTC().Format(icalUTCLayout)
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:-//project_lab//Viagens em Grupo//PT",
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+		"NAME:" + escapeICalText(name),
+		"X-WR-CALNAME:" + escapeICalText(name),
+		"REFRESH-INTERVAL;VALUE=DURATION:PT1H",
+		"X-PUBLISHED-TTL:PT1H",
+	}
+	for _, e := range events {
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+e.UID,
+			"DTSTAMP:"+stamp,
+		)
+		lines = append(lines, formatICalTime("DTSTART", e.Start, e.AllDay, e.Floating))
+		if !e.End.IsZero() {
+			lines = append(lines, formatICalTime("DTEND", e.End, e.AllDay, e.Floating))
+		}
+		lines = append(lines, "SUMMARY:"+escapeICalText(e.Summary))
+		if e.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeICalText(e.Description))
+		}
+		if e.Location != "" {
+			lines = append(lines, "LOCATION:"+escapeICalText(e.Location))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(foldICalLine(line)); err != nil {
+			return err
+		}
+	}
+	return bw.Flush()
+}
+
+func formatICalTime(property string, t time.Time, allDay bool, floating bool) string {
+	switch {
+	case allDay:
+		return property + ";VALUE=DATE:" + t.Format(icalDateLayout)
+	case floating:
+		return property + ":" + t.Format(icalFloatingLayout)
+	default:
+		return property + ":" + t.UTC().Format(icalUTCLayout)
+	}
+}
+
+// escapeICalText aplica o escape de valores TEXT (RFC 5545, 3.3.11).
+func escapeICalText(s string) string {
+	s = strings.ReplaceAll(s, "\r\n", "\n")
+	s = strings.ReplaceAll(s, "\r", "\n")
+	replacer := strings.NewReplacer(`\`, `\\`, ";", `\;`, ",", `\,`, "\n", `\n`)
+	return replacer.Replace(s)
+}
+
+// foldICalLine quebra a linha em partes de no máximo 75 octetos, sem cortar
+// caracteres UTF-8; as continuações começam com um espaço. Inclui o CRLF final.
+func foldICalLine(line string) string {
+	var b strings.Builder
+	limit := icalMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// O espaço inicial da continuação conta no limite.
+		limit = icalMaxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+	return b.String()
+}
@@ -0,0 +1,133 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestEscapeICalText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"Lisboa", "Lisboa"},
+		{"Porto, Portugal", `Porto\, Portugal`},
+		{"ida; volta", `ida\; volta`},
+		{`C:\viagens`, `C:\\viagens`},
+		{"linha 1\nlinha 2", `linha 1\nlinha 2`},
+		{"linha 1\r\nlinha 2\rlinha 3", `linha 1\nlinha 2\nlinha 3`},
+		{`já escapado: \,`, `já escapado: \\\,`},
+	}
+
+	for _, tt := range tests {
+		if got := escapeICalText(tt.in); got != tt.want {
+			t.Errorf("escapeICalText(%q) = %q, esperado %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestFoldICalLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"curta", "SUMMARY:Viagem"},
+		{"exatamente 75 octetos", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"76 octetos", "SUMMARY:" + strings.Repeat("a", 68)},
+		{"várias dobras", "DESCRIPTION:" + strings.Repeat("0123456789", 20)},
+		// "ç" e "ã" ocupam 2 octetos e "€" 3: o limite cai no meio deles.
+		{"2 octetos no limite", "SUMMARY:" + strings.Repeat("a", 66) + strings.Repeat("ç", 10)},
+		{"3 octetos no limite", "SUMMARY:" + strings.Repeat("a", 65) + strings.Repeat("€", 30)},
+		{"só multibyte", "DESCRIPTION:" + strings.Repeat("ãé€😀", 40)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := foldICalLine(tt.line)
+			if !strings.HasSuffix(folded, "\r\n") {
+				t.Fatalf("linha dobrada sem CRLF final: %q", folded)
+			}
+
+			parts := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			var unfolded strings.Builder
+			for i, part := range parts {
+				if len(part) > icalMaxLineOctets {
+					t.Fatalf("parte %d com %d octetos (máximo %d): %q", i, len(part), icalMaxLineOctets, part)
+				}
+				if i > 0 {
+					if !strings.HasPrefix(part, " ") {
+						t.Fatalf("continuação %d sem espaço inicial: %q", i, part)
+					}
+					part = part[1:]
+				}
+				if !utf8.ValidString(part) {
+					t.Fatalf("parte %d corta um caractere UTF-8: %q", i, part)
+				}
+				unfolded.WriteString(part)
+			}
+			if unfolded.String() != tt.line {
+				t.Fatalf("desdobrada = %q, esperado %q", unfolded.String(), tt.line)
+			}
+			if want := len(tt.line) > icalMaxLineOctets; (len(parts) > 1) != want {
+				t.Fatalf("%d partes para %d octetos", len(parts), len(tt.line))
+			}
+		})
+	}
+}
+
+func TestWriteICalendar(t *testing.T) {
+	var b strings.Builder
+	events := []CalendarEvent{
+		{
+			UID:     "group-1@project-lab",
+			Summary: "Viagem: Lisboa, Porto",
+			Start:   time.Date(2025, 5, 1, 0, 0, 0, 0, time.UTC),
+			End:     time.Date(2025, 5, 6, 0, 0, 0, 0, time.UTC),
+			AllDay:  true,
+		},
+		{
+			UID:         "itinerary-2@project-lab",
+			Summary:     "Museu",
+			Description: "Levar ingresso;\nchegar cedo",
+			Location:    "Belém",
+			Start:       time.Date(2025, 5, 2, 9, 0, 0, 0, time.UTC),
+			End:         time.Date(2025, 5, 2, 11, 0, 0, 0, time.UTC),
+			Floating:    true,
+		},
+		{
+			UID:     "voting-3-deadline@project-lab",
+			Summary: "Prazo",
+			Start:   time.Date(2025, 4, 20, 12, 0, 0, 0, time.FixedZone("BRT", -3*3600)),
+		},
+	}
+	if err := WriteICalendar(&b, "Minhas viagens", events, time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)); err != nil {
+		t.Fatalf("erro inesperado: %v", err)
+	}
+	out := b.String()
+
+	if strings.Contains(strings.ReplaceAll(out, "\r\n", ""), "\n") {
+		t.Fatal("quebra de linha sem CR")
+	}
+	for _, want := range []string{
+		"BEGIN:VCALENDAR\r\n",
+		"X-WR-CALNAME:Minhas viagens\r\n",
+		"DTSTAMP:20250101T000000Z\r\n",
+		"DTSTART;VALUE=DATE:20250501\r\nDTEND;VALUE=DATE:20250506\r\n",
+		`SUMMARY:Viagem: Lisboa\, Porto` + "\r\n",
+		"DTSTART:20250502T090000\r\nDTEND:20250502T110000\r\n",
+		`DESCRIPTION:Levar ingresso\;\nchegar cedo` + "\r\n",
+		"LOCATION:Belém\r\n",
+		// Prazo: horário em UTC e sem DTEND.
+		"DTSTART:20250420T150000Z\r\nSUMMARY:Prazo\r\n",
+		"END:VCALENDAR\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("calendário sem %q:\n%s", want, out)
+		}
+	}
+	if got := strings.Count(out, "BEGIN:VEVENT"); got != len(events) {
+		t.Errorf("%d VEVENTs, esperado %d", got, len(events))
+	}
+}
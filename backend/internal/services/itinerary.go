@@ -0,0 +1,102 @@
+package services
+
+import (
+	"errors"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"time"
+)
+
+// ErrInvalidItineraryItem indica que o item não respeita as regras do roteiro.
+var ErrInvalidItineraryItem = errors.New("item do roteiro inválido")
+
+// ValidateItineraryItem verifica que o item tem atividade, que termina depois de
+// começar e que cabe inteiramente entre startDate e endDate (datas da viagem,
+// com o último dia incluído).
+func ValidateItineraryItem(item *models.ItineraryItem, startDate time.Time, endDate time.Time) error {
+	if item.Activity == "" {
+		return i18n.Errorf(ErrInvalidItineraryItem, "itinerary.activity_required")
+	}
+	if !item.EndsAt.After(item.StartsAt.Time) {
+		return i18n.Errorf(ErrInvalidItineraryItem, "itinerary.end_after_start")
+	}
+
+	tripStart := models.NewLocalDateTime(startDate).Date()
+	tripEnd := models.NewLocalDateTime(endDate).Date().AddDate(0, 0, 1)
+	if item.StartsAt.Before(tripStart) || item.EndsAt.After(tripEnd) {
+		return i18n.Errorf(ErrInvalidItineraryItem, "itinerary.outside_trip",
+			tripStart.Format("2006-01-02"), endDate.Format("2006-01-02"))
+	}
+	return nil
+}
+
+// itemsOverlap diz se os horários se sobrepõem; encostar (um termina quando o
+// outro começa) não é sobreposição.
+func itemsOverlap(a, b *models.ItineraryItem) bool {
+	return a.StartsAt.Before(b.EndsAt.Time) && b.StartsAt.Before(a.EndsAt.Time)
+}
+
+// FindOverlaps retorna os itens de existing que se sobrepõem a item,
+// ignorando o próprio item (na alteração).
+func FindOverlaps(item *models.ItineraryItem, existing []models.ItineraryItem) []models.ItineraryItem {
+	var conflicts []models.ItineraryItem
+	for i := range existing {
+		if existing[i].ID == item.ID {
+			continue
+		}
+		if itemsOverlap(item, &existing[i]) {
+			conflicts = append(conflicts, existing[i])
+		}
+	}
+	return conflicts
+}
+
+// MarkOverlaps preenche OverlapsWith de cada item. Espera os itens em ordem de início.
+func MarkOverlaps(items []models.ItineraryItem) {
+	for i := range items {
+		items[i].OverlapsWith = []int{}
+	}
+	for i := range items {
+		for j := i + 1; j < len(items); j++ {
+			// Ordenados por início: nenhum item seguinte começa antes deste terminar.
+			if !items[j].StartsAt.Before(items[i].EndsAt.Time) {
+				break
+			}
+			if itemsOverlap(&items[i], &items[j]) {
+				items[i].OverlapsWith = append(items[i].OverlapsWith, items[j].ID)
+				items[j].OverlapsWith = append(items[j].OverlapsWith, items[i].ID)
+			}
+		}
+	}
+}
+
+// GroupItineraryByDay monta um dia para cada data da viagem (mesmo sem itens),
+// com os itens em ordem de início. Itens que passam da meia-noite aparecem em
+// cada dia que ocupam.
+func GroupItineraryByDay(items []models.ItineraryItem, startDate time.Time, endDate time.Time) []models.ItineraryDay {
+	first := models.NewLocalDateTime(startDate).Date()
+	last := models.NewLocalDateTime(endDate).Date()
+
+	days := []models.ItineraryDay{}
+	index := map[time.Time]int{}
+	for d, n := first, 1; !d.After(last); d, n = d.AddDate(0, 0, 1), n+1 {
+		index[d] = len(days)
+		days = append(days, models.ItineraryDay{
+			Date:      d.Format("2006-01-02"),
+			DayNumber: n,
+			Items:     []models.ItineraryItem{},
+		})
+	}
+
+	for _, item := range items {
+		// O último instante ocupado é EndsAt exclusivo: terminar à meia-noite não ocupa o dia seguinte.
+		lastDay := item.EndsAt.Add(-time.Nanosecond)
+		lastDate := time.Date(lastDay.Year(), lastDay.Month(), lastDay.Day(), 0, 0, 0, 0, time.UTC)
+		for d := item.StartsAt.Date(); !d.After(lastDate); d = d.AddDate(0, 0, 1) {
+			if i, ok := index[d]; ok {
+				days[i].Items = append(days[i].Items, item)
+			}
+		}
+	}
+	return days
+}
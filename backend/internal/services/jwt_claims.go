@@ -4,10 +4,15 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-const JwtSecret = "sua_chave_secreta_muito_forte_aqui"
+// Finalidades dos tokens assinados de uso único. Tokens de acesso não têm finalidade.
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+)
 
 // UserClaims define a estrutura dos dados que serão armazenados no token JWT.
 type UserClaims struct {
-	UserID int `json:"user_id"`
+	UserID  int    `json:"user_id"`
+	Purpose string `json:"purpose,omitempty"`
 	jwt.RegisteredClaims
 }
@@ -0,0 +1,147 @@
+package services
+
+import (
+	"crypto"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// ErrUnknownSigningKey indica um token assinado com um kid que não está na configuração.
+var ErrUnknownSigningKey = errors.New("chave de assinatura desconhecida")
+
+// minHMACSecretLength é o tamanho mínimo (em bytes) aceito para segredos HS256.
+const minHMACSecretLength = 32
+
+// SigningKey é uma chave identificada por kid. SignKey é nil para chaves que
+// só verificam (por exemplo, a chave pública de uma chave já aposentada).
+type SigningKey struct {
+	ID        string
+	Method    jwt.SigningMethod
+	SignKey   any
+	VerifyKey any
+}
+
+// KeySet guarda todas as chaves aceitas na verificação e qual delas assina os
+// novos tokens. Para rotacionar, adicione a nova chave, troque a ativa e só
+// remova a antiga depois que os tokens emitidos com ela expirarem.
+type KeySet struct {
+	active *SigningKey
+	keys   map[string]*SigningKey
+}
+
+// ParseKeySet lê a configuração no formato "kid:ALG:valor,kid:ALG:valor". Para
+// HS256 o valor é o próprio segredo; para RS256 e EdDSA é o caminho de um
+// arquivo PEM (chave privada para assinar, ou pública para apenas verificar).
+func ParseKeySet(spec, activeKID string) (*KeySet, error) {
+	set := &KeySet{keys: make(map[string]*SigningKey)}
+
+	for _, entry := range strings.Split(spec, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		parts := strings.SplitN(entry, ":", 3)
+		if len(parts) != 3 || parts[0] == "" || parts[2] == "" {
+			return nil, fmt.Errorf("chave JWT inválida %q: use kid:ALG:valor", entry)
+		}
+		kid, alg, value := parts[0], strings.ToUpper(parts[1]), parts[2]
+		if _, exists := set.keys[kid]; exists {
+			return nil, fmt.Errorf("kid JWT duplicado: %s", kid)
+		}
+
+		key, err := loadSigningKey(kid, alg, value)
+		if err != nil {
+			return nil, err
+		}
+		set.keys[kid] = key
+	}
+
+	if len(set.keys) == 0 {
+		return nil, errors.New("nenhuma chave JWT configurada")
+	}
+
+	if activeKID == "" && len(set.keys) == 1 {
+		for kid := range set.keys {
+			activeKID = kid
+		}
+	}
+	active, ok := set.keys[activeKID]
+	if !ok {
+		return nil, fmt.Errorf("chave JWT ativa %q não está entre as chaves configuradas", activeKID)
+	}
+	if active.SignKey == nil {
+		return nil, fmt.Errorf("chave JWT ativa %q não tem chave privada para assinar", activeKID)
+	}
+	set.active = active
+
+	return set, nil
+}
+
+func loadSigningKey(kid, alg, value string) (*SigningKey, error) {
+	switch alg {
+	case "HS256":
+		if len(value) < minHMACSecretLength {
+			return nil, fmt.Errorf("segredo da chave JWT %q deve ter pelo menos %d bytes", kid, minHMACSecretLength)
+		}
+		secret := []byte(value)
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodHS256, SignKey: secret, VerifyKey: secret}, nil
+
+	case "RS256":
+		pem, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler chave JWT %q: %w", kid, err)
+		}
+		key := &SigningKey{ID: kid, Method: jwt.SigningMethodRS256}
+		if private, err := jwt.ParseRSAPrivateKeyFromPEM(pem); err == nil {
+			key.SignKey, key.VerifyKey = private, &private.PublicKey
+		} else if public, err := jwt.ParseRSAPublicKeyFromPEM(pem); err == nil {
+			key.VerifyKey = public
+		} else {
+			return nil, fmt.Errorf("chave JWT %q não é uma chave RSA em PEM", kid)
+		}
+		return key, nil
+
+	case "EDDSA":
+		pem, err := os.ReadFile(value)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao ler chave JWT %q: %w", kid, err)
+		}
+		key := &SigningKey{ID: kid, Method: jwt.SigningMethodEdDSA}
+		if private, err := jwt.ParseEdPrivateKeyFromPEM(pem); err == nil {
+			key.SignKey, key.VerifyKey = private, private.(crypto.Signer).Public()
+		} else if public, err := jwt.ParseEdPublicKeyFromPEM(pem); err == nil {
+			key.VerifyKey = public
+		} else {
+			return nil, fmt.Errorf("chave JWT %q não é uma chave Ed25519 em PEM", kid)
+		}
+		return key, nil
+
+	default:
+		return nil, fmt.Errorf("algoritmo JWT não suportado na chave %q: %s (use HS256, RS256 ou EdDSA)", kid, alg)
+	}
+}
+
+// Sign assina as claims com a chave ativa, registrando o kid no cabeçalho.
+func (s *KeySet) Sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(s.active.Method, claims)
+	token.Header["kid"] = s.active.ID
+	return token.SignedString(s.active.SignKey)
+}
+
+// Keyfunc escolhe a chave de verificação pelo kid do token e exige que o
+// algoritmo do cabeçalho seja o da chave, evitando a troca de algoritmo.
+func (s *KeySet) Keyfunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys[kid]
+	if !ok {
+		return nil, ErrUnknownSigningKey
+	}
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("algoritmo %s não corresponde à chave %q", token.Method.Alg(), kid)
+	}
+	return key.VerifyKey, nil
+}
@@ -0,0 +1,183 @@
+package services
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	secretA = "segredo-a-com-pelo-menos-32-bytes!!"
+	secretB = "segredo-b-com-pelo-menos-32-bytes!!"
+)
+
+func mustKeySet(t *testing.T, spec, active string) *KeySet {
+	t.Helper()
+	keys, err := ParseKeySet(spec, active)
+	if err != nil {
+		t.Fatalf("ParseKeySet(%q, %q): %v", spec, active, err)
+	}
+	return keys
+}
+
+// writeRSAKey grava uma chave RSA nova em PEM e devolve os caminhos da chave
+// privada e da pública.
+func writeRSAKey(t *testing.T) (string, string) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("erro ao gerar chave RSA: %v", err)
+	}
+	public, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("erro ao serializar chave pública: %v", err)
+	}
+
+	dir := t.TempDir()
+	privatePath, publicPath := filepath.Join(dir, "private.pem"), filepath.Join(dir, "public.pem")
+	privatePEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+	publicPEM := pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: public})
+	if err := os.WriteFile(privatePath, privatePEM, 0o600); err != nil {
+		t.Fatalf("erro ao gravar chave: %v", err)
+	}
+	if err := os.WriteFile(publicPath, publicPEM, 0o600); err != nil {
+		t.Fatalf("erro ao gravar chave: %v", err)
+	}
+	return privatePath, publicPath
+}
+
+func TestParseKeySetRejects(t *testing.T) {
+	_, publicPath := writeRSAKey(t)
+
+	tests := []struct {
+		name   string
+		spec   string
+		active string
+	}{
+		{"vazia", "", ""},
+		{"formato inválido", "k1:" + secretA, ""},
+		{"segredo curto", "k1:HS256:curto", ""},
+		{"kid duplicado", "k1:HS256:" + secretA + ",k1:HS256:" + secretB, "k1"},
+		{"algoritmo não suportado", "k1:HS512:" + secretA, ""},
+		{"ativa fora da lista", "k1:HS256:" + secretA, "k2"},
+		{"várias chaves sem ativa", "k1:HS256:" + secretA + ",k2:HS256:" + secretB, ""},
+		{"ativa só verifica", "rs:RS256:" + publicPath, "rs"},
+		{"arquivo inexistente", "rs:RS256:" + filepath.Join(t.TempDir(), "nada.pem"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseKeySet(tt.spec, tt.active); err == nil {
+				t.Fatalf("ParseKeySet(%q, %q) deveria falhar", tt.spec, tt.active)
+			}
+		})
+	}
+}
+
+// Na rotação, tokens da chave antiga continuam válidos enquanto ela estiver
+// na configuração (mesmo só com a chave pública); depois de aposentada, o kid
+// é desconhecido. Token expirado é recusado por qualquer chave.
+func TestKeySetRotation(t *testing.T) {
+	privatePath, publicPath := writeRSAKey(t)
+
+	old := mustKeySet(t, "old:RS256:"+privatePath, "")
+	now := time.Now()
+	valid, err := old.Sign(&UserClaims{UserID: 7, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour))}})
+	if err != nil {
+		t.Fatalf("erro ao assinar: %v", err)
+	}
+	expired, err := old.Sign(&UserClaims{UserID: 7, RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(now.Add(-time.Minute))}})
+	if err != nil {
+		t.Fatalf("erro ao assinar: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		keys    *KeySet
+		token   string
+		wantErr error
+	}{
+		{"chave antiga ainda ativa", old, valid, nil},
+		{"nova ativa, antiga só verifica", mustKeySet(t, "new:HS256:"+secretA+",old:RS256:"+publicPath, "new"), valid, nil},
+		{"antiga aposentada", mustKeySet(t, "new:HS256:"+secretA, ""), valid, ErrUnknownSigningKey},
+		{"token expirado", old, expired, jwt.ErrTokenExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims := &UserClaims{}
+			_, err := jwt.ParseWithClaims(tt.token, claims, tt.keys.Keyfunc, jwt.WithExpirationRequired())
+			if tt.wantErr == nil {
+				if err != nil || claims.UserID != 7 {
+					t.Fatalf("erro = %v, usuário %d; esperado token válido do usuário 7", err, claims.UserID)
+				}
+				return
+			}
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("erro = %v, esperado %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// O algoritmo do cabeçalho tem que ser o da chave do kid: um token HS256
+// assinado com a chave pública RSA (ataque de troca de algoritmo), um HS384
+// com o segredo certo e um "none" são todos recusados.
+func TestKeySetRejectsAlgorithmMismatch(t *testing.T) {
+	privatePath, publicPath := writeRSAKey(t)
+	keys := mustKeySet(t, "hs:HS256:"+secretA+",rs:RS256:"+privatePath, "hs")
+	publicPEM, err := os.ReadFile(publicPath)
+	if err != nil {
+		t.Fatalf("erro ao ler chave pública: %v", err)
+	}
+
+	sign := func(method jwt.SigningMethod, kid string, key any) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, &UserClaims{UserID: 7, RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		}})
+		token.Header["kid"] = kid
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("erro ao assinar: %v", err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"HS256 com a chave pública do kid RS256", sign(jwt.SigningMethodHS256, "rs", publicPEM)},
+		{"HS384 com o segredo do kid HS256", sign(jwt.SigningMethodHS384, "hs", []byte(secretA))},
+		{"alg none", sign(jwt.SigningMethodNone, "hs", jwt.UnsafeAllowNoneSignatureType)},
+		{"sem kid", sign(jwt.SigningMethodHS256, "", []byte(secretA))},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := jwt.ParseWithClaims(tt.token, &UserClaims{}, keys.Keyfunc, jwt.WithExpirationRequired())
+			if err == nil || token.Valid {
+				t.Fatalf("token aceito; esperado erro")
+			}
+		})
+	}
+
+	// O mesmo conjunto aceita os tokens legítimos das duas chaves.
+	for kid, token := range map[string]string{
+		"hs": sign(jwt.SigningMethodHS256, "hs", []byte(secretA)),
+		"rs": sign(jwt.SigningMethodRS256, "rs", keys.keys["rs"].SignKey),
+	} {
+		if _, err := jwt.ParseWithClaims(token, &UserClaims{}, keys.Keyfunc); err != nil {
+			t.Fatalf("token legítimo do kid %s recusado: %v", kid, err)
+		}
+	}
+}
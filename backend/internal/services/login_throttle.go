@@ -0,0 +1,50 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidCredentials é devolvido para e-mail inexistente ou senha errada, sem distinguir os casos.
+var ErrInvalidCredentials = errors.New("usuário ou senha incorretos")
+
+// AccountLockedError indica que o e-mail está temporariamente bloqueado por
+// excesso de falhas de login.
+type AccountLockedError struct {
+	Until time.Time
+}
+
+func (e *AccountLockedError) Error() string {
+	return fmt.Sprintf("login bloqueado até %s", e.Until.Format(time.RFC3339))
+}
+
+// LoginThrottlePolicy define o backoff das falhas de login por e-mail: as
+// primeiras FreeAttempts falhas não bloqueiam; a partir daí cada falha bloqueia
+// por BaseDelay, dobrando a cada nova falha até MaxDelay. Falhas mais antigas
+// que Window são esquecidas.
+type LoginThrottlePolicy struct {
+	FreeAttempts int
+	BaseDelay    time.Duration
+	MaxDelay     time.Duration
+	Window       time.Duration
+}
+
+var DefaultLoginThrottle = LoginThrottlePolicy{
+	FreeAttempts: 5,
+	BaseDelay:    30 * time.Second,
+	MaxDelay:     30 * time.Minute,
+	Window:       time.Hour,
+}
+
+// Delay devolve por quanto tempo bloquear após a failures-ésima falha seguida.
+func (p LoginThrottlePolicy) Delay(failures int) time.Duration {
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, p.MaxDelay)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"bufio"
+	_ "embed"
+	"fmt"
+	"io"
+	"project_lab/internal/i18n"
+	"strings"
+	"unicode/utf8"
+)
+
+// commonPasswords é a lista padrão de senhas vazadas/comuns, uma por linha.
+//
+//go:embed common_passwords.txt
+var commonPasswords string
+
+const (
+	DefaultPasswordMinLength = 8
+	// bcrypt ignora o que passa de 72 bytes; senhas maiores seriam truncadas em silêncio.
+	passwordMaxBytes = 72
+)
+
+// PasswordPolicy define as regras para novas senhas: tamanho mínimo e recusa de
+// senhas que aparecem em listas de vazamentos (comparação sem diferenciar maiúsculas).
+type PasswordPolicy struct {
+	minLength int
+	blocked   map[string]struct{}
+}
+
+// NewPasswordPolicy cria a política com a lista embutida de senhas comuns e,
+// opcionalmente, listas adicionais (um arquivo por leitor, uma senha por linha).
+func NewPasswordPolicy(minLength int, extraLists ...io.Reader) (*PasswordPolicy, error) {
+	if minLength <= 0 {
+		minLength = DefaultPasswordMinLength
+	}
+	p := &PasswordPolicy{minLength: minLength, blocked: make(map[string]struct{})}
+
+	lists := append([]io.Reader{strings.NewReader(commonPasswords)}, extraLists...)
+	for _, list := range lists {
+		scanner := bufio.NewScanner(list)
+		for scanner.Scan() {
+			if line := strings.TrimSpace(scanner.Text()); line != "" && !strings.HasPrefix(line, "#") {
+				p.blocked[strings.ToLower(line)] = struct{}{}
+			}
+		}
+		if err := scanner.Err(); err != nil {
+			return nil, fmt.Errorf("erro ao ler lista de senhas bloqueadas: %w", err)
+		}
+	}
+	return p, nil
+}
+
+// Check devolve a mensagem do primeiro problema da senha, ou nil se ela é
+// aceita. O e-mail do usuário também é recusado como senha.
+func (p *PasswordPolicy) Check(password, email string) *i18n.Message {
+	if password == "" {
+		return message("validation.password_required")
+	}
+	if utf8.RuneCountInString(password) < p.minLength {
+		return message("validation.password_too_short", p.minLength)
+	}
+	if len(password) > passwordMaxBytes {
+		return message("validation.password_too_long", passwordMaxBytes)
+	}
+
+	lower := strings.ToLower(password)
+	if _, blocked := p.blocked[lower]; blocked {
+		return message("validation.password_breached")
+	}
+	if email != "" {
+		local, _, _ := strings.Cut(email, "@")
+		if lower == email || lower == local {
+			return message("validation.password_is_email")
+		}
+	}
+	return nil
+}
@@ -0,0 +1,393 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+	"sort"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrUnsettledBalance indica que o membro ainda tem saldo a pagar ou a receber.
+	ErrUnsettledBalance = errors.New("membro com saldo pendente")
+	// ErrInvalidPayment indica que o pagamento de acerto não respeita as regras.
+	ErrInvalidPayment = errors.New("pagamento inválido")
+)
+
+// SettlementService calcula saldos e acertos de contas de um grupo e registra
+// os pagamentos feitos para quitá-los.
+type SettlementService interface {
+	GetBalances(ctx context.Context, groupID int) ([]models.MemberBalance, error)
+	GetSettlements(ctx context.Context, groupID int) ([]models.Settlement, error)
+	ListPayments(ctx context.Context, groupID int) ([]models.SettlementPayment, error)
+	GetPayment(ctx context.Context, groupID int, paymentID int) (*models.SettlementPayment, error)
+	RecordPayment(ctx context.Context, payment *models.SettlementPayment) error
+	DeletePayment(ctx context.Context, groupID int, paymentID int) error
+	RemoveMember(ctx context.Context, groupID int, userID int, actorID int) error
+}
+
+type settlementService struct {
+	groupRepo repositories.TravelGroupRepository
+	payments  repositories.SettlementPaymentRepository
+	rates     ExchangeRateService
+}
+
+// NewSettlementService cria uma nova instância de SettlementService.
+func NewSettlementService(groupRepo repositories.TravelGroupRepository, payments repositories.SettlementPaymentRepository, rates ExchangeRateService) SettlementService {
+	return &settlementService{groupRepo: groupRepo, payments: payments, rates: rates}
+}
+
+// ledgerEntry acumula, em centavos, quanto cada membro pagou e quanto consumiu,
+// além dos pagamentos de acerto que fez (sent) e recebeu (received).
+type ledgerEntry struct {
+	userID   int
+	name     string
+	paid     int64
+	owed     int64
+	sent     int64
+	received int64
+	former   bool
+}
+
+func (e *ledgerEntry) balance() int64 {
+	return e.paid - e.owed + e.sent - e.received
+}
+
+// buildLedger consolida as despesas e os pagamentos do grupo em uma entrada por usuário, ordenada por ID.
+// O valor devido por cada participante vem de Shares (expense_participants.share_amount);
+// sem essa informação, a despesa é dividida igualmente entre ParticipantsIDs.
+// Despesas e pagamentos já devem estar na mesma moeda.
+func buildLedger(members []models.GroupMemberDTO, expenses []models.ExpenseDTO, payments []models.SettlementPayment) []*ledgerEntry {
+	entries := map[int]*ledgerEntry{}
+	entry := func(userID int) *ledgerEntry {
+		e, ok := entries[userID]
+		if !ok {
+			e = &ledgerEntry{userID: userID}
+			entries[userID] = e
+		}
+		return e
+	}
+
+	for _, m := range members {
+		e := entry(m.UserID)
+		e.name = m.Name
+		e.former = m.LeftAt != nil
+	}
+
+	for _, exp := range expenses {
+		cents := int64(exp.Amount)
+		payer := entry(exp.PayerID)
+		payer.paid += cents
+		if payer.name == "" {
+			payer.name = exp.PayerName
+		}
+
+		if len(exp.Shares) > 0 {
+			for _, share := range exp.Shares {
+				entry(share.UserID).owed += int64(share.Amount)
+			}
+			continue
+		}
+
+		participants := append([]int(nil), exp.ParticipantsIDs...)
+		if len(participants) == 0 {
+			// Sem participantes, a despesa é considerada apenas do pagador.
+			participants = []int{exp.PayerID}
+		}
+		sort.Ints(participants)
+
+		for i, part := range splitEqually(cents, len(participants)) {
+			entry(participants[i]).owed += part
+		}
+	}
+
+	for _, p := range payments {
+		from, to := entry(p.FromUserID), entry(p.ToUserID)
+		from.sent += int64(p.Amount)
+		to.received += int64(p.Amount)
+		if from.name == "" {
+			from.name = p.FromName
+		}
+		if to.name == "" {
+			to.name = p.ToName
+		}
+	}
+
+	ledger := make([]*ledgerEntry, 0, len(entries))
+	for _, e := range entries {
+		ledger = append(ledger, e)
+	}
+	sort.Slice(ledger, func(i, j int) bool { return ledger[i].userID < ledger[j].userID })
+	return ledger
+}
+
+// convertToBase converte a despesa para a moeda base do grupo, redistribuindo
+// as partes proporcionalmente para que continuem somando o valor convertido.
+func (s *settlementService) convertToBase(ctx context.Context, exp *models.ExpenseDTO, baseCurrency string) error {
+	if exp.Currency == baseCurrency {
+		return nil
+	}
+
+	converted, err := s.rates.Convert(ctx, exp.Amount, exp.Currency, baseCurrency, exp.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("despesa %d (%s): %w", exp.ID, exp.Currency, err)
+	}
+
+	if len(exp.Shares) > 0 {
+		weights := make([]*big.Rat, len(exp.Shares))
+		for i, share := range exp.Shares {
+			weights[i] = big.NewRat(int64(share.Amount), 1)
+		}
+		parts := splitProportionally(int64(converted), weights)
+		for i := range exp.Shares {
+			exp.Shares[i].Amount = models.Money(parts[i])
+		}
+	}
+
+	exp.Amount = converted
+	exp.Currency = baseCurrency
+	return nil
+}
+
+// paymentsToBase converte os pagamentos para a moeda base do grupo, pelo câmbio
+// do dia em que foram feitos.
+func (s *settlementService) paymentsToBase(ctx context.Context, payments []models.SettlementPayment, baseCurrency string) error {
+	for i := range payments {
+		p := &payments[i]
+		if p.Currency == baseCurrency {
+			continue
+		}
+		converted, err := s.rates.Convert(ctx, p.Amount, p.Currency, baseCurrency, p.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("pagamento %d (%s): %w", p.ID, p.Currency, err)
+		}
+		p.Amount = converted
+		p.Currency = baseCurrency
+	}
+	return nil
+}
+
+// minimizeTransfers gera as transferências para zerar os saldos.
+// Primeiro casa devedores e credores com valores idênticos e depois aplica
+// a estratégia gulosa (maior devedor paga ao maior credor), o que resulta
+// em no máximo n-1 transferências.
+func minimizeTransfers(ledger []*ledgerEntry, currency string) []models.Settlement {
+	type position struct {
+		userID int
+		name   string
+		amount int64
+	}
+
+	var debtors, creditors []*position
+	for _, e := range ledger {
+		switch b := e.balance(); {
+		case b < 0:
+			debtors = append(debtors, &position{e.userID, e.name, -b})
+		case b > 0:
+			creditors = append(creditors, &position{e.userID, e.name, b})
+		}
+	}
+
+	settlements := []models.Settlement{}
+	transfer := func(from, to *position, amount int64) {
+		settlements = append(settlements, models.Settlement{
+			FromUserID: from.userID,
+			FromName:   from.name,
+			ToUserID:   to.userID,
+			ToName:     to.name,
+			Amount:     models.Money(amount),
+			Currency:   currency,
+		})
+		from.amount -= amount
+		to.amount -= amount
+	}
+
+	for _, d := range debtors {
+		for _, c := range creditors {
+			if d.amount > 0 && d.amount == c.amount {
+				transfer(d, c, d.amount)
+				break
+			}
+		}
+	}
+
+	for {
+		sort.SliceStable(debtors, func(i, j int) bool { return debtors[i].amount > debtors[j].amount })
+		sort.SliceStable(creditors, func(i, j int) bool { return creditors[i].amount > creditors[j].amount })
+
+		if len(debtors) == 0 || len(creditors) == 0 || debtors[0].amount == 0 || creditors[0].amount == 0 {
+			break
+		}
+
+		d, c := debtors[0], creditors[0]
+		transfer(d, c, min(d.amount, c.amount))
+	}
+
+	return settlements
+}
+
+// loadExpenses busca as despesas do grupo já convertidas para a moeda base e
+// retorna essa moeda.
+func (s *settlementService) loadExpenses(ctx context.Context, groupID int) ([]models.ExpenseDTO, string, error) {
+	baseCurrency, err := s.groupRepo.GetGroupBaseCurrency(ctx, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	expenses, err := s.groupRepo.ListGroupExpenses(ctx, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	for i := range expenses {
+		if err := s.convertToBase(ctx, &expenses[i], baseCurrency); err != nil {
+			return nil, "", err
+		}
+	}
+	return expenses, baseCurrency, nil
+}
+
+// loadLedger monta o livro-razão do grupo na moeda base e retorna essa moeda.
+func (s *settlementService) loadLedger(ctx context.Context, groupID int) ([]*ledgerEntry, string, error) {
+	expenses, baseCurrency, err := s.loadExpenses(ctx, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// Ex-membros entram no livro-razão: as despesas antigas continuam valendo.
+	members, err := s.groupRepo.ListGroupMembers(ctx, groupID, true)
+	if err != nil {
+		return nil, "", err
+	}
+
+	payments, err := s.payments.ListPayments(ctx, groupID)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.paymentsToBase(ctx, payments, baseCurrency); err != nil {
+		return nil, "", err
+	}
+
+	return buildLedger(members, expenses, payments), baseCurrency, nil
+}
+
+// GetBalances retorna o saldo (pago - devido + pagamentos feitos - recebidos)
+// de cada membro do grupo.
+func (s *settlementService) GetBalances(ctx context.Context, groupID int) ([]models.MemberBalance, error) {
+	ledger, currency, err := s.loadLedger(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make([]models.MemberBalance, 0, len(ledger))
+	for _, e := range ledger {
+		balances = append(balances, e.memberBalance(currency))
+	}
+	return balances, nil
+}
+
+func (e *ledgerEntry) memberBalance(currency string) models.MemberBalance {
+	return models.MemberBalance{
+		UserID:       e.userID,
+		Name:         e.name,
+		Paid:         models.Money(e.paid),
+		Owed:         models.Money(e.owed),
+		Sent:         models.Money(e.sent),
+		Received:     models.Money(e.received),
+		Balance:      models.Money(e.balance()),
+		Currency:     currency,
+		FormerMember: e.former,
+	}
+}
+
+// GetSettlements retorna as transferências sugeridas para quitar o grupo.
+func (s *settlementService) GetSettlements(ctx context.Context, groupID int) ([]models.Settlement, error) {
+	ledger, currency, err := s.loadLedger(ctx, groupID)
+	if err != nil {
+		return nil, err
+	}
+	return minimizeTransfers(ledger, currency), nil
+}
+
+// ListPayments lista os pagamentos do grupo, cada um na moeda em que foi feito.
+func (s *settlementService) ListPayments(ctx context.Context, groupID int) ([]models.SettlementPayment, error) {
+	return s.payments.ListPayments(ctx, groupID)
+}
+
+// GetPayment busca um pagamento do grupo.
+func (s *settlementService) GetPayment(ctx context.Context, groupID int, paymentID int) (*models.SettlementPayment, error) {
+	return s.payments.GetPayment(ctx, groupID, paymentID)
+}
+
+// RecordPayment valida e grava um pagamento de acerto entre dois membros
+// ativos. Sem moeda, vale a moeda base do grupo; as demais precisam ter câmbio
+// para ela, senão o pagamento ficaria de fora dos saldos.
+func (s *settlementService) RecordPayment(ctx context.Context, payment *models.SettlementPayment) error {
+	if payment.Amount <= 0 {
+		return i18n.Errorf(ErrInvalidPayment, "payment.amount_positive")
+	}
+	if payment.Amount > models.MaxMoney {
+		return i18n.Errorf(ErrInvalidPayment, "validation.amount_too_large", models.MaxMoney.String())
+	}
+	if payment.FromUserID == payment.ToUserID {
+		return i18n.Errorf(ErrInvalidPayment, "payment.same_member")
+	}
+
+	baseCurrency, err := s.groupRepo.GetGroupBaseCurrency(ctx, payment.TravelGroupID)
+	if err != nil {
+		return err
+	}
+
+	payment.Currency = strings.ToUpper(strings.TrimSpace(payment.Currency))
+	if payment.Currency == "" {
+		payment.Currency = baseCurrency
+	}
+	if !models.IsValidCurrency(payment.Currency) {
+		return i18n.Errorf(ErrInvalidPayment, "request.invalid_currency")
+	}
+	if _, err := s.rates.Convert(ctx, payment.Amount, payment.Currency, baseCurrency, time.Now()); err != nil {
+		return err
+	}
+
+	return s.payments.CreatePayment(ctx, payment)
+}
+
+// DeletePayment apaga um pagamento; os saldos voltam a contar como antes dele.
+func (s *settlementService) DeletePayment(ctx context.Context, groupID int, paymentID int) error {
+	return s.payments.DeletePayment(ctx, groupID, paymentID)
+}
+
+// RemoveMember registra a saída do membro pedida por actorID se os papéis
+// permitirem (CheckMemberRemoval) e o saldo dele estiver zerado; do contrário
+// devolve ErrUnsettledBalance com o valor pendente. Papéis e saldo são
+// conferidos na mesma transação da saída (ver TravelGroupRepository.RemoveMember).
+func (s *settlementService) RemoveMember(ctx context.Context, groupID int, userID int, actorID int) error {
+	return s.groupRepo.RemoveMember(ctx, groupID, userID, actorID, func(removal repositories.MemberRemoval) error {
+		if err := CheckMemberRemoval(removal.ActorRole, removal.Role, userID == actorID); err != nil {
+			return err
+		}
+
+		data := removal.Ledger
+		for i := range data.Expenses {
+			if err := s.convertToBase(ctx, &data.Expenses[i], data.BaseCurrency); err != nil {
+				return err
+			}
+		}
+		if err := s.paymentsToBase(ctx, data.Payments, data.BaseCurrency); err != nil {
+			return err
+		}
+
+		for _, e := range buildLedger(data.Members, data.Expenses, data.Payments) {
+			if e.userID == userID && e.balance() != 0 {
+				return i18n.Errorf(ErrUnsettledBalance, "member.unsettled_balance", models.Money(e.balance()).String(), data.BaseCurrency)
+			}
+		}
+		return nil
+	})
+}
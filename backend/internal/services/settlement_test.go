@@ -0,0 +1,478 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+)
+
+func ledgerOf(balances map[int]int64) []*ledgerEntry {
+	ledger := make([]*ledgerEntry, 0, len(balances))
+	for id := 1; len(ledger) < len(balances); id++ {
+		if b, ok := balances[id]; ok {
+			e := &ledgerEntry{userID: id, name: string(rune('A' + id - 1))}
+			if b > 0 {
+				e.paid = b
+			} else {
+				e.owed = -b
+			}
+			ledger = append(ledger, e)
+		}
+	}
+	return ledger
+}
+
+func TestBuildLedger(t *testing.T) {
+	members := []models.GroupMemberDTO{
+		{UserID: 1, Name: "Ana"},
+		{UserID: 2, Name: "Bruno"},
+		{UserID: 3, Name: "Carla"},
+	}
+	expenses := []models.ExpenseDTO{
+		// Sem Shares: divisão igual, o centavo que sobra fica com o menor ID.
+		{PayerID: 1, Amount: 1000, ParticipantsIDs: []int{3, 2, 1}},
+		// Com Shares: vale o valor gravado de cada participante.
+		{PayerID: 2, Amount: 900, Shares: []models.ExpenseShare{{UserID: 1, Amount: 600}, {UserID: 3, Amount: 300}}},
+		// Sem participantes: a despesa é só do pagador.
+		{PayerID: 3, Amount: 250},
+		// Pagador que não está mais na lista de membros.
+		{PayerID: 4, PayerName: "Davi", Amount: 300, ParticipantsIDs: []int{1, 4}},
+	}
+
+	got := map[int][3]int64{}
+	var total int64
+	for _, e := range buildLedger(members, expenses, nil) {
+		got[e.userID] = [3]int64{e.paid, e.owed, e.balance()}
+		total += e.balance()
+	}
+	want := map[int][3]int64{
+		1: {1000, 334 + 600 + 150, 1000 - 1084},
+		2: {900, 333, 567},
+		3: {250, 333 + 300 + 250, 250 - 883},
+		4: {300, 150, 150},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("livro-razão = %v, esperado %v", got, want)
+	}
+	if total != 0 {
+		t.Fatalf("soma dos saldos = %d, esperado 0", total)
+	}
+}
+
+func TestBuildLedgerWithoutExpenses(t *testing.T) {
+	ledger := buildLedger([]models.GroupMemberDTO{{UserID: 2, Name: "Bruno"}, {UserID: 1, Name: "Ana"}}, nil, nil)
+	if len(ledger) != 2 || ledger[0].userID != 1 || ledger[1].userID != 2 {
+		t.Fatalf("livro-razão fora de ordem: %+v, %+v", ledger[0], ledger[1])
+	}
+	for _, e := range ledger {
+		if e.balance() != 0 {
+			t.Fatalf("saldo de %d = %d, esperado 0", e.userID, e.balance())
+		}
+	}
+}
+
+func TestMinimizeTransfers(t *testing.T) {
+	tests := []struct {
+		name     string
+		balances map[int]int64
+		want     []models.Settlement
+	}{
+		{
+			name:     "todos quites",
+			balances: map[int]int64{1: 0, 2: 0, 3: 0},
+			want:     []models.Settlement{},
+		},
+		{
+			name:     "livro vazio",
+			balances: map[int]int64{},
+			want:     []models.Settlement{},
+		},
+		{
+			name:     "um devedor e um credor",
+			balances: map[int]int64{1: 500, 2: -500},
+			want:     []models.Settlement{{FromUserID: 2, FromName: "B", ToUserID: 1, ToName: "A", Amount: 500, Currency: "BRL"}},
+		},
+		{
+			name:     "valores idênticos são casados primeiro",
+			balances: map[int]int64{1: 700, 2: 300, 3: -300, 4: -700},
+			want: []models.Settlement{
+				{FromUserID: 3, FromName: "C", ToUserID: 2, ToName: "B", Amount: 300, Currency: "BRL"},
+				{FromUserID: 4, FromName: "D", ToUserID: 1, ToName: "A", Amount: 700, Currency: "BRL"},
+			},
+		},
+		{
+			name:     "maior devedor paga ao maior credor",
+			balances: map[int]int64{1: 1000, 2: 1, 3: -667, 4: -334},
+			want: []models.Settlement{
+				{FromUserID: 3, FromName: "C", ToUserID: 1, ToName: "A", Amount: 667, Currency: "BRL"},
+				{FromUserID: 4, FromName: "D", ToUserID: 1, ToName: "A", Amount: 333, Currency: "BRL"},
+				{FromUserID: 4, FromName: "D", ToUserID: 2, ToName: "B", Amount: 1, Currency: "BRL"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := minimizeTransfers(ledgerOf(tt.balances), "BRL")
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("transferências = %+v, esperado %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+// As transferências sugeridas sempre zeram todos os saldos, em no máximo n-1 passos.
+func TestMinimizeTransfersSettlesEveryone(t *testing.T) {
+	balances := map[int]int64{1: 12345, 2: -2345, 3: -4000, 4: 1, 5: -6000, 6: -1, 7: -1, 8: 1}
+	ledger := ledgerOf(balances)
+	settlements := minimizeTransfers(ledger, "EUR")
+
+	if len(settlements) > len(ledger)-1 {
+		t.Fatalf("%d transferências para %d membros", len(settlements), len(ledger))
+	}
+	remaining := map[int]int64{}
+	for id, b := range balances {
+		remaining[id] = b
+	}
+	for _, s := range settlements {
+		if s.Amount <= 0 || s.Currency != "EUR" {
+			t.Fatalf("transferência inválida: %+v", s)
+		}
+		remaining[s.FromUserID] += int64(s.Amount)
+		remaining[s.ToUserID] -= int64(s.Amount)
+	}
+	for id, b := range remaining {
+		if b != 0 {
+			t.Fatalf("membro %d termina com saldo %d", id, b)
+		}
+	}
+}
+
+func TestConvertToBase(t *testing.T) {
+	service := &settlementService{rates: NewExchangeRateService(&fakeRateRepo{rates: map[string]string{"EURBRL": "6.2345"}})}
+
+	tests := []struct {
+		name       string
+		expense    models.ExpenseDTO
+		wantAmount models.Money
+		wantShares []models.Money
+	}{
+		{
+			name: "mesma moeda não muda",
+			expense: models.ExpenseDTO{Amount: 1000, Currency: "BRL",
+				Shares: []models.ExpenseShare{{UserID: 1, Amount: 333}, {UserID: 2, Amount: 667}}},
+			wantAmount: 1000,
+			wantShares: []models.Money{333, 667},
+		},
+		{
+			name: "partes iguais continuam somando o valor convertido",
+			// 100.00 EUR = 623.45 BRL, dividido em três.
+			expense: models.ExpenseDTO{Amount: 10000, Currency: "EUR",
+				Shares: []models.ExpenseShare{{UserID: 1, Amount: 3334}, {UserID: 2, Amount: 3333}, {UserID: 3, Amount: 3333}}},
+			wantAmount: 62345,
+			wantShares: []models.Money{20786, 20780, 20779},
+		},
+		{
+			name: "partes desiguais",
+			// 0.10 EUR = 0.62 BRL (62.345 centavos arredondados).
+			expense: models.ExpenseDTO{Amount: 10, Currency: "EUR",
+				Shares: []models.ExpenseShare{{UserID: 1, Amount: 7}, {UserID: 2, Amount: 3}}},
+			wantAmount: 62,
+			wantShares: []models.Money{43, 19},
+		},
+		{
+			name:       "sem partes gravadas",
+			expense:    models.ExpenseDTO{Amount: 200, Currency: "EUR", ParticipantsIDs: []int{1, 2}},
+			wantAmount: 1247,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exp := tt.expense
+			if err := service.convertToBase(context.Background(), &exp, "BRL"); err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if exp.Amount != tt.wantAmount || exp.Currency != "BRL" {
+				t.Fatalf("despesa = %s %s, esperado %s BRL", exp.Amount, exp.Currency, tt.wantAmount)
+			}
+			var shares []models.Money
+			var sum models.Money
+			for _, s := range exp.Shares {
+				shares = append(shares, s.Amount)
+				sum += s.Amount
+			}
+			if !reflect.DeepEqual(shares, tt.wantShares) {
+				t.Fatalf("partes = %v, esperado %v", shares, tt.wantShares)
+			}
+			if len(shares) > 0 && sum != exp.Amount {
+				t.Fatalf("soma das partes = %s, esperado %s", sum, exp.Amount)
+			}
+		})
+	}
+
+	// Sem taxa, o erro identifica a despesa e mantém ErrRateNotFound.
+	exp := models.ExpenseDTO{ID: 9, Amount: 100, Currency: "GBP"}
+	if err := service.convertToBase(context.Background(), &exp, "BRL"); !errors.Is(err, repositories.ErrRateNotFound) {
+		t.Fatalf("erro = %v, esperado ErrRateNotFound", err)
+	}
+}
+
+// Despesas em moedas diferentes, convertidas e somadas no livro-razão, ainda
+// fecham em zero.
+func TestLedgerMultiCurrencyIsZeroSum(t *testing.T) {
+	service := &settlementService{rates: NewExchangeRateService(&fakeRateRepo{rates: map[string]string{"EURBRL": "6.2345", "USDBRL": "5.0001"}})}
+	expenses := []models.ExpenseDTO{
+		{PayerID: 1, Amount: 10001, Currency: "EUR", Shares: []models.ExpenseShare{{UserID: 1, Amount: 3334}, {UserID: 2, Amount: 3334}, {UserID: 3, Amount: 3333}}},
+		{PayerID: 2, Amount: 777, Currency: "USD", Shares: []models.ExpenseShare{{UserID: 1, Amount: 259}, {UserID: 3, Amount: 518}}},
+		{PayerID: 3, Amount: 5000, Currency: "BRL", ParticipantsIDs: []int{1, 2, 3}},
+	}
+	for i := range expenses {
+		if err := service.convertToBase(context.Background(), &expenses[i], "BRL"); err != nil {
+			t.Fatalf("erro inesperado: %v", err)
+		}
+	}
+
+	var total int64
+	for _, e := range buildLedger(nil, expenses, nil) {
+		total += e.balance()
+	}
+	if total != 0 {
+		t.Fatalf("soma dos saldos = %d, esperado 0", total)
+	}
+}
+
+// Pagamentos de acerto movem o saldo de quem pagou para cima e o de quem
+// recebeu para baixo, sem mexer no que foi pago e devido nas despesas.
+func TestBuildLedgerWithPayments(t *testing.T) {
+	members := []models.GroupMemberDTO{{UserID: 1, Name: "Ana"}, {UserID: 2, Name: "Bruno"}, {UserID: 3, Name: "Carla"}}
+	expenses := []models.ExpenseDTO{{PayerID: 1, Amount: 9000, ParticipantsIDs: []int{1, 2, 3}}}
+
+	tests := []struct {
+		name     string
+		payments []models.SettlementPayment
+		want     map[int]int64
+	}{
+		{"sem pagamentos", nil, map[int]int64{1: 6000, 2: -3000, 3: -3000}},
+		{"quitação total", []models.SettlementPayment{{FromUserID: 2, ToUserID: 1, Amount: 3000}}, map[int]int64{1: 3000, 2: 0, 3: -3000}},
+		{"quitação parcial", []models.SettlementPayment{{FromUserID: 3, ToUserID: 1, Amount: 1000}}, map[int]int64{1: 5000, 2: -3000, 3: -2000}},
+		{"pagamento a mais", []models.SettlementPayment{{FromUserID: 2, ToUserID: 1, Amount: 5000}}, map[int]int64{1: 1000, 2: 2000, 3: -3000}},
+		{"entre devedores", []models.SettlementPayment{{FromUserID: 2, ToUserID: 3, Amount: 500}}, map[int]int64{1: 6000, 2: -2500, 3: -3500}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := map[int]int64{}
+			var total int64
+			for _, e := range buildLedger(members, expenses, tt.payments) {
+				got[e.userID] = e.balance()
+				total += e.balance()
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("saldos = %v, esperado %v", got, tt.want)
+			}
+			if total != 0 {
+				t.Fatalf("soma dos saldos = %d, esperado 0", total)
+			}
+		})
+	}
+}
+
+// fakeGroupRepo guarda um grupo em memória. RemoveMember imita a transação do
+// repositório: lê os papéis e os dados dos saldos, chama check e só então
+// marca a saída.
+type fakeGroupRepo struct {
+	repositories.TravelGroupRepository
+	baseCurrency string
+	members      []models.GroupMemberDTO
+	expenses     []models.ExpenseDTO
+	payments     *fakePaymentRepo
+}
+
+func (f *fakeGroupRepo) GetGroupBaseCurrency(context.Context, int) (string, error) {
+	return f.baseCurrency, nil
+}
+
+func (f *fakeGroupRepo) activeMember(userID int) *models.GroupMemberDTO {
+	for i := range f.members {
+		if f.members[i].UserID == userID && f.members[i].LeftAt == nil {
+			return &f.members[i]
+		}
+	}
+	return nil
+}
+
+func (f *fakeGroupRepo) RemoveMember(_ context.Context, _ int, userID int, actorID int, check func(repositories.MemberRemoval) error) error {
+	actor, member := f.activeMember(actorID), f.activeMember(userID)
+	if actor == nil {
+		return repositories.ErrGroupNotFound
+	}
+	if member == nil {
+		return repositories.ErrMemberNotFound
+	}
+	err := check(repositories.MemberRemoval{
+		Role:      member.Role,
+		ActorRole: actor.Role,
+		Ledger: repositories.GroupLedger{
+			BaseCurrency: f.baseCurrency,
+			Members:      append([]models.GroupMemberDTO(nil), f.members...),
+			Expenses:     append([]models.ExpenseDTO(nil), f.expenses...),
+			Payments:     append([]models.SettlementPayment(nil), f.payments.payments...),
+		},
+	})
+	if err != nil {
+		return err
+	}
+	now := time.Now()
+	member.LeftAt = &now
+	return nil
+}
+
+type fakePaymentRepo struct {
+	repositories.SettlementPaymentRepository
+	payments []models.SettlementPayment
+}
+
+func (f *fakePaymentRepo) CreatePayment(_ context.Context, payment *models.SettlementPayment) error {
+	payment.ID = len(f.payments) + 1
+	f.payments = append(f.payments, *payment)
+	return nil
+}
+
+func newFakeSettlementService() (*settlementService, *fakeGroupRepo) {
+	payments := &fakePaymentRepo{}
+	repo := &fakeGroupRepo{
+		baseCurrency: "BRL",
+		members: []models.GroupMemberDTO{
+			{UserID: 1, Name: "Ana", Role: models.RoleOrganizer},
+			{UserID: 2, Name: "Bruno", Role: models.RoleParticipant},
+			{UserID: 3, Name: "Carla", Role: models.RoleCoOrganizer},
+			{UserID: 4, Name: "Davi", Role: models.RoleParticipant},
+		},
+		// Ana pagou R$ 100,00 divididos com Bruno, que deve R$ 50,00.
+		expenses: []models.ExpenseDTO{{ID: 1, PayerID: 1, Amount: 10000, Currency: "BRL", ParticipantsIDs: []int{1, 2}}},
+		payments: payments,
+	}
+	rates := NewExchangeRateService(&fakeRateRepo{rates: map[string]string{"EURBRL": "5"}})
+	return &settlementService{groupRepo: repo, payments: payments, rates: rates}, repo
+}
+
+// Quem deve não sai do grupo; depois de pagar (aqui em duas vezes, uma delas
+// em outra moeda), sai.
+func TestRemoveMemberAfterPayment(t *testing.T) {
+	service, repo := newFakeSettlementService()
+	ctx := context.Background()
+
+	err := service.RemoveMember(ctx, 1, 2, 2)
+	if !errors.Is(err, ErrUnsettledBalance) {
+		t.Fatalf("erro = %v, esperado ErrUnsettledBalance", err)
+	}
+	if !strings.Contains(err.Error(), "-50.00 BRL") {
+		t.Fatalf("mensagem = %q, esperado o saldo -50.00 BRL", err.Error())
+	}
+
+	steps := []struct {
+		payment models.SettlementPayment
+		pending string
+	}{
+		{models.SettlementPayment{FromUserID: 2, ToUserID: 1, Amount: 3000}, "-20.00 BRL"},
+		{models.SettlementPayment{FromUserID: 2, ToUserID: 1, Amount: 400, Currency: "eur"}, ""},
+	}
+	for _, step := range steps {
+		step.payment.TravelGroupID = 1
+		if err := service.RecordPayment(ctx, &step.payment); err != nil {
+			t.Fatalf("erro ao registrar pagamento: %v", err)
+		}
+
+		err := service.RemoveMember(ctx, 1, 2, 2)
+		if step.pending == "" {
+			if err != nil {
+				t.Fatalf("erro ao sair depois de quitar: %v", err)
+			}
+			continue
+		}
+		if !errors.Is(err, ErrUnsettledBalance) || !strings.Contains(err.Error(), step.pending) {
+			t.Fatalf("erro = %v, esperado saldo pendente de %s", err, step.pending)
+		}
+	}
+
+	if repo.members[1].LeftAt == nil {
+		t.Fatalf("Bruno deveria ter saído do grupo")
+	}
+	if repo.payments.payments[1].Currency != "EUR" {
+		t.Fatalf("moeda gravada = %q, esperado EUR", repo.payments.payments[1].Currency)
+	}
+}
+
+// Os papéis são conferidos junto com o saldo: com o saldo zerado, a saída
+// ainda depende de quem remove quem.
+func TestRemoveMemberChecksRoles(t *testing.T) {
+	tests := []struct {
+		name    string
+		member  int
+		actor   int
+		want    error
+		removed bool
+	}{
+		{"organizador sai", 1, 1, ErrOwnershipTransferRequired, false},
+		{"co-organizador remove o organizador", 1, 3, ErrOwnershipTransferRequired, false},
+		{"participante remove participante", 4, 2, ErrMemberRemovalForbidden, false},
+		{"co-organizador remove participante", 4, 3, nil, true},
+		{"organizador remove co-organizador", 3, 1, nil, true},
+		{"participante sai", 4, 4, nil, true},
+		{"ex-membro remove", 4, 5, repositories.ErrGroupNotFound, false},
+		{"alvo não é membro", 5, 1, repositories.ErrMemberNotFound, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newFakeSettlementService()
+			if err := service.RemoveMember(context.Background(), 1, tt.member, tt.actor); !errors.Is(err, tt.want) {
+				t.Fatalf("erro = %v, esperado %v", err, tt.want)
+			}
+			left := 0
+			for _, m := range repo.members {
+				if m.LeftAt != nil {
+					left++
+				}
+			}
+			if removed := left == 1 && repo.activeMember(tt.member) == nil; removed != tt.removed || left > 1 {
+				t.Fatalf("membro %d removido = %v (saídas: %d), esperado %v", tt.member, removed, left, tt.removed)
+			}
+		})
+	}
+}
+
+func TestRecordPaymentRejects(t *testing.T) {
+	tests := []struct {
+		name    string
+		payment models.SettlementPayment
+		want    error
+	}{
+		{"valor zero", models.SettlementPayment{FromUserID: 2, ToUserID: 1}, ErrInvalidPayment},
+		{"valor negativo", models.SettlementPayment{FromUserID: 2, ToUserID: 1, Amount: -100}, ErrInvalidPayment},
+		{"valor acima do limite", models.SettlementPayment{FromUserID: 2, ToUserID: 1, Amount: models.MaxMoney + 1}, ErrInvalidPayment},
+		{"mesmo membro", models.SettlementPayment{FromUserID: 2, ToUserID: 2, Amount: 100}, ErrInvalidPayment},
+		{"moeda inválida", models.SettlementPayment{FromUserID: 2, ToUserID: 1, Amount: 100, Currency: "REAL"}, ErrInvalidPayment},
+		{"moeda sem câmbio", models.SettlementPayment{FromUserID: 2, ToUserID: 1, Amount: 100, Currency: "JPY"}, repositories.ErrRateNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			service, repo := newFakeSettlementService()
+			tt.payment.TravelGroupID = 1
+			if err := service.RecordPayment(context.Background(), &tt.payment); !errors.Is(err, tt.want) {
+				t.Fatalf("erro = %v, esperado %v", err, tt.want)
+			}
+			if len(repo.payments.payments) != 0 {
+				t.Fatalf("pagamento inválido foi gravado: %+v", repo.payments.payments)
+			}
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package services
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // registra o decodificador de GIF
+	"image/jpeg"
+	_ "image/png" // registra o decodificador de PNG
+)
+
+const (
+	// thumbnailSize é o maior lado da miniatura, em pixels.
+	thumbnailSize = 320
+	// maxImagePixels protege contra imagens pequenas em bytes mas enormes
+	// depois de decodificadas.
+	maxImagePixels = 50_000_000
+	// samplesPerAxis limita quantos pixels da origem entram em cada pixel da
+	// miniatura, para que o custo não cresça com o tamanho da foto.
+	samplesPerAxis = 4
+)
+
+// makeThumbnail gera a miniatura JPEG de imagens JPEG, PNG e GIF. Para os
+// demais tipos (PDF, WebP) devolve nil sem erro.
+func makeThumbnail(contentType string, data []byte) ([]byte, error) {
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, nil
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 || cfg.Width*cfg.Height > maxImagePixels {
+		return nil, fmt.Errorf("imagem de %dx%d pixels fora dos limites", cfg.Width, cfg.Height)
+	}
+
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	if err := jpeg.Encode(&out, downscale(src, thumbnailSize), &jpeg.Options{Quality: 80}); err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// downscale reduz a imagem para caber em maxSide x maxSide, mantendo a
+// proporção. Cada pixel é a média de uma amostra da área correspondente da
+// origem; imagens já pequenas só são copiadas. O fundo transparente vira branco.
+func downscale(src image.Image, maxSide int) image.Image {
+	b := src.Bounds()
+	w, h := b.Dx(), b.Dy()
+	tw, th := w, h
+	if w > maxSide || h > maxSide {
+		if w >= h {
+			tw, th = maxSide, max(1, h*maxSide/w)
+		} else {
+			tw, th = max(1, w*maxSide/h), maxSide
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, tw, th))
+	for y := 0; y < th; y++ {
+		y0, y1 := b.Min.Y+y*h/th, b.Min.Y+max((y+1)*h/th, y*h/th+1)
+		for x := 0; x < tw; x++ {
+			x0, x1 := b.Min.X+x*w/tw, b.Min.X+max((x+1)*w/tw, x*w/tw+1)
+			dst.Set(x, y, averageArea(src, x0, y0, x1, y1))
+		}
+	}
+	return dst
+}
+
+// averageArea tira a média de até samplesPerAxis² pixels do retângulo
+// [x0,x1) x [y0,y1), compondo a transparência sobre branco.
+func averageArea(src image.Image, x0, y0, x1, y1 int) color.RGBA {
+	stepX := max(1, (x1-x0)/samplesPerAxis)
+	stepY := max(1, (y1-y0)/samplesPerAxis)
+
+	var r, g, b, n uint64
+	for y := y0; y < y1; y += stepY {
+		for x := x0; x < x1; x += stepX {
+			cr, cg, cb, ca := src.At(x, y).RGBA()
+			white := 0xffff - uint64(ca)
+			r += uint64(cr) + white
+			g += uint64(cg) + white
+			b += uint64(cb) + white
+			n++
+		}
+	}
+	return color.RGBA{R: uint8(r / n >> 8), G: uint8(g / n >> 8), B: uint8(b / n >> 8), A: 0xff}
+}
@@ -0,0 +1,124 @@
+package services
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+func testImage(w, h int) image.Image {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			img.Set(x, y, color.RGBA{uint8(x), uint8(y), 200, 255})
+		}
+	}
+	return img
+}
+
+func encodePNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, testImage(w, h)); err != nil {
+		t.Fatalf("erro ao gerar PNG: %v", err)
+	}
+	return buf.Bytes()
+}
+
+// pngHeader devolve um PNG que declara w x h pixels no cabeçalho (IHDR), mas
+// cujos dados são os de uma imagem 1x1: basta para DecodeConfig.
+func pngHeader(t *testing.T, w, h uint32) []byte {
+	t.Helper()
+	data := encodePNG(t, 1, 1)
+	// Assinatura (8 bytes), tamanho do chunk (4) e "IHDR" (4): largura e
+	// altura vêm em seguida, e o CRC cobre o tipo e os 13 bytes de dados.
+	binary.BigEndian.PutUint32(data[16:], w)
+	binary.BigEndian.PutUint32(data[20:], h)
+	binary.BigEndian.PutUint32(data[29:], crc32.ChecksumIEEE(data[12:29]))
+	return data
+}
+
+func TestMakeThumbnail(t *testing.T) {
+	var gifData, jpegData bytes.Buffer
+	if err := gif.Encode(&gifData, testImage(10, 10), nil); err != nil {
+		t.Fatalf("erro ao gerar GIF: %v", err)
+	}
+	if err := jpeg.Encode(&jpegData, testImage(400, 800), nil); err != nil {
+		t.Fatalf("erro ao gerar JPEG: %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		contentType string
+		data        []byte
+		width       int // zero: sem miniatura
+		height      int
+	}{
+		{"PNG largo", "image/png", encodePNG(t, 640, 320), 320, 160},
+		{"JPEG alto", "image/jpeg", jpegData.Bytes(), 160, 320},
+		{"GIF pequeno não é ampliado", "image/gif", gifData.Bytes(), 10, 10},
+		{"PDF não tem miniatura", "application/pdf", []byte("%PDF-1.4"), 0, 0},
+		{"WebP não tem miniatura", "image/webp", []byte("RIFF\x00\x00\x00\x00WEBPVP8 "), 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			thumb, err := makeThumbnail(tt.contentType, tt.data)
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if tt.width == 0 {
+				if thumb != nil {
+					t.Fatalf("miniatura gerada para %s", tt.contentType)
+				}
+				return
+			}
+			cfg, format, err := image.DecodeConfig(bytes.NewReader(thumb))
+			if err != nil || format != "jpeg" {
+				t.Fatalf("miniatura inválida: formato %q, erro %v", format, err)
+			}
+			if cfg.Width != tt.width || cfg.Height != tt.height {
+				t.Fatalf("miniatura de %dx%d, esperado %dx%d", cfg.Width, cfg.Height, tt.width, tt.height)
+			}
+		})
+	}
+}
+
+// O limite de pixels é conferido no cabeçalho, antes de decodificar (e alocar)
+// a imagem inteira.
+func TestMakeThumbnailPixelCap(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		overCap  bool
+		contains string
+	}{
+		{"acima do limite", pngHeader(t, 50_001, 1_000), true, "50001x1000 pixels fora dos limites"},
+		{"lado enorme", pngHeader(t, 1<<30, 1), true, "fora dos limites"},
+		// Abaixo do limite, passa do cabeçalho e falha só ao decodificar os dados.
+		{"abaixo do limite", pngHeader(t, 1_000, 1_000), false, ""},
+		{"corrompido", []byte("\x89PNG\r\n\x1a\nlixo"), false, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := makeThumbnail("image/png", tt.data)
+			if err == nil {
+				t.Fatalf("makeThumbnail deveria falhar")
+			}
+			if got := strings.Contains(err.Error(), "fora dos limites"); got != tt.overCap {
+				t.Fatalf("erro = %v, limite de pixels aplicado = %v, esperado %v", err, got, tt.overCap)
+			}
+			if tt.contains != "" && !strings.Contains(err.Error(), tt.contains) {
+				t.Fatalf("erro = %v, esperado conter %q", err, tt.contains)
+			}
+		})
+	}
+}
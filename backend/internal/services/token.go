@@ -0,0 +1,158 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"project_lab/internal/models"
+	"project_lab/internal/repositories"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrInvalidAccessToken indica um token de acesso malformado, expirado ou com assinatura inválida.
+	ErrInvalidAccessToken = errors.New("token inválido ou expirado")
+	// ErrInvalidActionToken indica um link de verificação ou redefinição inválido, expirado ou já usado.
+	ErrInvalidActionToken = errors.New("link inválido ou expirado")
+)
+
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// TokenService emite e valida os tokens de acesso (JWT de vida curta) e os
+// refresh tokens (opacos, guardados no banco e trocados a cada uso).
+type TokenService interface {
+	IssueTokens(ctx context.Context, userID int) (*models.AuthTokens, error)
+	Refresh(ctx context.Context, refreshToken string) (*models.AuthTokens, error)
+	Revoke(ctx context.Context, refreshToken string) error
+	RevokeAll(ctx context.Context, userID int) error
+	ParseAccessToken(tokenString string) (*UserClaims, error)
+	IssueActionToken(userID int, purpose string, ttl time.Duration) (string, error)
+	ConsumeActionToken(ctx context.Context, tokenString, purpose string) (*UserClaims, error)
+}
+
+type tokenService struct {
+	keys          *KeySet
+	refreshRepo   repositories.RefreshTokenRepository
+	usedTokenRepo repositories.UsedTokenRepository
+	accessTTL     time.Duration
+	refreshTTL    time.Duration
+}
+
+// NewTokenService cria o serviço de tokens. TTLs zerados usam os valores padrão.
+func NewTokenService(keys *KeySet, refreshRepo repositories.RefreshTokenRepository, usedTokenRepo repositories.UsedTokenRepository, accessTTL, refreshTTL time.Duration) TokenService {
+	if accessTTL <= 0 {
+		accessTTL = DefaultAccessTokenTTL
+	}
+	if refreshTTL <= 0 {
+		refreshTTL = DefaultRefreshTokenTTL
+	}
+	return &tokenService{keys: keys, refreshRepo: refreshRepo, usedTokenRepo: usedTokenRepo, accessTTL: accessTTL, refreshTTL: refreshTTL}
+}
+
+// IssueTokens inicia uma nova sessão (login): token de acesso e uma nova família de refresh tokens.
+func (s *tokenService) IssueTokens(ctx context.Context, userID int) (*models.AuthTokens, error) {
+	refresh, err := s.refreshRepo.CreateRefreshToken(ctx, userID, time.Now().Add(s.refreshTTL))
+	if err != nil {
+		return nil, err
+	}
+	return s.withAccessToken(userID, refresh)
+}
+
+// Refresh troca o refresh token por um novo par. Reapresentar um token já
+// trocado revoga a sessão inteira (repositories.ErrRefreshTokenReused).
+func (s *tokenService) Refresh(ctx context.Context, refreshToken string) (*models.AuthTokens, error) {
+	userID, next, err := s.refreshRepo.RotateRefreshToken(ctx, refreshToken, time.Now().Add(s.refreshTTL))
+	if err != nil {
+		return nil, err
+	}
+	return s.withAccessToken(userID, next)
+}
+
+// Revoke encerra a sessão do refresh token. Tokens de acesso já emitidos
+// continuam válidos até expirar, por isso a validade deles é curta.
+func (s *tokenService) Revoke(ctx context.Context, refreshToken string) error {
+	return s.refreshRepo.RevokeRefreshTokenFamily(ctx, refreshToken)
+}
+
+// RevokeAll encerra todas as sessões do usuário.
+func (s *tokenService) RevokeAll(ctx context.Context, userID int) error {
+	return s.refreshRepo.RevokeUserRefreshTokens(ctx, userID)
+}
+
+func (s *tokenService) withAccessToken(userID int, refresh string) (*models.AuthTokens, error) {
+	now := time.Now()
+	claims := &UserClaims{
+		UserID: userID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(s.accessTTL)),
+		},
+	}
+
+	token, err := s.keys.Sign(claims)
+	if err != nil {
+		return nil, err
+	}
+	return &models.AuthTokens{
+		Token:        token,
+		RefreshToken: refresh,
+		ExpiresIn:    int(s.accessTTL / time.Second),
+	}, nil
+}
+
+// ParseAccessToken valida assinatura (pelo kid), algoritmo e expiração do token.
+func (s *tokenService) ParseAccessToken(tokenString string) (*UserClaims, error) {
+	claims := &UserClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.keys.Keyfunc, jwt.WithExpirationRequired())
+	// Tokens de verificação e redefinição são assinados com as mesmas chaves,
+	// mas não dão acesso à API.
+	if err != nil || !token.Valid || claims.Purpose != "" {
+		return nil, ErrInvalidAccessToken
+	}
+	return claims, nil
+}
+
+// IssueActionToken assina um token de uso único para a finalidade informada
+// (verificação de e-mail, redefinição de senha), identificado por um jti aleatório.
+func (s *tokenService) IssueActionToken(userID int, purpose string, ttl time.Duration) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	claims := &UserClaims{
+		UserID:  userID,
+		Purpose: purpose,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+		},
+	}
+	return s.keys.Sign(claims)
+}
+
+// ConsumeActionToken valida o token para a finalidade e o marca como usado;
+// uma segunda apresentação do mesmo token falha.
+func (s *tokenService) ConsumeActionToken(ctx context.Context, tokenString, purpose string) (*UserClaims, error) {
+	claims := &UserClaims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, s.keys.Keyfunc, jwt.WithExpirationRequired())
+	if err != nil || !token.Valid || claims.Purpose != purpose || claims.ID == "" {
+		return nil, ErrInvalidActionToken
+	}
+
+	if err := s.usedTokenRepo.ConsumeToken(ctx, claims.ID, claims.ExpiresAt.Time); err != nil {
+		if errors.Is(err, repositories.ErrTokenAlreadyUsed) {
+			return nil, ErrInvalidActionToken
+		}
+		return nil, err
+	}
+	return claims, nil
+}
@@ -0,0 +1,237 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"project_lab/internal/repositories"
+)
+
+// fakeRefreshRepo segue o contrato de RefreshTokenRepository: cada login abre
+// uma família, cada troca consome o token e emite o próximo, e reapresentar um
+// token consumido revoga a família inteira.
+type fakeRefreshRepo struct {
+	next    int
+	tokens  map[string]*fakeRefreshToken
+	revoked map[int]bool
+}
+
+type fakeRefreshToken struct {
+	userID int
+	family int
+	used   bool
+}
+
+func newFakeRefreshRepo() *fakeRefreshRepo {
+	return &fakeRefreshRepo{tokens: map[string]*fakeRefreshToken{}, revoked: map[int]bool{}}
+}
+
+func (f *fakeRefreshRepo) issue(userID, family int) string {
+	f.next++
+	token := fmt.Sprintf("rt-%d", f.next)
+	f.tokens[token] = &fakeRefreshToken{userID: userID, family: family}
+	return token
+}
+
+func (f *fakeRefreshRepo) CreateRefreshToken(_ context.Context, userID int, _ time.Time) (string, error) {
+	return f.issue(userID, f.next+1), nil
+}
+
+func (f *fakeRefreshRepo) RotateRefreshToken(_ context.Context, token string, _ time.Time) (int, string, error) {
+	current, ok := f.tokens[token]
+	if !ok || f.revoked[current.family] {
+		return 0, "", repositories.ErrRefreshTokenInvalid
+	}
+	if current.used {
+		f.revoked[current.family] = true
+		return 0, "", repositories.ErrRefreshTokenReused
+	}
+	current.used = true
+	return current.userID, f.issue(current.userID, current.family), nil
+}
+
+func (f *fakeRefreshRepo) RevokeRefreshTokenFamily(_ context.Context, token string) error {
+	current, ok := f.tokens[token]
+	if !ok {
+		return repositories.ErrRefreshTokenInvalid
+	}
+	f.revoked[current.family] = true
+	return nil
+}
+
+func (f *fakeRefreshRepo) RevokeUserRefreshTokens(_ context.Context, userID int) error {
+	for _, t := range f.tokens {
+		if t.userID == userID {
+			f.revoked[t.family] = true
+		}
+	}
+	return nil
+}
+
+// fakeUsedTokenRepo guarda os jti já consumidos.
+type fakeUsedTokenRepo struct {
+	used map[string]bool
+}
+
+func (f *fakeUsedTokenRepo) ConsumeToken(_ context.Context, jti string, _ time.Time) error {
+	if f.used[jti] {
+		return repositories.ErrTokenAlreadyUsed
+	}
+	f.used[jti] = true
+	return nil
+}
+
+func newTestTokenService(t *testing.T, accessTTL time.Duration) (*tokenService, *fakeRefreshRepo) {
+	t.Helper()
+	refresh := newFakeRefreshRepo()
+	service := NewTokenService(mustKeySet(t, "k1:HS256:"+secretA, ""), refresh, &fakeUsedTokenRepo{used: map[string]bool{}}, 0, 0).(*tokenService)
+	if accessTTL != 0 {
+		service.accessTTL = accessTTL
+	}
+	return service, refresh
+}
+
+func TestParseAccessToken(t *testing.T) {
+	service, _ := newTestTokenService(t, 0)
+	expiredService, _ := newTestTokenService(t, -time.Minute)
+	ctx := context.Background()
+
+	tokens, err := service.IssueTokens(ctx, 7)
+	if err != nil {
+		t.Fatalf("erro ao emitir tokens: %v", err)
+	}
+	expired, err := expiredService.IssueTokens(ctx, 7)
+	if err != nil {
+		t.Fatalf("erro ao emitir tokens: %v", err)
+	}
+	action, err := service.IssueActionToken(7, PurposeResetPassword, time.Hour)
+	if err != nil {
+		t.Fatalf("erro ao emitir token de ação: %v", err)
+	}
+	otherKey, _ := newTestTokenService(t, 0)
+	otherKey.keys = mustKeySet(t, "k1:HS256:"+secretB, "")
+	forged, err := otherKey.IssueTokens(ctx, 7)
+	if err != nil {
+		t.Fatalf("erro ao emitir tokens: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"válido", tokens.Token, true},
+		{"expirado", expired.Token, false},
+		{"token de ação não dá acesso", action, false},
+		{"mesmo kid, outro segredo", forged.Token, false},
+		{"malformado", "abc.def.ghi", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := service.ParseAccessToken(tt.token)
+			if !tt.valid {
+				if !errors.Is(err, ErrInvalidAccessToken) {
+					t.Fatalf("erro = %v, esperado ErrInvalidAccessToken", err)
+				}
+				return
+			}
+			if err != nil || claims.UserID != 7 {
+				t.Fatalf("erro = %v, claims = %+v; esperado usuário 7", err, claims)
+			}
+		})
+	}
+}
+
+// Reapresentar um refresh token já trocado revoga a família: nem o token
+// roubado nem o que o dono legítimo recebeu na troca funcionam mais. Outras
+// sessões do usuário continuam.
+func TestRefreshReuseRevokesFamily(t *testing.T) {
+	service, _ := newTestTokenService(t, 0)
+	ctx := context.Background()
+
+	session, err := service.IssueTokens(ctx, 7)
+	if err != nil {
+		t.Fatalf("erro ao emitir tokens: %v", err)
+	}
+	other, err := service.IssueTokens(ctx, 7)
+	if err != nil {
+		t.Fatalf("erro ao emitir tokens: %v", err)
+	}
+	rotated, err := service.Refresh(ctx, session.RefreshToken)
+	if err != nil {
+		t.Fatalf("erro na primeira troca: %v", err)
+	}
+	if _, err := service.ParseAccessToken(rotated.Token); err != nil {
+		t.Fatalf("token de acesso da troca recusado: %v", err)
+	}
+
+	steps := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{"reuso do token trocado", session.RefreshToken, repositories.ErrRefreshTokenReused},
+		{"token seguinte da família", rotated.RefreshToken, repositories.ErrRefreshTokenInvalid},
+		{"reuso de novo", session.RefreshToken, repositories.ErrRefreshTokenInvalid},
+		{"token desconhecido", "rt-x", repositories.ErrRefreshTokenInvalid},
+	}
+	for _, step := range steps {
+		if _, err := service.Refresh(ctx, step.token); !errors.Is(err, step.wantErr) {
+			t.Fatalf("%s: erro = %v, esperado %v", step.name, err, step.wantErr)
+		}
+	}
+
+	if _, err := service.Refresh(ctx, other.RefreshToken); err != nil {
+		t.Fatalf("outra sessão do usuário foi revogada: %v", err)
+	}
+}
+
+// Tokens de ação valem uma vez e só para a finalidade com que foram emitidos.
+func TestConsumeActionToken(t *testing.T) {
+	service, _ := newTestTokenService(t, 0)
+	ctx := context.Background()
+
+	issue := func(purpose string, ttl time.Duration) string {
+		t.Helper()
+		token, err := service.IssueActionToken(7, purpose, ttl)
+		if err != nil {
+			t.Fatalf("erro ao emitir token de ação: %v", err)
+		}
+		return token
+	}
+	reused := issue(PurposeVerifyEmail, time.Hour)
+	access, err := service.IssueTokens(ctx, 7)
+	if err != nil {
+		t.Fatalf("erro ao emitir tokens: %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		purpose string
+		valid   bool
+	}{
+		{"primeiro uso", reused, PurposeVerifyEmail, true},
+		{"segundo uso", reused, PurposeVerifyEmail, false},
+		{"outra finalidade", issue(PurposeVerifyEmail, time.Hour), PurposeResetPassword, false},
+		{"expirado", issue(PurposeResetPassword, -time.Minute), PurposeResetPassword, false},
+		{"token de acesso", access.Token, PurposeResetPassword, false},
+	}
+
+	for _, tt := range tests {
+		claims, err := service.ConsumeActionToken(ctx, tt.token, tt.purpose)
+		if !tt.valid {
+			if !errors.Is(err, ErrInvalidActionToken) {
+				t.Fatalf("%s: erro = %v, esperado ErrInvalidActionToken", tt.name, err)
+			}
+			continue
+		}
+		if err != nil || claims.UserID != 7 {
+			t.Fatalf("%s: erro = %v, claims = %+v; esperado usuário 7", tt.name, err, claims)
+		}
+	}
+}
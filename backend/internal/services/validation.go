@@ -0,0 +1,110 @@
+package services
+
+import (
+	"net/mail"
+	"project_lab/internal/i18n"
+	"strings"
+	"unicode/utf8"
+)
+
+// FieldError descreve um problema de validação em um campo da requisição. A
+// mensagem é traduzida na resposta, no idioma de quem fez a requisição.
+type FieldError struct {
+	Field   string
+	Message i18n.Message
+}
+
+// ValidationError reúne todos os problemas encontrados em uma requisição, para
+// que o cliente possa exibi-los de uma vez ao lado de cada campo.
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, len(e.Fields))
+	for i, f := range e.Fields {
+		messages[i] = f.Field + ": " + f.Message.In(i18n.Default)
+	}
+	return "dados inválidos: " + strings.Join(messages, "; ")
+}
+
+// Add registra um problema no campo.
+func (e *ValidationError) Add(field string, message i18n.Message) {
+	e.Fields = append(e.Fields, FieldError{Field: field, Message: message})
+}
+
+// OrNil devolve o próprio erro se houver algum problema, ou nil.
+func (e *ValidationError) OrNil() error {
+	if len(e.Fields) == 0 {
+		return nil
+	}
+	return e
+}
+
+const (
+	minNameLength  = 3
+	maxNameLength  = 255
+	maxEmailLength = 255
+)
+
+// NormalizeEmail remove espaços e converte para minúsculas: e-mails são
+// comparados sem diferenciar maiúsculas.
+func NormalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// ValidateEmail confere um e-mail já normalizado: apenas o endereço (sem nome
+// de exibição), com domínio. Devolve nil se o e-mail é aceito.
+func ValidateEmail(email string) *i18n.Message {
+	if email == "" {
+		return message("validation.email_required")
+	}
+	if len(email) > maxEmailLength {
+		return message("validation.email_too_long")
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email {
+		return message("validation.email_invalid")
+	}
+	_, domain, _ := strings.Cut(email, "@")
+	if !strings.Contains(domain, ".") {
+		return message("validation.email_invalid")
+	}
+	return nil
+}
+
+// NormalizeName remove espaços das pontas e colapsa espaços repetidos.
+func NormalizeName(name string) string {
+	return strings.Join(strings.Fields(name), " ")
+}
+
+// ValidateName confere um nome já normalizado. Devolve nil se o nome é aceito.
+func ValidateName(name string) *i18n.Message {
+	length := utf8.RuneCountInString(name)
+	if length == 0 {
+		return message("validation.name_required")
+	}
+	if length < minNameLength {
+		return message("validation.name_too_short", minNameLength)
+	}
+	if length > maxNameLength {
+		return message("validation.name_too_long", maxNameLength)
+	}
+	return nil
+}
+
+// ValidateLocale confere o idioma preferido do usuário; vazio remove a preferência.
+func ValidateLocale(locale string) *i18n.Message {
+	if locale == "" {
+		return nil
+	}
+	if _, ok := i18n.Parse(locale); !ok {
+		return message("validation.locale_unsupported", "pt-BR, en")
+	}
+	return nil
+}
+
+func message(id string, args ...any) *i18n.Message {
+	m := i18n.M(id, args...)
+	return &m
+}
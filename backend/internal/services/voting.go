@@ -0,0 +1,47 @@
+package services
+
+import (
+	"errors"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"time"
+)
+
+// ErrInvalidVoting indica que os dados da votação não respeitam as regras de criação/alteração.
+var ErrInvalidVoting = errors.New("votação inválida")
+
+// ValidateVoting confere pergunta, opções, tipo, limite de escolhas e prazo,
+// preenchendo o tipo padrão ("single") e descartando MaxSelections fora de "multiple".
+func ValidateVoting(voting *models.Voting, now time.Time) error {
+	if voting.Question == "" || len(voting.Options) < 2 {
+		return i18n.Errorf(ErrInvalidVoting, "voting.question_and_options")
+	}
+
+	seenOptions := make(map[string]bool, len(voting.Options))
+	for _, opt := range voting.Options {
+		if opt == "" || seenOptions[opt] {
+			return i18n.Errorf(ErrInvalidVoting, "voting.distinct_options")
+		}
+		seenOptions[opt] = true
+	}
+
+	if voting.ClosesAt != nil && !voting.ClosesAt.After(now) {
+		return i18n.Errorf(ErrInvalidVoting, "voting.deadline_future")
+	}
+
+	if voting.Type == "" {
+		voting.Type = models.VotingTypeSingle
+	}
+	if !models.IsValidVotingType(voting.Type) {
+		return i18n.Errorf(ErrInvalidVoting, "voting.invalid_type")
+	}
+
+	if voting.Type != models.VotingTypeMultiple {
+		voting.MaxSelections = nil
+		return nil
+	}
+	if voting.MaxSelections == nil || *voting.MaxSelections < 1 || *voting.MaxSelections > len(voting.Options) {
+		return i18n.Errorf(ErrInvalidVoting, "voting.invalid_max_selections")
+	}
+	return nil
+}
@@ -0,0 +1,236 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"project_lab/internal/i18n"
+	"project_lab/internal/models"
+	"time"
+)
+
+// ErrInvalidBallot indica que as opções escolhidas não respeitam as regras do tipo de votação.
+var ErrInvalidBallot = errors.New("voto inválido")
+
+// NormalizeBallot valida as opções escolhidas conforme o tipo da votação e
+// retorna a cédula a ser gravada (na ordem informada pelo eleitor).
+func NormalizeBallot(voting *models.Voting, req models.VoteRequest) ([]string, error) {
+	selections := req.SelectedOptions
+	if len(selections) == 0 && req.SelectedOption != "" {
+		selections = []string{req.SelectedOption}
+	}
+	if len(selections) == 0 {
+		return nil, i18n.Errorf(ErrInvalidBallot, "ballot.selection_required")
+	}
+
+	valid := make(map[string]bool, len(voting.Options))
+	for _, opt := range voting.Options {
+		valid[opt] = true
+	}
+	seen := make(map[string]bool, len(selections))
+	for _, sel := range selections {
+		if !valid[sel] {
+			return nil, i18n.Errorf(ErrInvalidBallot, "ballot.unknown_option", sel)
+		}
+		if seen[sel] {
+			return nil, i18n.Errorf(ErrInvalidBallot, "ballot.duplicate_option", sel)
+		}
+		seen[sel] = true
+	}
+
+	switch voting.Type {
+	case models.VotingTypeMultiple:
+		if voting.MaxSelections != nil && len(selections) > *voting.MaxSelections {
+			return nil, i18n.Errorf(ErrInvalidBallot, "ballot.too_many", *voting.MaxSelections)
+		}
+	case models.VotingTypeApproval, models.VotingTypeRanked:
+		// Qualquer quantidade de opções distintas (ranking parcial é permitido).
+	default:
+		if len(selections) != 1 {
+			return nil, i18n.Errorf(ErrInvalidBallot, "ballot.single_only")
+		}
+	}
+
+	return selections, nil
+}
+
+// TallyVoting apura a votação a partir das cédulas de cada eleitor.
+// As opções seguem a ordem em que foram cadastradas e os percentuais têm duas
+// casas decimais. Sem nenhum voto não há vencedor.
+func TallyVoting(voting *models.Voting, ballots [][]string, now time.Time) models.VotingResults {
+	results := models.VotingResults{
+		VotingID: voting.ID,
+		Question: voting.Question,
+		Type:     voting.Type,
+		Status:   voting.Status(now),
+		ClosesAt: voting.ClosesAt,
+		ClosedAt: voting.ClosedAt,
+		Winners:  []string{},
+	}
+
+	valid := make(map[string]bool, len(voting.Options))
+	for _, opt := range voting.Options {
+		valid[opt] = true
+	}
+
+	// Opções que não existem mais são descartadas; cédulas vazias não contam.
+	cleaned := make([][]string, 0, len(ballots))
+	for _, ballot := range ballots {
+		var kept []string
+		for _, sel := range ballot {
+			if valid[sel] {
+				kept = append(kept, sel)
+			}
+		}
+		if len(kept) > 0 {
+			cleaned = append(cleaned, kept)
+		}
+	}
+	results.TotalVotes = len(cleaned)
+
+	if voting.Type == models.VotingTypeRanked {
+		tallyRanked(&results, voting.Options, cleaned)
+		return results
+	}
+
+	// single, multiple e approval: cada opção marcada vale um voto.
+	counts := map[string]int{}
+	for _, ballot := range cleaned {
+		for _, sel := range ballot {
+			counts[sel]++
+		}
+	}
+	results.Options = optionResults(voting.Options, counts, results.TotalVotes)
+	results.Winners = topOptions(results.Options)
+	results.IsTie = len(results.Winners) > 1
+
+	return results
+}
+
+// tallyRanked aplica o segundo turno instantâneo: a cada rodada conta a
+// primeira preferência ainda na disputa de cada cédula; vence quem tiver
+// maioria absoluta das cédulas ativas. Senão, é eliminada a opção com menos
+// votos; empates na lanterna são desfeitos pela opção menos citada nas
+// cédulas (em qualquer posição) e, persistindo, todas as empatadas saem.
+// Se todas as restantes empatarem, todas vencem (empate).
+func tallyRanked(results *models.VotingResults, options []string, ballots [][]string) {
+	remaining := make([]string, len(options))
+	copy(remaining, options)
+	results.Rounds = []models.RunoffRound{}
+
+	mentions := map[string]int{}
+	for _, ballot := range ballots {
+		for _, sel := range ballot {
+			mentions[sel]++
+		}
+	}
+
+	for round := 1; len(remaining) > 0; round++ {
+		inRace := make(map[string]bool, len(remaining))
+		for _, opt := range remaining {
+			inRace[opt] = true
+		}
+
+		counts := map[string]int{}
+		active := 0
+		for _, ballot := range ballots {
+			for _, sel := range ballot {
+				if inRace[sel] {
+					counts[sel]++
+					active++
+					break
+				}
+			}
+		}
+
+		current := models.RunoffRound{
+			Round:      round,
+			Options:    optionResults(remaining, counts, active),
+			Eliminated: []string{},
+		}
+		if round == 1 {
+			results.Options = optionResults(options, counts, active)
+		}
+
+		if active == 0 {
+			results.Rounds = append(results.Rounds, current)
+			return
+		}
+
+		for _, r := range current.Options {
+			if r.Votes*2 > active {
+				results.Rounds = append(results.Rounds, current)
+				results.Winners = []string{r.Option}
+				return
+			}
+		}
+
+		minVotes := math.MaxInt
+		for _, r := range current.Options {
+			minVotes = min(minVotes, r.Votes)
+		}
+		minMentions := math.MaxInt
+		for _, r := range current.Options {
+			if r.Votes == minVotes {
+				minMentions = min(minMentions, mentions[r.Option])
+			}
+		}
+
+		var survivors []string
+		for _, r := range current.Options {
+			if r.Votes == minVotes && mentions[r.Option] == minMentions {
+				current.Eliminated = append(current.Eliminated, r.Option)
+			} else {
+				survivors = append(survivors, r.Option)
+			}
+		}
+
+		if len(survivors) == 0 {
+			// Empate entre todas as opções restantes.
+			current.Eliminated = []string{}
+			results.Rounds = append(results.Rounds, current)
+			results.Winners = remaining
+			results.IsTie = len(remaining) > 1
+			return
+		}
+
+		results.Rounds = append(results.Rounds, current)
+		remaining = survivors
+	}
+}
+
+// optionResults monta a contagem e o percentual (sobre total) de cada opção.
+func optionResults(options []string, counts map[string]int, total int) []models.OptionResult {
+	out := make([]models.OptionResult, 0, len(options))
+	for _, option := range options {
+		votes := counts[option]
+		percentage := 0.0
+		if total > 0 {
+			percentage = math.Round(float64(votes)*10000/float64(total)) / 100
+		}
+		out = append(out, models.OptionResult{
+			Option:     option,
+			Votes:      votes,
+			Percentage: percentage,
+		})
+	}
+	return out
+}
+
+// topOptions retorna as opções com a maior contagem (nenhuma se não houver votos).
+func topOptions(results []models.OptionResult) []string {
+	maxVotes := 0
+	for _, r := range results {
+		maxVotes = max(maxVotes, r.Votes)
+	}
+
+	winners := []string{}
+	if maxVotes == 0 {
+		return winners
+	}
+	for _, r := range results {
+		if r.Votes == maxVotes {
+			winners = append(winners, r.Option)
+		}
+	}
+	return winners
+}
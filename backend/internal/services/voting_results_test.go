@@ -0,0 +1,257 @@
+package services
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	"project_lab/internal/models"
+)
+
+func repeat(n int, ballot ...string) [][]string {
+	ballots := make([][]string, n)
+	for i := range ballots {
+		ballots[i] = ballot
+	}
+	return ballots
+}
+
+func concat(groups ...[][]string) [][]string {
+	var all [][]string
+	for _, g := range groups {
+		all = append(all, g...)
+	}
+	return all
+}
+
+// roundSummary resume uma rodada como votos por opção e eliminadas.
+type roundSummary struct {
+	votes      map[string]int
+	eliminated []string
+}
+
+func summarizeRounds(rounds []models.RunoffRound) []roundSummary {
+	out := make([]roundSummary, len(rounds))
+	for i, r := range rounds {
+		out[i] = roundSummary{votes: map[string]int{}, eliminated: r.Eliminated}
+		for _, o := range r.Options {
+			out[i].votes[o.Option] = o.Votes
+		}
+	}
+	return out
+}
+
+func TestTallyRanked(t *testing.T) {
+	options := []string{"A", "B", "C"}
+	none := []string{}
+	tests := []struct {
+		name       string
+		ballots    [][]string
+		wantTotal  int
+		wantWinner []string
+		wantTie    bool
+		wantRounds []roundSummary
+	}{
+		{
+			name:       "maioria na primeira rodada",
+			ballots:    concat(repeat(3, "A", "B"), repeat(2, "B")),
+			wantTotal:  5,
+			wantWinner: []string{"A"},
+			wantRounds: []roundSummary{{votes: map[string]int{"A": 3, "B": 2, "C": 0}, eliminated: none}},
+		},
+		{
+			name: "votos transferidos depois da eliminação",
+			// C é a última; as cédulas dela passam para B, que vira maioria.
+			ballots:    concat(repeat(3, "A"), repeat(2, "B", "A"), repeat(2, "C", "B")),
+			wantTotal:  7,
+			wantWinner: []string{"B"},
+			wantRounds: []roundSummary{
+				{votes: map[string]int{"A": 3, "B": 2, "C": 2}, eliminated: []string{"C"}},
+				{votes: map[string]int{"A": 3, "B": 4}, eliminated: none},
+			},
+		},
+		{
+			name: "empate na lanterna desfeito por menções",
+			// B e C têm 2 votos, mas C aparece em mais cédulas: sai só B, e as
+			// cédulas de B, que não citam mais ninguém, se esgotam.
+			ballots:    concat(repeat(3, "A"), repeat(1, "A", "C"), repeat(2, "B"), repeat(2, "C", "A")),
+			wantTotal:  8,
+			wantWinner: []string{"A"},
+			wantRounds: []roundSummary{
+				{votes: map[string]int{"A": 4, "B": 2, "C": 2}, eliminated: []string{"B"}},
+				{votes: map[string]int{"A": 4, "C": 2}, eliminated: none},
+			},
+		},
+		{
+			name: "cédulas esgotadas reduzem a maioria",
+			// B e C empatam também em menções e saem juntas; as cédulas delas
+			// não citam A e deixam de contar.
+			ballots:    concat(repeat(2, "A"), repeat(1, "B", "C"), repeat(1, "C", "B")),
+			wantTotal:  4,
+			wantWinner: []string{"A"},
+			wantRounds: []roundSummary{
+				{votes: map[string]int{"A": 2, "B": 1, "C": 1}, eliminated: []string{"B", "C"}},
+				{votes: map[string]int{"A": 2}, eliminated: none},
+			},
+		},
+		{
+			name:       "empate entre todas as restantes",
+			ballots:    [][]string{{"A"}, {"B"}},
+			wantTotal:  2,
+			wantWinner: []string{"A", "B"},
+			wantTie:    true,
+			wantRounds: []roundSummary{
+				{votes: map[string]int{"A": 1, "B": 1, "C": 0}, eliminated: []string{"C"}},
+				{votes: map[string]int{"A": 1, "B": 1}, eliminated: none},
+			},
+		},
+		{
+			name:       "sem votos",
+			ballots:    nil,
+			wantTotal:  0,
+			wantWinner: []string{},
+			wantRounds: []roundSummary{{votes: map[string]int{"A": 0, "B": 0, "C": 0}, eliminated: none}},
+		},
+		{
+			name: "opções removidas e cédulas vazias são descartadas",
+			ballots: [][]string{
+				{"X", "A"},
+				{"X"},
+				{},
+				{"B", "Y"},
+				{"A"},
+			},
+			wantTotal:  3,
+			wantWinner: []string{"A"},
+			wantRounds: []roundSummary{{votes: map[string]int{"A": 2, "B": 1, "C": 0}, eliminated: none}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voting := &models.Voting{ID: 1, Options: options, Type: models.VotingTypeRanked}
+			got := TallyVoting(voting, tt.ballots, time.Now())
+
+			if got.TotalVotes != tt.wantTotal {
+				t.Fatalf("total = %d, esperado %d", got.TotalVotes, tt.wantTotal)
+			}
+			if !reflect.DeepEqual(got.Winners, tt.wantWinner) || got.IsTie != tt.wantTie {
+				t.Fatalf("vencedores = %v (empate %v), esperado %v (empate %v)", got.Winners, got.IsTie, tt.wantWinner, tt.wantTie)
+			}
+			if rounds := summarizeRounds(got.Rounds); !reflect.DeepEqual(rounds, tt.wantRounds) {
+				t.Fatalf("rodadas = %+v, esperado %+v", rounds, tt.wantRounds)
+			}
+		})
+	}
+}
+
+func TestTallyPlurality(t *testing.T) {
+	options := []string{"Praia", "Serra", "Cidade"}
+	tests := []struct {
+		name        string
+		votingType  string
+		ballots     [][]string
+		wantVotes   []int
+		wantPercent []float64
+		wantWinners []string
+		wantTie     bool
+	}{
+		{
+			name:        "maioria simples",
+			votingType:  models.VotingTypeSingle,
+			ballots:     [][]string{{"Praia"}, {"Serra"}, {"Praia"}},
+			wantVotes:   []int{2, 1, 0},
+			wantPercent: []float64{66.67, 33.33, 0},
+			wantWinners: []string{"Praia"},
+		},
+		{
+			name:        "empate",
+			votingType:  models.VotingTypeSingle,
+			ballots:     [][]string{{"Praia"}, {"Serra"}},
+			wantVotes:   []int{1, 1, 0},
+			wantPercent: []float64{50, 50, 0},
+			wantWinners: []string{"Praia", "Serra"},
+			wantTie:     true,
+		},
+		{
+			name:        "aprovação conta cada opção marcada",
+			votingType:  models.VotingTypeApproval,
+			ballots:     [][]string{{"Praia", "Serra"}, {"Serra", "Cidade"}, {"Serra"}},
+			wantVotes:   []int{1, 3, 1},
+			wantPercent: []float64{33.33, 100, 33.33},
+			wantWinners: []string{"Serra"},
+		},
+		{
+			name:        "sem votos não há vencedor",
+			votingType:  models.VotingTypeMultiple,
+			ballots:     [][]string{{"Removida"}},
+			wantVotes:   []int{0, 0, 0},
+			wantPercent: []float64{0, 0, 0},
+			wantWinners: []string{},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			voting := &models.Voting{Options: options, Type: tt.votingType}
+			got := TallyVoting(voting, tt.ballots, time.Now())
+
+			var votes []int
+			var percent []float64
+			for _, o := range got.Options {
+				votes = append(votes, o.Votes)
+				percent = append(percent, o.Percentage)
+			}
+			if !reflect.DeepEqual(votes, tt.wantVotes) || !reflect.DeepEqual(percent, tt.wantPercent) {
+				t.Fatalf("votos = %v %v, esperado %v %v", votes, percent, tt.wantVotes, tt.wantPercent)
+			}
+			if !reflect.DeepEqual(got.Winners, tt.wantWinners) || got.IsTie != tt.wantTie {
+				t.Fatalf("vencedores = %v (empate %v), esperado %v (empate %v)", got.Winners, got.IsTie, tt.wantWinners, tt.wantTie)
+			}
+			if got.Rounds != nil {
+				t.Fatalf("rodadas só existem em votações ranked: %+v", got.Rounds)
+			}
+		})
+	}
+}
+
+func TestNormalizeBallot(t *testing.T) {
+	two := 2
+	options := []string{"A", "B", "C"}
+	tests := []struct {
+		name    string
+		voting  models.Voting
+		req     models.VoteRequest
+		want    []string
+		wantErr bool
+	}{
+		{name: "campo antigo de opção única", voting: models.Voting{Type: models.VotingTypeSingle}, req: models.VoteRequest{SelectedOption: "A"}, want: []string{"A"}},
+		{name: "single com duas opções", voting: models.Voting{Type: models.VotingTypeSingle}, req: models.VoteRequest{SelectedOptions: []string{"A", "B"}}, wantErr: true},
+		{name: "sem opção", voting: models.Voting{Type: models.VotingTypeSingle}, req: models.VoteRequest{}, wantErr: true},
+		{name: "opção desconhecida", voting: models.Voting{Type: models.VotingTypeApproval}, req: models.VoteRequest{SelectedOptions: []string{"A", "Z"}}, wantErr: true},
+		{name: "opção repetida", voting: models.Voting{Type: models.VotingTypeRanked}, req: models.VoteRequest{SelectedOptions: []string{"A", "A"}}, wantErr: true},
+		{name: "multiple dentro do limite", voting: models.Voting{Type: models.VotingTypeMultiple, MaxSelections: &two}, req: models.VoteRequest{SelectedOptions: []string{"C", "A"}}, want: []string{"C", "A"}},
+		{name: "multiple acima do limite", voting: models.Voting{Type: models.VotingTypeMultiple, MaxSelections: &two}, req: models.VoteRequest{SelectedOptions: []string{"A", "B", "C"}}, wantErr: true},
+		{name: "ranking parcial mantém a ordem", voting: models.Voting{Type: models.VotingTypeRanked}, req: models.VoteRequest{SelectedOptions: []string{"C", "A"}}, want: []string{"C", "A"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.voting.Options = options
+			got, err := NormalizeBallot(&tt.voting, tt.req)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidBallot) {
+					t.Fatalf("erro = %v, esperado ErrInvalidBallot", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("erro inesperado: %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("cédula = %v, esperado %v", got, tt.want)
+			}
+		})
+	}
+}
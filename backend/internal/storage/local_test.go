@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestLocalStore(t *testing.T) {
+	dir := t.TempDir()
+	blobs, err := NewLocalStore(filepath.Join(dir, "uploads"))
+	if err != nil {
+		t.Fatalf("erro ao criar LocalStore: %v", err)
+	}
+	ctx := context.Background()
+
+	if err := blobs.Put(ctx, "expenses/12/ab34", strings.NewReader("conteúdo"), 0, "application/pdf"); err != nil {
+		t.Fatalf("erro ao gravar: %v", err)
+	}
+
+	body, err := blobs.Get(ctx, "expenses/12/ab34")
+	if err != nil {
+		t.Fatalf("erro ao ler: %v", err)
+	}
+	data, _ := io.ReadAll(body)
+	body.Close()
+	if string(data) != "conteúdo" {
+		t.Fatalf("conteúdo lido = %q, esperado %q", data, "conteúdo")
+	}
+
+	// Nenhum arquivo temporário fica para trás.
+	entries, err := os.ReadDir(filepath.Join(dir, "uploads", "expenses", "12"))
+	if err != nil || len(entries) != 1 {
+		t.Fatalf("arquivos no diretório = %v (%v), esperado só ab34", entries, err)
+	}
+
+	if err := blobs.Delete(ctx, "expenses/12/ab34"); err != nil {
+		t.Fatalf("erro ao apagar: %v", err)
+	}
+	if _, err := blobs.Get(ctx, "expenses/12/ab34"); !errors.Is(err, ErrBlobNotFound) {
+		t.Fatalf("erro ao ler arquivo apagado = %v, esperado ErrBlobNotFound", err)
+	}
+	if err := blobs.Delete(ctx, "expenses/12/ab34"); err != nil {
+		t.Fatalf("erro ao apagar arquivo inexistente: %v", err)
+	}
+}
+
+// Chaves que sairiam do diretório são recusadas antes de tocar no disco.
+func TestLocalStoreRejectsPathTraversal(t *testing.T) {
+	dir := t.TempDir()
+	secret := filepath.Join(dir, "segredo")
+	if err := os.WriteFile(secret, []byte("não leia"), 0o600); err != nil {
+		t.Fatalf("erro ao criar arquivo: %v", err)
+	}
+	blobs, err := NewLocalStore(filepath.Join(dir, "uploads"))
+	if err != nil {
+		t.Fatalf("erro ao criar LocalStore: %v", err)
+	}
+	ctx := context.Background()
+
+	for _, key := range []string{"../segredo", "expenses/../../segredo", secret} {
+		if _, err := blobs.Get(ctx, key); err == nil {
+			t.Fatalf("Get(%q) deveria falhar", key)
+		}
+		if err := blobs.Put(ctx, key, strings.NewReader("x"), 1, "text/plain"); err == nil {
+			t.Fatalf("Put(%q) deveria falhar", key)
+		}
+		if err := blobs.Delete(ctx, key); err == nil {
+			t.Fatalf("Delete(%q) deveria falhar", key)
+		}
+	}
+
+	data, err := os.ReadFile(secret)
+	if err != nil || string(data) != "não leia" {
+		t.Fatalf("arquivo fora do diretório foi alterado: %q (%v)", data, err)
+	}
+}
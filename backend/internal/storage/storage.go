@@ -0,0 +1,37 @@
+// Package storage guarda arquivos enviados pelos usuários (como os
+// comprovantes das despesas) fora do banco. Os backends (diretório local ou um
+// serviço compatível com a API do S3, como MinIO) usam as mesmas chaves, então
+// trocar de um para o outro só exige copiar os arquivos.
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrBlobNotFound indica que não há arquivo gravado com a chave.
+var ErrBlobNotFound = errors.New("arquivo não encontrado no armazenamento")
+
+// BlobStore grava, lê e apaga arquivos por chave. As chaves são caminhos
+// relativos com "/" (ex.: "expenses/12/ab34...").
+type BlobStore interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	Delete(ctx context.Context, key string) error
+}
+
+// validKey rejeita chaves vazias, absolutas ou com segmentos "." e "..", que
+// poderiam escapar do diretório ou do bucket.
+func validKey(key string) bool {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return false
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return false
+		}
+	}
+	return true
+}
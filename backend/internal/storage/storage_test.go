@@ -0,0 +1,32 @@
+package storage
+
+import "testing"
+
+func TestValidKey(t *testing.T) {
+	tests := []struct {
+		key  string
+		want bool
+	}{
+		{"expenses/12/ab34", true},
+		{"expenses/12/ab34_thumb.jpg", true},
+		{"a", true},
+		{"..a/b..", true},
+		{"", false},
+		{"/etc/passwd", false},
+		{"../segredo", false},
+		{"expenses/../../segredo", false},
+		{"expenses/12/..", false},
+		{"expenses/./12", false},
+		{".", false},
+		{"expenses//12", false},
+		{"expenses/12/", false},
+		{`expenses\..\..\segredo`, false},
+		{`C:\segredo`, false},
+	}
+
+	for _, tt := range tests {
+		if got := validKey(tt.key); got != tt.want {
+			t.Fatalf("validKey(%q) = %v, esperado %v", tt.key, got, tt.want)
+		}
+	}
+}
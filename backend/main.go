@@ -1,134 +1,59 @@
 package main
 
 import (
+	"context"
+	"database/sql"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
 	"project_lab/internal/handlers"
 	"project_lab/internal/middleware"
+	"project_lab/internal/migrations"
+	"project_lab/internal/openapi"
 	"project_lab/internal/repositories"
+	"project_lab/internal/router"
 	"project_lab/internal/services"
-	"strings"
 
 	"github.com/joho/godotenv"
 	"github.com/rs/cors"
 )
 
-func profileRouter(h *handlers.ProfileHandler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		path := r.URL.Path
-
-		if path == "/profile" {
-			switch r.Method {
-			case "GET":
-				h.GetProfileHandler(w, r)
-			case "PATCH":
-				h.UpdateProfileHandler(w, r)
-			default:
-				http.Error(w, "Método não permitido para /profile.", http.StatusMethodNotAllowed)
-			}
-			return
+// runCommand executa um subcomando de linha de comando em vez de subir o servidor.
+func runCommand(args []string, db *sql.DB, migrator *migrations.Migrator) {
+	switch args[0] {
+	case "migrate":
+		runMigrateCommand(args[1:], migrator)
+	case "import-rates":
+		if len(args) != 2 {
+			log.Fatal("Uso: import-rates <arquivo.csv>")
 		}
+		applyMigrations(migrator)
+		exchangeRateService := services.NewExchangeRateService(repositories.NewExchangeRateRepository(db))
 
-		http.NotFound(w, r)
-	}
-}
-
-func groupsRouter(h *handlers.TravelGroupHandler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-
-		pathSegments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-
-		if len(pathSegments) == 1 && pathSegments[0] == "groups" {
-			switch r.Method {
-			case "GET":
-				h.ListGroups(w, r)
-			case "POST":
-				h.CreateGroupHandler(w, r)
-			default:
-				http.Error(w, "Método não permitido para /groups", http.StatusMethodNotAllowed)
-			}
-			return
+		file, err := os.Open(args[1])
+		if err != nil {
+			log.Fatalf("Erro ao abrir arquivo de câmbio: %v", err)
 		}
+		defer file.Close()
 
-		if len(pathSegments) >= 2 && pathSegments[0] == "groups" {
-			groupIDStr := pathSegments[1]
-
-			if len(pathSegments) == 3 {
-				resource := pathSegments[2]
-
-				switch resource {
-				case "members":
-					if r.Method == "GET" {
-						h.ListGroupMembersHandler(w, r, groupIDStr)
-						return
-					}
-				case "destinations":
-					switch r.Method {
-					case "GET":
-						h.ListGroupDestinationsHandler(w, r, groupIDStr)
-					case "POST":
-						h.CreateDestinationHandler(w, r, groupIDStr)
-					default:
-						http.Error(w, "Método não permitido para /destinations", http.StatusMethodNotAllowed)
-					}
-					return
-				case "votings":
-					switch r.Method {
-					case "GET":
-						h.ListGroupVotingsHandler(w, r, groupIDStr)
-					case "POST":
-						h.CreateVotingHandler(w, r, groupIDStr)
-					default:
-						http.Error(w, "Método não permitido para /votings", http.StatusMethodNotAllowed)
-					}
-					return
-				case "expenses":
-					if r.Method == "GET" {
-						h.ListGroupExpensesHandler(w, r, groupIDStr)
-						return
-					}
-					// A lógica de POST para expenses (futuro) entraria aqui
-				}
-				http.Error(w, "Recurso ou Método não permitido.", http.StatusMethodNotAllowed)
-				return
-			}
-
-			if len(pathSegments) == 2 {
-				if r.Method == "GET" {
-					h.GetGroupDetailsWithID(w, r, groupIDStr)
-					return
-				}
-				http.Error(w, "Método não permitido para detalhes do grupo", http.StatusMethodNotAllowed)
-				return
-			}
+		count, err := exchangeRateService.ImportCSV(context.Background(), file)
+		if err != nil {
+			log.Fatalf("Erro ao importar taxas de câmbio: %v", err)
 		}
-
-		http.NotFound(w, r)
+		fmt.Printf("%d taxas de câmbio importadas.\n", count)
+	default:
+		log.Fatalf("Comando desconhecido: %s", args[0])
 	}
 }
 
-func votingsRouter(h *handlers.VoteHandler) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-
-		pathSegments := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
-
-		// Esperamos a rota /votings/{id}/vote
-		if len(pathSegments) == 3 && pathSegments[0] == "votings" && pathSegments[2] == "vote" {
-			votingIDStr := pathSegments[1]
-
-			if r.Method == "POST" {
-				h.VoteHandler(w, r, votingIDStr)
-				return
-			}
-		}
-
-		http.NotFound(w, r)
+func main() {
+	// "openapi" só imprime o documento da API; não precisa de .env nem de banco.
+	if len(os.Args) == 2 && os.Args[1] == "openapi" {
+		writeOpenAPI(os.Stdout)
+		return
 	}
-}
 
-func main() {
 	err := godotenv.Load()
 	if err != nil {
 		log.Fatalf("Erro ao carregar o arquivo .env: %v", err)
@@ -141,37 +66,117 @@ func main() {
 
 	db := connectDB()
 	defer db.Close()
-	createTables(db)
+
+	migrator, err := migrations.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("Erro ao carregar migrações: %v", err)
+	}
+
+	// Subcomandos de manutenção: "migrate up|down [n]|status" gerencia o schema e
+	// "import-rates <arquivo.csv>" carrega a tabela de câmbio.
+	if len(os.Args) > 1 {
+		runCommand(os.Args[1:], db, migrator)
+		return
+	}
+
+	applyMigrations(migrator)
+
+	exchangeRateRepo := repositories.NewExchangeRateRepository(db)
+	exchangeRateService := services.NewExchangeRateService(exchangeRateRepo)
 
 	// Inicializa as camadas da aplicação, injetando as dependências.
+	refreshTokenRepo := repositories.NewRefreshTokenRepository(db)
+	usedTokenRepo := repositories.NewUsedTokenRepository(db)
+	tokenService := loadTokenService(refreshTokenRepo, usedTokenRepo)
+
+	limiter, rateLimits, trustedProxies := loadRateLimiting()
+	rateLimit := func(name string) func(http.Handler) http.Handler {
+		return middleware.RateLimit(limiter, rateLimits[name])
+	}
+
 	userRepo := repositories.NewUserRepository(db)
-	authService := services.NewAuthService(userRepo)
-	authHandler := handlers.NewAuthHandler(authService)
-	profileHandler := handlers.NewProfileHandler(userRepo)
+
+	// Nas rotas autenticadas, o idioma preferido do usuário vale mais que o
+	// Accept-Language.
+	authenticate := middleware.AuthMiddleware(tokenService)
+	preferredLocale := middleware.PreferredLocale(userRepo.GetPreferredLocale)
+	requireAuth := func(next http.Handler) http.Handler {
+		return authenticate(preferredLocale(next))
+	}
+
+	loginAttemptRepo := repositories.NewLoginAttemptRepository(db)
+	mailer := loadMailer()
+	authService := services.NewAuthService(userRepo, tokenService, mailer, frontendURL, loadPasswordPolicy(), loginAttemptRepo)
+	authHandler := handlers.NewAuthHandler(authService, tokenService)
+	profileHandler := handlers.NewProfileHandler(userRepo, loginAttemptRepo)
 
 	travelGroupsRepo := repositories.NewTravelGroupRepository(db)
-	travelGroupsHandler := handlers.NewTravelGroupHandler(travelGroupsRepo)
+	settlementService := services.NewSettlementService(travelGroupsRepo, repositories.NewSettlementPaymentRepository(db), exchangeRateService)
+	blobStore, attachmentMaxSize := loadBlobStore()
+	attachmentService := services.NewAttachmentService(repositories.NewAttachmentRepository(db), blobStore, attachmentMaxSize)
+	attachmentHandler := handlers.NewAttachmentHandler(attachmentService, travelGroupsRepo)
+	budgetService := services.NewBudgetService(repositories.NewBudgetRepository(db), travelGroupsRepo, userRepo, exchangeRateService, mailer)
+	travelGroupsHandler := handlers.NewTravelGroupHandler(travelGroupsRepo, exchangeRateService, userRepo, settlementService, budgetService, attachmentService, boolEnv("REQUIRE_VERIFIED_EMAIL"))
+	budgetHandler := handlers.NewBudgetHandler(budgetService, travelGroupsRepo)
+
+	settlementHandler := handlers.NewSettlementHandler(settlementService, travelGroupsRepo)
+
+	inviteRepo := repositories.NewInviteRepository(db)
+	inviteHandler := handlers.NewInviteHandler(inviteRepo, travelGroupsRepo)
+
+	itineraryRepo := repositories.NewItineraryRepository(db)
+	itineraryHandler := handlers.NewItineraryHandler(itineraryRepo, travelGroupsRepo)
+
+	calendarFeedRepo := repositories.NewCalendarFeedRepository(db)
+	calendarService := services.NewCalendarService(travelGroupsRepo, itineraryRepo, userRepo)
+	calendarHandler := handlers.NewCalendarHandler(calendarService, travelGroupsRepo, calendarFeedRepo, os.Getenv("API_URL"))
 
 	voteRepo := repositories.NewVoteRepository(db)
 	voteHandler := handlers.NewVoteHandler(voteRepo, travelGroupsRepo) // Passa travelGroupsRepo para validações
 
-	mux := http.NewServeMux()
-
-	mux.HandleFunc("/auth/register", authHandler.RegisterUserHandler)
-	mux.Handle("/auth/login", middleware.RateLimitMiddleware(http.HandlerFunc(authHandler.LoginUserHandler)))
-	mux.Handle("/profile", middleware.AuthMiddleware(profileRouter(profileHandler)))
-	mux.Handle("/groups/", middleware.AuthMiddleware(groupsRouter(travelGroupsHandler)))
-	mux.Handle("/groups", middleware.AuthMiddleware(groupsRouter(travelGroupsHandler)))
-	mux.Handle("/votings/", middleware.AuthMiddleware(votingsRouter(voteHandler)))
+	api := router.New(requireAuth, rateLimit)
+	api.Handle(routes(apiHandlers{
+		auth:        authHandler,
+		profile:     profileHandler,
+		groups:      travelGroupsHandler,
+		invites:     inviteHandler,
+		settlements: settlementHandler,
+		budgets:     budgetHandler,
+		attachments: attachmentHandler,
+		itinerary:   itineraryHandler,
+		calendar:    calendarHandler,
+		votes:       voteHandler,
+	})...)
+
+	// O documento OpenAPI é gerado da própria tabela de rotas; as rotas da
+	// documentação ficam de fora dele.
+	spec := apiDocument(api.Routes())
+	api.Handle(
+		router.Route{Method: http.MethodGet, Pattern: "/openapi.json", Handler: openapi.JSONHandler(spec)},
+		router.Route{Method: http.MethodGet, Pattern: "/docs", Handler: openapi.UIHandler(spec.Info.Title, "/openapi.json")},
+	)
+
+	// Com OPENAPI_VALIDATE=true, toda requisição e resposta é conferida contra o
+	// documento e as divergências vão para o log (útil em desenvolvimento).
+	var handler http.Handler = api
+	if boolEnv("OPENAPI_VALIDATE") {
+		handler = openapi.Validator(spec, func(err error) {
+			fmt.Printf("Divergência do contrato OpenAPI: %v\n", err)
+		})(api)
+	}
 
 	// Configuração do middleware CORS
 	c := cors.New(cors.Options{
 		AllowedOrigins:   []string{frontendURL},
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"Content-Type", "Authorization"},
+		AllowedMethods:   []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders:   []string{"Content-Type", "Authorization", "Accept-Language"},
+		ExposedHeaders:   []string{"RateLimit-Policy", "RateLimit-Limit", "RateLimit-Remaining", "RateLimit-Reset", "Retry-After", "Content-Language"},
 		AllowCredentials: true,
 	})
-	handlerWithCORS := c.Handler(mux)
+	// RealIP fica por fora de tudo para que os limites e a auditoria de login
+	// vejam o IP do cliente, e não o do proxy reverso. Locale vem logo em
+	// seguida para que até os erros de CORS e de rota saiam no idioma pedido.
+	handlerWithCORS := middleware.RealIP(trustedProxies)(middleware.Locale(c.Handler(handler)))
 
 	fmt.Println("🚀 Servidor rodando em http://localhost:8080")
 	if err := http.ListenAndServe(":8080", handlerWithCORS); err != nil {
@@ -0,0 +1,257 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"project_lab/internal/openapi"
+	"project_lab/internal/router"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+// O docs/openapi.json versionado precisa ser o gerado pela tabela de rotas;
+// depois de mudar rotas ou modelos, rode "go run . openapi > docs/openapi.json".
+func TestOpenAPIDocumentIsUpToDate(t *testing.T) {
+	committed, err := os.ReadFile("docs/openapi.json")
+	if err != nil {
+		t.Fatalf("erro ao ler docs/openapi.json: %v", err)
+	}
+	var generated bytes.Buffer
+	writeOpenAPI(&generated)
+
+	if !bytes.Equal(committed, generated.Bytes()) {
+		t.Fatal("docs/openapi.json desatualizado: rode \"go run . openapi > docs/openapi.json\"")
+	}
+}
+
+var routeParam = regexp.MustCompile(`\{([^}]+)\}`)
+
+// samplePath preenche os parâmetros do padrão: IDs com id, os demais com um
+// texto válido para a rota.
+func samplePath(pattern, id string) string {
+	return routeParam.ReplaceAllStringFunc(pattern, func(param string) string {
+		name := strings.Trim(param, "{}")
+		switch {
+		case strings.HasSuffix(name, "Id"):
+			return id
+		case name == "feed":
+			return "token.ics"
+		default:
+			return "abc"
+		}
+	})
+}
+
+// sample devolve um valor do tipo com todos os campos preenchidos (slices e
+// maps com um elemento, ponteiros apontando para valores), para que o JSON
+// escrito pelos handlers percorra todo o schema.
+func sample(t reflect.Type) reflect.Value {
+	v := reflect.New(t).Elem()
+	fill(v, 0)
+	return v
+}
+
+func fill(v reflect.Value, depth int) {
+	if depth > 6 {
+		return
+	}
+	switch v.Type() {
+	case reflect.TypeOf(time.Time{}):
+		v.Set(reflect.ValueOf(time.Date(2025, 7, 10, 9, 30, 0, 0, time.UTC)))
+		return
+	case reflect.TypeOf(json.Number("")):
+		v.SetString("12.5")
+		return
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer:
+		v.Set(reflect.New(v.Type().Elem()))
+		fill(v.Elem(), depth+1)
+	case reflect.Struct:
+		for i := range v.NumField() {
+			if v.Type().Field(i).IsExported() {
+				fill(v.Field(i), depth+1)
+			}
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			v.SetBytes([]byte("abc"))
+			return
+		}
+		v.Set(reflect.MakeSlice(v.Type(), 1, 1))
+		fill(v.Index(0), depth+1)
+	case reflect.Map:
+		v.Set(reflect.MakeMap(v.Type()))
+		key, elem := sample(v.Type().Key()), sample(v.Type().Elem())
+		v.SetMapIndex(key, elem)
+	case reflect.String:
+		v.SetString("abc")
+	case reflect.Bool:
+		v.SetBool(true)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		v.SetInt(1234)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		v.SetUint(7)
+	case reflect.Float32, reflect.Float64:
+		v.SetFloat(1.5)
+	}
+}
+
+// downloadTypes são os tipos enviados nas rotas que não respondem JSON.
+var downloadTypes = map[string]string{
+	"*/*":        "application/pdf",
+	"image/jpeg": "image/jpeg",
+	"text/plain": "text/plain; charset=utf-8",
+}
+
+// contractHandler responde o que a rota documenta: o tipo de Response
+// serializado pelo encoding/json, como fazem os handlers.
+func contractHandler(route router.Route, value any) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		switch {
+		case route.Response != nil:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(value)
+		case route.ContentType != "":
+			contentType, ok := downloadTypes[route.ContentType]
+			if !ok {
+				contentType = route.ContentType
+			}
+			w.Header().Set("Content-Type", contentType)
+			w.WriteHeader(status)
+			w.Write([]byte("conteúdo"))
+		default:
+			w.WriteHeader(status)
+		}
+	})
+}
+
+func passThrough(h http.Handler) http.Handler { return h }
+
+// TestRoutesFollowContract passa cada rota registrada pelo validador do
+// contrato: corpos de requisição e de resposta com os tipos declarados na
+// tabela (preenchidos e vazios) têm de seguir o documento gerado.
+func TestRoutesFollowContract(t *testing.T) {
+	table := routes(apiHandlers{})
+	doc := apiDocument(table)
+
+	for _, route := range table {
+		variants := map[string]func(reflect.Type) reflect.Value{
+			"preenchido": sample,
+			"vazio":      func(t reflect.Type) reflect.Value { return reflect.New(t).Elem() },
+		}
+		for variant, build := range variants {
+			t.Run(route.Method+" "+route.Pattern+" "+variant, func(t *testing.T) {
+				var response any
+				if route.Response != nil {
+					response = route.Response
+					if variant == "preenchido" {
+						response = build(reflect.TypeOf(route.Response)).Interface()
+					}
+				}
+
+				stub := route
+				stub.Handler = contractHandler(route, response)
+				api := router.New(passThrough, func(string) func(http.Handler) http.Handler { return passThrough })
+				api.Handle(stub)
+
+				var reports []string
+				handler := openapi.Validator(doc, func(err error) { reports = append(reports, err.Error()) })(api)
+
+				var body []byte
+				if route.Request != nil && route.RequestContentType == "" {
+					body, _ = json.Marshal(build(reflect.TypeOf(route.Request)).Interface())
+				}
+				req := httptest.NewRequest(route.Method, samplePath(route.Pattern, "1"), bytes.NewReader(body))
+				rec := httptest.NewRecorder()
+				handler.ServeHTTP(rec, req)
+
+				if rec.Code >= 400 {
+					t.Fatalf("status %d: %s", rec.Code, rec.Body.String())
+				}
+				for _, report := range reports {
+					t.Error(report)
+				}
+			})
+		}
+	}
+}
+
+// TestRoutesRejectInvalidIDs passa pelos handlers reais de cada rota com
+// parâmetros {xxxId} um ID inválido: o router responde 400 antes de chegar ao
+// handler, e a resposta tem de estar no formato de problem documentado.
+func TestRoutesRejectInvalidIDs(t *testing.T) {
+	table := routes(apiHandlers{})
+	doc := apiDocument(table)
+	api := router.New(passThrough, func(string) func(http.Handler) http.Handler { return passThrough })
+	api.Handle(table...)
+
+	tested := 0
+	for _, route := range table {
+		if !strings.Contains(route.Pattern, "Id}") {
+			continue
+		}
+		tested++
+		t.Run(route.Method+" "+route.Pattern, func(t *testing.T) {
+			var reports []string
+			handler := openapi.Validator(doc, func(err error) { reports = append(reports, err.Error()) })(api)
+
+			var body []byte
+			if route.Request != nil && route.RequestContentType == "" {
+				body, _ = json.Marshal(sample(reflect.TypeOf(route.Request)).Interface())
+			}
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(route.Method, samplePath(route.Pattern, "abc"), bytes.NewReader(body)))
+
+			if rec.Code != http.StatusBadRequest {
+				t.Fatalf("status %d, esperado 400: %s", rec.Code, rec.Body.String())
+			}
+			for _, report := range reports {
+				t.Error(report)
+			}
+		})
+	}
+	if tested == 0 {
+		t.Fatal("nenhuma rota com parâmetro de ID")
+	}
+}
+
+// Caminhos e métodos fora da tabela também respondem no formato de problem.
+func TestUnknownRoutesAnswerProblems(t *testing.T) {
+	table := routes(apiHandlers{})
+	doc := apiDocument(table)
+	api := router.New(passThrough, func(string) func(http.Handler) http.Handler { return passThrough })
+	api.Handle(table...)
+
+	var reports []string
+	handler := openapi.Validator(doc, func(err error) { reports = append(reports, err.Error()) })(api)
+
+	for _, tc := range []struct {
+		method, path string
+		want         int
+	}{
+		{http.MethodGet, "/nao-existe", http.StatusNotFound},
+		{http.MethodPut, "/groups", http.StatusMethodNotAllowed},
+	} {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(tc.method, tc.path, nil))
+		if rec.Code != tc.want {
+			t.Errorf("%s %s: status %d, esperado %d", tc.method, tc.path, rec.Code, tc.want)
+		}
+	}
+	for _, report := range reports {
+		t.Error(report)
+	}
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"project_lab/internal/migrations"
+	"strconv"
+)
+
+// applyMigrations leva o banco à versão mais recente. Roda em toda
+// inicialização; o advisory lock do Migrator serializa instâncias concorrentes.
+func applyMigrations(migrator *migrations.Migrator) {
+	applied, err := migrator.Up(context.Background())
+	for _, m := range applied {
+		fmt.Printf("Migração aplicada: %04d_%s\n", m.Version, m.Name)
+	}
+	if err != nil {
+		log.Fatalf("Erro ao aplicar migrações: %v", err)
+	}
+	fmt.Println("Banco de dados atualizado!")
+}
+
+// runMigrateCommand trata "migrate up", "migrate down [n]" e "migrate status".
+func runMigrateCommand(args []string, migrator *migrations.Migrator) {
+	if len(args) == 0 {
+		log.Fatal("Uso: migrate up | down [n] | status")
+	}
+
+	switch args[0] {
+	case "up":
+		applyMigrations(migrator)
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n <= 0 {
+				log.Fatal("Uso: migrate down [n], com n > 0")
+			}
+			steps = n
+		}
+		reverted, err := migrator.Down(context.Background(), steps)
+		for _, m := range reverted {
+			fmt.Printf("Migração desfeita: %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			log.Fatalf("Erro ao desfazer migrações: %v", err)
+		}
+		if len(reverted) == 0 {
+			fmt.Println("Nenhuma migração para desfazer.")
+		}
+	case "status":
+		statuses, err := migrator.Status(context.Background())
+		if err != nil {
+			log.Fatalf("Erro ao consultar migrações: %v", err)
+		}
+		for _, s := range statuses {
+			state := "pendente"
+			if s.AppliedAt != nil {
+				state = "aplicada em " + s.AppliedAt.Local().Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%04d_%s\t%s\n", s.Version, s.Name, state)
+		}
+	default:
+		log.Fatalf("Subcomando de migrate desconhecido: %s", args[0])
+	}
+}
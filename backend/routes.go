@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"log"
+	"net/http"
+	"project_lab/internal/handlers"
+	"project_lab/internal/models"
+	"project_lab/internal/openapi"
+	"project_lab/internal/problem"
+	"project_lab/internal/router"
+	"reflect"
+	"strings"
+)
+
+// Tags da documentação.
+const (
+	tagAuth      = "Autenticação"
+	tagProfile   = "Perfil"
+	tagGroups    = "Grupos de Viagem"
+	tagDest      = "Destinos"
+	tagVotings   = "Votações"
+	tagExpenses  = "Despesas"
+	tagItinerary = "Roteiro"
+	tagCalendar  = "Calendário"
+)
+
+// apiHandlers reúne os handlers referenciados pela tabela de rotas.
+type apiHandlers struct {
+	auth        *handlers.AuthHandler
+	profile     *handlers.ProfileHandler
+	groups      *handlers.TravelGroupHandler
+	invites     *handlers.InviteHandler
+	settlements *handlers.SettlementHandler
+	budgets     *handlers.BudgetHandler
+	attachments *handlers.AttachmentHandler
+	itinerary   *handlers.ItineraryHandler
+	calendar    *handlers.CalendarHandler
+	votes       *handlers.VoteHandler
+}
+
+// routes é a tabela com todos os endpoints da API. Os parâmetros {xxxId} são
+// convertidos para int pelo router antes de chegar ao handler.
+func routes(h apiHandlers) []router.Route {
+	return []router.Route{
+		// Autenticação
+		{Method: http.MethodPost, Pattern: "/auth/register", Handler: http.HandlerFunc(h.auth.RegisterUserHandler),
+			Tag: tagAuth, Summary: "Cadastra um novo usuário", Request: models.UserRegisterRequest{}, Status: http.StatusCreated, ContentType: "text/plain"},
+		{Method: http.MethodPost, Pattern: "/auth/login", Handler: http.HandlerFunc(h.auth.LoginUserHandler), RateLimit: "login",
+			Tag: tagAuth, Summary: "Realiza login de um usuário", Request: models.UserLogin{}, Response: models.AuthTokens{}},
+		{Method: http.MethodPost, Pattern: "/auth/refresh", Handler: http.HandlerFunc(h.auth.RefreshTokenHandler), RateLimit: "refresh",
+			Tag: tagAuth, Summary: "Troca o refresh token por um novo par de tokens", Request: models.RefreshTokenRequest{}, Response: models.AuthTokens{}},
+		{Method: http.MethodPost, Pattern: "/auth/logout", Handler: http.HandlerFunc(h.auth.LogoutHandler),
+			Tag: tagAuth, Summary: "Encerra a sessão do refresh token", Request: models.RefreshTokenRequest{}, Status: http.StatusNoContent},
+		{Method: http.MethodPost, Pattern: "/auth/verify", Handler: http.HandlerFunc(h.auth.VerifyEmailHandler),
+			Tag: tagAuth, Summary: "Confirma o e-mail com o token recebido no link", Request: models.EmailTokenRequest{}, Status: http.StatusNoContent},
+		{Method: http.MethodPost, Pattern: "/auth/verify/resend", Handler: http.HandlerFunc(h.auth.ResendVerificationHandler), Auth: true, RateLimit: "verify-resend",
+			Tag: tagAuth, Summary: "Reenvia o link de verificação ao usuário logado", Status: http.StatusAccepted},
+		{Method: http.MethodPost, Pattern: "/auth/forgot-password", Handler: http.HandlerFunc(h.auth.ForgotPasswordHandler), RateLimit: "forgot-password",
+			Tag: tagAuth, Summary: "Envia o link de redefinição de senha", Request: models.ForgotPasswordRequest{}, Status: http.StatusAccepted},
+		{Method: http.MethodPost, Pattern: "/auth/reset-password", Handler: http.HandlerFunc(h.auth.ResetPasswordHandler),
+			Tag: tagAuth, Summary: "Define uma nova senha com o token recebido no link", Request: models.ResetPasswordRequest{}, Status: http.StatusNoContent},
+
+		// Perfil
+		{Method: http.MethodGet, Pattern: "/profile", Handler: http.HandlerFunc(h.profile.GetProfileHandler), Auth: true,
+			Tag: tagProfile, Summary: "Obtém os dados do perfil do usuário logado", Response: models.UserProfileResponse{}},
+		{Method: http.MethodPatch, Pattern: "/profile", Handler: http.HandlerFunc(h.profile.UpdateProfileHandler), Auth: true,
+			Tag: tagProfile, Summary: "Atualiza o nome e/ou o idioma preferido do usuário logado", Request: models.UserProfileUpdateRequest{}, Response: models.UserProfileResponse{}},
+		{Method: http.MethodGet, Pattern: "/profile/failed-logins", Handler: http.HandlerFunc(h.profile.ListFailedLoginsHandler), Auth: true,
+			Tag: tagProfile, Summary: "Lista as tentativas de login malsucedidas na conta do usuário", Response: []models.LoginAuditEntry{}},
+
+		// Grupos
+		{Method: http.MethodGet, Pattern: "/groups", Handler: http.HandlerFunc(h.groups.ListGroups), Auth: true,
+			Tag: tagGroups, Summary: "Lista grupos do usuário logado", Response: []models.TravelGroupListItem{}},
+		{Method: http.MethodPost, Pattern: "/groups", Handler: http.HandlerFunc(h.groups.CreateGroupHandler), Auth: true,
+			Tag: tagGroups, Summary: "Cria um novo grupo de viagem", Request: models.TravelGroupCreateRequest{}, Response: models.TravelGroup{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}", Handler: router.ID("groupId", h.groups.GetGroupDetailsWithID), Auth: true,
+			Tag: tagGroups, Summary: "Detalhes de um grupo", Response: models.TravelGroupDetails{}},
+		{Method: http.MethodPatch, Pattern: "/groups/{groupId}", Handler: router.ID("groupId", h.groups.UpdateGroupHandler), Auth: true,
+			Tag: tagGroups, Summary: "Altera o grupo (organizador e co-organizadores)", Request: models.TravelGroupUpdateRequest{}, Response: models.TravelGroupDetails{}},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}", Handler: router.ID("groupId", h.groups.DeleteGroupHandler), Auth: true,
+			Tag: tagGroups, Summary: "Apaga o grupo e todos os seus dados (apenas o organizador)", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/members", Handler: router.ID("groupId", h.groups.ListGroupMembersHandler), Auth: true,
+			Tag: tagGroups, Summary: "Lista todos os membros de um grupo", Response: []models.GroupMemberDTO{}},
+		{Method: http.MethodPatch, Pattern: "/groups/{groupId}/members/{userId}", Handler: router.IDs("groupId", "userId", h.groups.UpdateMemberRoleHandler), Auth: true,
+			Tag: tagGroups, Summary: "Altera o papel de um membro (apenas o organizador)", Request: models.MemberRoleUpdateRequest{}, Response: []models.GroupMemberDTO{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/transfer-ownership", Handler: router.ID("groupId", h.groups.TransferOwnershipHandler), Auth: true,
+			Tag: tagGroups, Summary: "Transfere o grupo para outro membro (apenas o organizador)", Request: models.OwnershipTransferRequest{}, Response: []models.GroupMemberDTO{}},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/members/{userId}", Handler: router.IDs("groupId", "userId", h.groups.RemoveMemberHandler), Auth: true,
+			Tag: tagGroups, Summary: "Remove um membro sem saldo pendente (organizador e co-organizadores)", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/leave", Handler: router.ID("groupId", h.groups.LeaveGroupHandler), Auth: true,
+			Tag: tagGroups, Summary: "Sai do grupo (exige saldo zerado; o organizador transfere o grupo antes)", Status: http.StatusNoContent},
+
+		// Convites
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/invites", Handler: router.ID("groupId", h.invites.ListGroupInvitesHandler), Auth: true,
+			Tag: tagGroups, Summary: "Lista os convites do grupo (organizador e co-organizadores)", Response: []models.GroupInvite{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/invites", Handler: router.ID("groupId", h.invites.CreateInviteHandler), Auth: true,
+			Tag: tagGroups, Summary: "Cria um convite por e-mail (uso único) ou um código compartilhável", Request: models.InviteCreateRequest{}, Response: models.GroupInvite{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/invites/{inviteId}", Handler: router.IDs("groupId", "inviteId", h.invites.RevokeInviteHandler), Auth: true,
+			Tag: tagGroups, Summary: "Revoga um convite pendente", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Pattern: "/invites", Handler: http.HandlerFunc(h.invites.ListMyInvitesHandler), Auth: true,
+			Tag: tagGroups, Summary: "Lista os convites por e-mail pendentes do usuário logado", Response: []models.PendingInviteDTO{}},
+		{Method: http.MethodPost, Pattern: "/invites/{code}/accept", Handler: router.Param("code", h.invites.AcceptInviteHandler), Auth: true,
+			Tag: tagGroups, Summary: "Aceita um convite e entra no grupo", Response: map[string]int{}},
+		{Method: http.MethodPost, Pattern: "/invites/{code}/decline", Handler: router.Param("code", h.invites.DeclineInviteHandler), Auth: true,
+			Tag: tagGroups, Summary: "Recusa um convite por e-mail", Status: http.StatusNoContent},
+
+		// Destinos
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/destinations", Handler: router.ID("groupId", h.groups.ListGroupDestinationsHandler), Auth: true,
+			Tag: tagDest, Summary: "Lista todos os destinos sugeridos para um grupo", Response: []models.DestinationDTO{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/destinations", Handler: router.ID("groupId", h.groups.CreateDestinationHandler), Auth: true,
+			Tag: tagDest, Summary: "Adiciona um destino ao grupo", Request: models.DestinationCreateRequest{}, Response: models.Destination{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Pattern: "/groups/{groupId}/destinations/{destinationId}", Handler: router.IDs("groupId", "destinationId", h.groups.UpdateDestinationHandler), Auth: true,
+			Tag: tagDest, Summary: "Altera um destino (quem sugeriu ou o organizador)", Request: models.DestinationCreateRequest{}, Response: models.DestinationDTO{}},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/destinations/{destinationId}", Handler: router.IDs("groupId", "destinationId", h.groups.DeleteDestinationHandler), Auth: true,
+			Tag: tagDest, Summary: "Apaga um destino (quem sugeriu ou o organizador)", Status: http.StatusNoContent},
+
+		// Votações
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/votings", Handler: router.ID("groupId", h.groups.ListGroupVotingsHandler), Auth: true,
+			Tag: tagVotings, Summary: "Lista todas as votações de um grupo", Response: []models.VotingDTO{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/votings", Handler: router.ID("groupId", h.groups.CreateVotingHandler), Auth: true,
+			Tag: tagVotings, Summary: "Cria uma nova votação para o grupo", Request: models.VotingCreateRequest{}, Response: map[string]int{}, Status: http.StatusCreated},
+		{Method: http.MethodPatch, Pattern: "/votings/{votingId}", Handler: router.ID("votingId", h.votes.UpdateVotingHandler), Auth: true,
+			Tag: tagVotings, Summary: "Altera a votação (autor ou organizador)", Request: models.VotingUpdateRequest{}, Response: models.VotingResults{}},
+		{Method: http.MethodDelete, Pattern: "/votings/{votingId}", Handler: router.ID("votingId", h.votes.DeleteVotingHandler), Auth: true,
+			Tag: tagVotings, Summary: "Apaga a votação e seus votos (autor ou organizador)", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Pattern: "/votings/{votingId}/vote", Handler: router.ID("votingId", h.votes.VoteHandler), Auth: true,
+			Tag: tagVotings, Summary: "Registra o voto do usuário logado em uma votação", Request: models.VoteRequest{}, Response: map[string]string{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Pattern: "/votings/{votingId}/vote", Handler: router.ID("votingId", h.votes.ChangeVoteHandler), Auth: true,
+			Tag: tagVotings, Summary: "Altera o voto do usuário logado", Request: models.VoteRequest{}, Response: map[string]string{}},
+		{Method: http.MethodDelete, Pattern: "/votings/{votingId}/vote", Handler: router.ID("votingId", h.votes.RetractVoteHandler), Auth: true,
+			Tag: tagVotings, Summary: "Retira o voto do usuário logado", Status: http.StatusNoContent},
+		{Method: http.MethodPost, Pattern: "/votings/{votingId}/close", Handler: router.ID("votingId", h.votes.CloseVotingHandler), Auth: true,
+			Tag: tagVotings, Summary: "Encerra a votação (autor da votação ou organizador do grupo)", Response: models.VotingResults{}},
+		{Method: http.MethodGet, Pattern: "/votings/{votingId}/results", Handler: router.ID("votingId", h.votes.GetVotingResultsHandler), Auth: true,
+			Tag: tagVotings, Summary: "Apuração da votação com contagem e percentual por opção", Response: models.VotingResults{}},
+
+		// Despesas
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/expenses", Handler: router.ID("groupId", h.groups.ListGroupExpensesHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Lista todas as despesas registradas no grupo", Response: []models.ExpenseDTO{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/expenses", Handler: router.ID("groupId", h.groups.CreateExpenseHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Cria uma nova despesa e registra o rateio", Request: models.ExpenseCreateRequest{}, Response: models.Expense{}, Status: http.StatusCreated},
+		{Method: http.MethodPut, Pattern: "/groups/{groupId}/expenses/{expenseId}", Handler: router.IDs("groupId", "expenseId", h.groups.UpdateExpenseHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Altera uma despesa (quem lançou ou o organizador)", Request: models.ExpenseCreateRequest{}, Response: models.Expense{}},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/expenses/{expenseId}", Handler: router.IDs("groupId", "expenseId", h.groups.DeleteExpenseHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Apaga uma despesa (quem lançou ou o organizador)", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/expenses/{expenseId}/attachments", Handler: router.IDs("groupId", "expenseId", h.attachments.ListAttachmentsHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Lista os comprovantes de uma despesa", Response: []models.ExpenseAttachment{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/expenses/{expenseId}/attachments", Handler: router.IDs("groupId", "expenseId", h.attachments.UploadAttachmentHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Anexa um comprovante (JPEG, PNG, GIF, WebP ou PDF) no campo file", Request: models.AttachmentUploadForm{}, RequestContentType: "multipart/form-data", Response: models.ExpenseAttachment{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/expenses/{expenseId}/attachments/{attachmentId}", Handler: router.IDs3("groupId", "expenseId", "attachmentId", h.attachments.DownloadAttachmentHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Baixa o arquivo do comprovante (apenas membros do grupo)", ContentType: "*/*"},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/expenses/{expenseId}/attachments/{attachmentId}/thumbnail", Handler: router.IDs3("groupId", "expenseId", "attachmentId", h.attachments.DownloadThumbnailHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Baixa a miniatura de um comprovante de imagem", ContentType: "image/jpeg"},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/expenses/{expenseId}/attachments/{attachmentId}", Handler: router.IDs3("groupId", "expenseId", "attachmentId", h.attachments.DeleteAttachmentHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Apaga um comprovante (quem enviou ou o organizador)", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/balances", Handler: router.ID("groupId", h.settlements.GetGroupBalancesHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Saldo de cada membro (pago - devido) considerando despesas e pagamentos de acerto", Response: []models.MemberBalance{}},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/settlements", Handler: router.ID("groupId", h.settlements.GetGroupSettlementsHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Transferências sugeridas para quitar as dívidas do grupo", Response: []models.Settlement{}},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/payments", Handler: router.ID("groupId", h.settlements.ListPaymentsHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Pagamentos de acerto já feitos entre os membros", Response: []models.SettlementPayment{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/payments", Handler: router.ID("groupId", h.settlements.CreatePaymentHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Registra um pagamento de acerto (quem pagou, quem recebeu ou o organizador)", Request: models.SettlementPaymentRequest{}, Response: models.SettlementPayment{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/payments/{paymentId}", Handler: router.IDs("groupId", "paymentId", h.settlements.DeletePaymentHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Apaga um pagamento de acerto (quem registrou ou o organizador)", Status: http.StatusNoContent},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/budget", Handler: router.ID("groupId", h.budgets.GetBudgetHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Orçamento do grupo: planejado x realizado por categoria e por membro", Response: models.BudgetReport{}},
+		{Method: http.MethodPut, Pattern: "/groups/{groupId}/budget", Handler: router.ID("groupId", h.budgets.UpdateBudgetHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Define o orçamento do grupo (organizador e co-organizadores)", Request: models.BudgetUpdateRequest{}, Response: models.BudgetReport{}},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/budget", Handler: router.ID("groupId", h.budgets.DeleteBudgetHandler), Auth: true,
+			Tag: tagExpenses, Summary: "Remove o orçamento do grupo (organizador e co-organizadores)", Status: http.StatusNoContent},
+
+		// Roteiro
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/itinerary", Handler: router.ID("groupId", h.itinerary.ListItineraryHandler), Auth: true,
+			Tag: tagItinerary, Summary: "Lista os itens do roteiro do grupo em ordem cronológica", Response: []models.ItineraryItem{}},
+		{Method: http.MethodPost, Pattern: "/groups/{groupId}/itinerary", Handler: router.ID("groupId", h.itinerary.CreateItineraryItemHandler), Auth: true,
+			Tag: tagItinerary, Summary: "Adiciona um item ao roteiro", Request: models.ItineraryItemRequest{}, Response: models.ItineraryItem{}, Status: http.StatusCreated},
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/itinerary/days", Handler: router.ID("groupId", h.itinerary.ListItineraryDaysHandler), Auth: true,
+			Tag: tagItinerary, Summary: "Roteiro agrupado por dia da viagem (inclui dias sem itens)", Response: []models.ItineraryDay{}},
+		{Method: http.MethodPut, Pattern: "/groups/{groupId}/itinerary/{itemId}", Handler: router.IDs("groupId", "itemId", h.itinerary.UpdateItineraryItemHandler), Auth: true,
+			Tag: tagItinerary, Summary: "Altera um item do roteiro (autor do item ou organizador)", Request: models.ItineraryItemRequest{}, Response: models.ItineraryItem{}},
+		{Method: http.MethodDelete, Pattern: "/groups/{groupId}/itinerary/{itemId}", Handler: router.IDs("groupId", "itemId", h.itinerary.DeleteItineraryItemHandler), Auth: true,
+			Tag: tagItinerary, Summary: "Remove um item do roteiro (autor do item ou organizador)", Status: http.StatusNoContent},
+
+		// Calendário
+		{Method: http.MethodGet, Pattern: "/groups/{groupId}/calendar.ics", Handler: router.ID("groupId", h.calendar.GetGroupCalendarHandler), Auth: true,
+			Tag: tagCalendar, Summary: "Calendário iCalendar (RFC 5545) do grupo", ContentType: "text/calendar"},
+		{Method: http.MethodPost, Pattern: "/profile/calendar-feed", Handler: http.HandlerFunc(h.calendar.CreateCalendarFeedHandler), Auth: true,
+			Tag: tagCalendar, Summary: "Gera (ou troca) o link pessoal do feed de calendário", Response: map[string]string{}, Status: http.StatusCreated},
+		{Method: http.MethodDelete, Pattern: "/profile/calendar-feed", Handler: http.HandlerFunc(h.calendar.RevokeCalendarFeedHandler), Auth: true,
+			Tag: tagCalendar, Summary: "Revoga o link do feed de calendário", Status: http.StatusNoContent},
+		// Sem Auth: o token na URL é a credencial, pois clientes de calendário
+		// não enviam Bearer.
+		{Method: http.MethodGet, Pattern: "/calendar/{feed}", Handler: router.Param("feed", calendarFeed(h.calendar)),
+			Tag: tagCalendar, Summary: "Feed de calendário com todas as viagens do usuário", ContentType: "text/calendar"},
+	}
+}
+
+// calendarFeed atende /calendar/{token}.ics. O http.ServeMux não aceita
+// parâmetro em parte de um segmento, então a extensão é conferida aqui.
+func calendarFeed(h *handlers.CalendarHandler) func(http.ResponseWriter, *http.Request, string) {
+	return func(w http.ResponseWriter, r *http.Request, feed string) {
+		token, ok := strings.CutSuffix(feed, ".ics")
+		if !ok {
+			problem.NotFound(w, r)
+			return
+		}
+		h.GetCalendarFeedHandler(w, r, token)
+	}
+}
+
+// apiDocument gera o documento OpenAPI da API a partir das rotas registradas.
+func apiDocument(rs []router.Route) *openapi.Document {
+	return openapi.Generate(openapi.Options{
+		Info: openapi.Info{
+			Title:   "API - Aplicação de Planejamento de Viagens em Grupo",
+			Version: "1.0.0",
+			Description: "Documentação da API para organização colaborativa de viagens em grupo, " +
+				"gerada a partir da tabela de rotas (routes.go) e dos tipos de internal/models.\n\n" +
+				"Todas as respostas de erro seguem a RFC 7807 (application/problem+json), com um campo " +
+				"\"code\" estável; veja o schema Problem. Caminhos inexistentes respondem 404; métodos não " +
+				"suportados em um caminho existente respondem 405, com os métodos aceitos no header Allow.\n\n" +
+				"As mensagens saem em pt-BR ou en, conforme o header Accept-Language; o padrão é pt-BR. Nas " +
+				"rotas autenticadas, o idioma preferido salvo no perfil tem prioridade. O idioma usado volta " +
+				"no header Content-Language.",
+		},
+		Servers: []openapi.Server{
+			{URL: "http://localhost:8080", Description: "Servidor local"},
+		},
+		Tags: []openapi.Tag{
+			{Name: tagAuth, Description: "Cadastro, login, sessão e recuperação de senha"},
+			{Name: tagProfile, Description: "Gerenciamento e visualização do perfil do usuário"},
+			{Name: tagGroups, Description: "CRUD de grupos, membros e convites"},
+			{Name: tagDest, Description: "Adição e listagem de destinos"},
+			{Name: tagVotings, Description: "Criação e participação em votações"},
+			{Name: tagExpenses, Description: "Registro e rateio de despesas"},
+			{Name: tagItinerary, Description: "Programação dia a dia da viagem"},
+			{Name: tagCalendar, Description: "Exportação iCalendar e feed assinável"},
+		},
+		Types: map[reflect.Type]*openapi.Schema{
+			reflect.TypeOf(models.Money(0)): {Type: "number", Description: "Valor decimal com duas casas", Example: 45.5},
+			reflect.TypeOf(models.LocalDateTime{}): {Type: "string", Description: "Data e hora local, sem fuso (AAAA-MM-DDTHH:MM)",
+				Example: "2025-07-10T09:30"},
+		},
+	}, rs)
+}
+
+// writeOpenAPI escreve o documento da API em JSON (subcomando "openapi"). Os
+// handlers não são chamados, então a tabela é montada sem dependências.
+func writeOpenAPI(out io.Writer) {
+	api := router.New(func(h http.Handler) http.Handler { return h }, func(string) func(http.Handler) http.Handler {
+		return func(h http.Handler) http.Handler { return h }
+	})
+	api.Handle(routes(apiHandlers{})...)
+
+	encoder := json.NewEncoder(out)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(apiDocument(api.Routes())); err != nil {
+		log.Fatalf("Erro ao gerar o documento OpenAPI: %v", err)
+	}
+}
@@ -57,7 +57,8 @@ export interface GroupMemberDTO {
     userId: number;
     name: string;
     email: string;
-    role: 'Organizador' | 'Participante';
+    role: 'organizer' | 'co_organizer' | 'participant' | 'viewer';
+    leftAt?: string; // só para ex-membros (GET /groups/{id}/members?former=true)
 }
 
 /**
@@ -146,6 +147,7 @@ export interface ExpenseDTO {
     payerName: string;
     participantsIds: number[];
     participantsCount: number;
+    category: ExpenseCategory;
     createdAt: string;
 }
 
@@ -158,8 +160,27 @@ export interface ExpenseCreateRequest {
     amount: number;
     payerId: number;
     participantIds: number[];
+    category?: ExpenseCategory; // padrão 'other'
 }
 
+/**
+ * Comprovante anexado a uma despesa. url e thumbnailUrl são rotas da API
+ * (exigem o token), relativas à URL base.
+ */
+export interface ExpenseAttachment {
+    id: number;
+    expenseId: number;
+    fileName: string;
+    contentType: string;
+    size: number;
+    uploadedBy: number | null;
+    createdAt: string;
+    url: string;
+    thumbnailUrl: string | null;
+}
+
+export type ExpenseCategory = 'lodging' | 'transport' | 'food' | 'activities' | 'shopping' | 'other';
+
 /**
  * DTO para a resposta da criação de uma despesa.
  * Corresponde ao ExpenseResponse no YAML (que usa allOf com ExpenseDTO).